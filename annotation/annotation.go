@@ -1,58 +1,200 @@
 package annotation
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 type Annotation struct {
 	Name       string
 	Attributes map[string]string
 }
 
+// copy returns the annotation with attributes of its own, so modifying them leaves the original untouched
+func (a Annotation) copy() Annotation {
+	if a.Attributes == nil {
+		return a
+	}
+	attributes := make(map[string]string, len(a.Attributes))
+	for name, value := range a.Attributes {
+		attributes[name] = value
+	}
+	return Annotation{Name: a.Name, Attributes: attributes}
+}
+
 type ValidationFunc func(annot Annotation) bool
 
-type annotationDescriptor struct {
-	name       string
-	paramNames []string
-	validator  ValidationFunc
+// ExplainingValidationFunc also returns the reason why an annotation is invalid, to be reported to the developer
+type ExplainingValidationFunc func(annot Annotation) (bool, error)
+
+// explainingValidator adapts a validator that only tells if an annotation is valid
+func explainingValidator(name string, validator ValidationFunc) ExplainingValidationFunc {
+	return func(annot Annotation) (bool, error) {
+		if validator(annot) {
+			return true, nil
+		}
+		return false, fmt.Errorf("Annotation @%s has invalid or missing attributes", name)
+	}
 }
 
-var annotationRegistry []annotationDescriptor = []annotationDescriptor{}
+// WarnValidator reports a soft rule that a valid annotation does not follow: an empty warning means there is nothing
+// to report. A warning informs the developer, but never makes the annotation invalid.
+type WarnValidator func(annot Annotation) (warning string)
 
-func ClearRegisteredAnnotations() {
-	annotationRegistry = []annotationDescriptor{}
+// ParamDef describes a parameter of an annotation: a required parameter must have a non-empty value, an optional
+// parameter gets its default-value when it is omitted
+type ParamDef struct {
+	Name     string
+	Required bool
+	Default  string
 }
 
-func RegisterAnnotation(name string, paramNames []string, validator ValidationFunc) {
-	annotationRegistry = append(annotationRegistry, annotationDescriptor{name: name, paramNames: paramNames, validator: validator})
+// paramValidator enforces the required parameters before the validator of the annotation is called: the validator can
+// be nil when there is nothing else to validate
+func paramValidator(name string, params []ParamDef, validator ExplainingValidationFunc) ExplainingValidationFunc {
+	return func(annot Annotation) (bool, error) {
+		for _, p := range params {
+			if p.Required && annot.Attributes[strings.ToLower(p.Name)] == "" {
+				return false, fmt.Errorf("@%s requires a non-empty '%s' attribute", name, strings.ToLower(p.Name))
+			}
+		}
+		if validator == nil {
+			return true, nil
+		}
+		return validator(annot)
+	}
 }
 
-func ResolveAnnotations(annotationDocline []string) (Annotation, bool) {
-	for _, line := range annotationDocline {
-		a, ok := ResolveAnnotation(strings.TrimSpace(line))
-		if ok {
-			return a, ok
+// withDefaults returns a copy of the annotation in which the omitted optional parameters have their default-value
+func withDefaults(annot Annotation, params []ParamDef) Annotation {
+	if len(params) == 0 {
+		return annot
+	}
+	attributes := make(map[string]string, len(annot.Attributes))
+	for name, value := range annot.Attributes {
+		attributes[name] = value
+	}
+	for _, p := range params {
+		if _, found := attributes[strings.ToLower(p.Name)]; !found && p.Default != "" {
+			attributes[strings.ToLower(p.Name)] = p.Default
 		}
 	}
-	return Annotation{}, false
+	return Annotation{Name: annot.Name, Attributes: attributes}
 }
 
-func ResolveAnnotation(annotationDocline string) (Annotation, bool) {
-	for _, descriptor := range annotationRegistry {
-		annotation, err := parseAnnotation(annotationDocline)
-		annotation, err = parseAnnotation(annotationDocline)
-		if err != nil {
-			continue
-		}
+// ValidationError explains why a registered annotation is invalid
+type ValidationError struct {
+	AnnotationName string
+	Reason         error
+}
 
-		if annotation.Name != descriptor.name {
-			continue
-		}
+func (e ValidationError) Error() string {
+	return e.Reason.Error()
+}
 
-		ok := descriptor.validator(annotation)
-		if !ok {
-			continue
-		}
+// AnnotationWarning is a soft rule that a valid annotation does not follow: the annotation is still used by the
+// generators
+type AnnotationWarning struct {
+	AnnotationName string
+	Warning        string
+}
 
-		return annotation, true
-	}
-	return Annotation{}, false
+func (w AnnotationWarning) String() string {
+	return w.Warning
+}
+
+// AnnotationInfo describes a registered annotation, for tools like editors
+type AnnotationInfo struct {
+	Name       string
+	ParamNames []string
+}
+
+// The functions below operate on the DefaultRegistry
+
+func ClearRegisteredAnnotations() {
+	DefaultRegistry.Clear()
+}
+
+// RegisterAnnotation registers an annotation with its validator: the optional warn-validators report soft rules that
+// valid annotations do not follow
+func RegisterAnnotation(name string, paramNames []string, validator ValidationFunc, warnValidators ...WarnValidator) {
+	DefaultRegistry.Register(name, paramNames, validator, warnValidators...)
+}
+
+// RegisterExplainingAnnotation registers an annotation with a validator that explains why an annotation is invalid
+func RegisterExplainingAnnotation(name string, paramNames []string, validator ExplainingValidationFunc, warnValidators ...WarnValidator) {
+	DefaultRegistry.RegisterExplaining(name, paramNames, validator, warnValidators...)
+}
+
+// RegisterAnnotationWithParams registers an annotation of which the required parameters are enforced and the optional
+// parameters get their default-value
+func RegisterAnnotationWithParams(name string, params []ParamDef, validator ExplainingValidationFunc, warnValidators ...WarnValidator) {
+	DefaultRegistry.RegisterWithParams(name, params, validator, warnValidators...)
+}
+
+// MustRegister registers an annotation like RegisterAnnotation, but panics when an annotation with the same name
+// has already been registered. It is intended to be called from init()-functions.
+func MustRegister(name string, paramNames []string, validator ValidationFunc) {
+	DefaultRegistry.MustRegister(name, paramNames, validator)
+}
+
+// IsRegistered tells if an annotation with the given name has been registered
+func IsRegistered(name string) bool {
+	return DefaultRegistry.IsRegistered(name)
+}
+
+// UnregisteredNames returns the names of the well-formed annotations in the doc-lines that have not been registered,
+// which are most likely typos
+func UnregisteredNames(annotationDocline []string) []string {
+	return DefaultRegistry.UnregisteredNames(annotationDocline)
+}
+
+// ValidationErrors explains why the registered annotations in the doc-lines are invalid: invalid annotations are
+// ignored by the generators
+func ValidationErrors(annotationDocline []string) []ValidationError {
+	return DefaultRegistry.Validate(annotationDocline)
+}
+
+// LintAll explains why the registered annotations in the doc-lines are invalid, like ValidationErrors, and also returns
+// the warnings about the valid annotations: warnings do not prevent generation
+func LintAll(annotationDocline []string) ([]ValidationError, []AnnotationWarning) {
+	return DefaultRegistry.Lint(annotationDocline)
+}
+
+// ListAnnotations returns all registered annotations, sorted by name
+func ListAnnotations() []AnnotationInfo {
+	return DefaultRegistry.List()
+}
+
+func ResolveAnnotations(annotationDocline []string) (Annotation, bool) {
+	return DefaultRegistry.ResolveAnnotations(annotationDocline)
+}
+
+// ParseAnnotations returns all valid annotations in the order in which they appear in the doc-lines
+func ParseAnnotations(annotationDocline []string) []Annotation {
+	return DefaultRegistry.ParseAnnotations(annotationDocline)
+}
+
+// GetAll returns all valid annotations with the given name, in the order in which they appear in the doc-lines
+func GetAll(annotationDocline []string, name string) []Annotation {
+	return DefaultRegistry.GetAll(annotationDocline, name)
+}
+
+// ResolveAnnotationByName returns the first valid annotation with the given name
+func ResolveAnnotationByName(annotationDocline []string, name string) (Annotation, bool) {
+	return DefaultRegistry.ResolveAnnotationByName(annotationDocline, name)
+}
+
+// GetAnnotation returns the first valid annotation with the given name
+func GetAnnotation(annotationDocline []string, name string) (Annotation, bool) {
+	return DefaultRegistry.GetAnnotation(annotationDocline, name)
+}
+
+// GetAnnotationAttribute returns the value of an attribute of the first valid annotation with the given name
+func GetAnnotationAttribute(annotationDocline []string, annotName, attrName string) (string, bool) {
+	return DefaultRegistry.GetAnnotationAttribute(annotationDocline, annotName, attrName)
+}
+
+func ResolveAnnotation(annotationDocline string) (Annotation, bool) {
+	return DefaultRegistry.ResolveAnnotation(annotationDocline)
 }
@@ -2,6 +2,8 @@ package annotation
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"text/scanner"
 )
@@ -16,6 +18,9 @@ const (
 	done
 )
 
+// bareAnnotationPattern matches annotations without parentheses, like @Readonly, that act as a flag
+var bareAnnotationPattern = regexp.MustCompile(`^@([A-Za-z_][A-Za-z0-9_]*)$`)
+
 func parseAnnotation(line string) (Annotation, error) {
 	withoutComment := strings.TrimLeft(strings.TrimSpace(line), "/")
 
@@ -24,15 +29,35 @@ func parseAnnotation(line string) (Annotation, error) {
 		Attributes: make(map[string]string),
 	}
 
+	match := bareAnnotationPattern.FindStringSubmatch(strings.TrimSpace(withoutComment))
+	if match != nil {
+		annotation.Name = match[1]
+		return annotation, nil
+	}
+
 	var s scanner.Scanner
 	s.Init(strings.NewReader(withoutComment))
+	// backslashes that do not escape a quote or a backslash are kept, so the scanner should not complain about them
+	s.Error = func(*scanner.Scanner, string) {}
 
 	var tok rune
 	var currentStatus status = initial
 	var attrName string
+	var valueTokens []string
+	depth := 0
 
 	for tok != scanner.EOF && currentStatus < done {
 		tok = s.Scan()
+		if currentStatus == attributeValue && tok != scanner.EOF && !(depth == 0 && (tok == ',' || tok == ')')) {
+			// a value can be an expression, like ${MaxRPS}*2, that spans multiple tokens
+			if tok == '(' {
+				depth++
+			} else if tok == ')' {
+				depth--
+			}
+			valueTokens = append(valueTokens, s.TokenText())
+			continue
+		}
 		switch tok {
 		case '@':
 			currentStatus = annotationName
@@ -40,10 +65,21 @@ func parseAnnotation(line string) (Annotation, error) {
 			currentStatus = attributeName
 		case '=':
 			currentStatus = attributeValue
-		case ',':
-			currentStatus = attributeName
-		case ')':
-			currentStatus = done
+			valueTokens = []string{}
+			depth = 0
+		case ',', ')':
+			if currentStatus == attributeValue && len(valueTokens) > 0 {
+				value, err := attributeValueOf(valueTokens)
+				if err != nil {
+					return annotation, fmt.Errorf("Invalid value for attribute %s of annotation:%s: %s", attrName, line, err)
+				}
+				annotation.Attributes[strings.ToLower(attrName)] = value
+			}
+			if tok == ',' {
+				currentStatus = attributeName
+			} else {
+				currentStatus = done
+			}
 		case scanner.Ident:
 			//log.Printf("key:%s", s.TokenText())
 			switch currentStatus {
@@ -52,12 +88,6 @@ func parseAnnotation(line string) (Annotation, error) {
 			case attributeName:
 				attrName = s.TokenText()
 			}
-		default:
-			//log.Printf("value:%s", s.TokenText())
-			switch currentStatus {
-			case attributeValue:
-				annotation.Attributes[strings.ToLower(attrName)] = strings.Trim(s.TokenText(), "\"")
-			}
 		}
 	}
 
@@ -67,3 +97,36 @@ func parseAnnotation(line string) (Annotation, error) {
 	}
 	return annotation, nil
 }
+
+// attributeValueOf returns a single token as-is, without its quotes, and evaluates multiple tokens as an expression
+// when they are one: other values, like 200ms or pkg.Type, are returned as written
+func attributeValueOf(tokens []string) (string, error) {
+	if len(tokens) == 1 {
+		if len(tokens[0]) >= 2 && strings.HasPrefix(tokens[0], "\"") && strings.HasSuffix(tokens[0], "\"") {
+			return unescapeValue(tokens[0][1 : len(tokens[0])-1]), nil
+		}
+		return strings.Trim(tokens[0], "\""), nil
+	}
+	joined := strings.Join(tokens, "")
+	if !isExpression(joined) {
+		return joined, nil
+	}
+	value, err := evaluateExpression(joined)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(value), nil
+}
+
+// unescapeValue replaces the escaped quotes and backslashes in a quoted value: other backslashes, like in the regular
+// expression of a path-parameter, are kept as-is
+func unescapeValue(quoted string) string {
+	var value strings.Builder
+	for i := 0; i < len(quoted); i++ {
+		if quoted[i] == '\\' && i+1 < len(quoted) && (quoted[i+1] == '"' || quoted[i+1] == '\\') {
+			i++
+		}
+		value.WriteByte(quoted[i])
+	}
+	return value.String()
+}
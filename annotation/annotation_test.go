@@ -1,6 +1,7 @@
 package annotation
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,6 +50,146 @@ func TestCorrectAnnotation(t *testing.T) {
 	assert.Equal(t, "A", annotation.Attributes["a"])
 }
 
+func TestResolveAnnotationByName(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("X", []string{}, validateOk)
+	RegisterAnnotation("Y", []string{}, validateOk)
+
+	annotation, ok := ResolveAnnotationByName([]string{`// @X( a = "A" )`, `// @Y( b = "B" )`}, "Y")
+	assert.True(t, ok)
+	assert.Equal(t, "Y", annotation.Name)
+	assert.Equal(t, "B", annotation.Attributes["b"])
+
+	_, ok = ResolveAnnotationByName([]string{`// @X( a = "A" )`}, "Y")
+	assert.False(t, ok)
+}
+
+func TestParseAnnotationsPreservesOrder(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("X", []string{}, validateOk)
+	RegisterAnnotation("Y", []string{}, validateOk)
+
+	annotations := ParseAnnotations([]string{
+		`// @Y( a = "1" )`,
+		`// just a comment`,
+		`// @X( a = "2" )`,
+		`// @Y( a = "3" )`,
+		`// @Z( a = "4" )`,
+	})
+	assert.Len(t, annotations, 3)
+	assert.Equal(t, "Y", annotations[0].Name)
+	assert.Equal(t, "1", annotations[0].Attributes["a"])
+	assert.Equal(t, "X", annotations[1].Name)
+	assert.Equal(t, "2", annotations[1].Attributes["a"])
+	assert.Equal(t, "Y", annotations[2].Name)
+	assert.Equal(t, "3", annotations[2].Attributes["a"])
+}
+
+func TestGetAll(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("ResponseCode", []string{}, validateOk)
+	RegisterAnnotation("X", []string{}, validateOk)
+
+	annotations := GetAll([]string{
+		`// @ResponseCode( status = "200" )`,
+		`// @X( a = "A" )`,
+		`// @ResponseCode( status = "404" )`,
+	}, "ResponseCode")
+	assert.Len(t, annotations, 2)
+	assert.Equal(t, "200", annotations[0].Attributes["status"])
+	assert.Equal(t, "404", annotations[1].Attributes["status"])
+
+	assert.Empty(t, GetAll([]string{`// @X( a = "A" )`}, "ResponseCode"))
+}
+
+func TestGetAnnotation(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("Flag", []string{}, validateOk)
+	RegisterAnnotation("X", []string{}, validateOk)
+
+	docLines := []string{
+		`// Some documentation`,
+		`// @Flag()`,
+		`// @X( a = "A", b = "B", c = "one, two" )`,
+	}
+
+	_, ok := GetAnnotation(docLines, "Missing")
+	assert.False(t, ok)
+
+	annotation, ok := GetAnnotation(docLines, "Flag")
+	assert.True(t, ok)
+	assert.Empty(t, annotation.Attributes)
+
+	annotation, ok = GetAnnotation(docLines, "X")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"a": "A", "b": "B", "c": "one, two"}, annotation.Attributes)
+}
+
+func TestGetAnnotationAttribute(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("X", []string{}, validateOk)
+
+	docLines := []string{`// @X( aggregate = "Tour", values = "a,b" )`}
+
+	value, ok := GetAnnotationAttribute(docLines, "X", "aggregate")
+	assert.True(t, ok)
+	assert.Equal(t, "Tour", value)
+
+	value, ok = GetAnnotationAttribute(docLines, "X", "Values")
+	assert.True(t, ok)
+	assert.Equal(t, "a,b", value)
+
+	_, ok = GetAnnotationAttribute(docLines, "X", "missing")
+	assert.False(t, ok)
+
+	_, ok = GetAnnotationAttribute(docLines, "Y", "aggregate")
+	assert.False(t, ok)
+}
+
+func TestRegisterAnnotationWithParams(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotationWithParams("Route", []ParamDef{
+		{Name: "path", Required: true},
+		{Name: "method", Default: "GET"},
+		{Name: "timeout"},
+	}, nil)
+
+	annotation, ok := ResolveAnnotation(`// @Route( path = "/users" )`)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"path": "/users", "method": "GET"}, annotation.Attributes)
+
+	annotation, ok = ResolveAnnotation(`// @Route( path = "/users", method = "POST" )`)
+	assert.True(t, ok)
+	assert.Equal(t, "POST", annotation.Attributes["method"])
+
+	_, ok = ResolveAnnotation(`// @Route( method = "POST" )`)
+	assert.False(t, ok)
+
+	errs := ValidationErrors([]string{`// @Route( method = "POST" )`})
+	assert.Equal(t, 1, len(errs))
+	assert.EqualError(t, errs[0], "@Route requires a non-empty 'path' attribute")
+
+	info, _ := DefaultRegistry.Get("Route")
+	assert.Equal(t, []string{"path", "method", "timeout"}, info.ParamNames)
+}
+
+func TestRegisterAnnotationWithParamsAndValidator(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotationWithParams("Route", []ParamDef{{Name: "path", Required: true}, {Name: "method", Default: "GET"}}, func(annot Annotation) (bool, error) {
+		if annot.Attributes["method"] != "GET" && annot.Attributes["method"] != "POST" {
+			return false, fmt.Errorf("Unsupported method %s", annot.Attributes["method"])
+		}
+		return true, nil
+	})
+
+	_, ok := ResolveAnnotation(`// @Route( path = "/users" )`)
+	assert.True(t, ok, "the validator should see the default method")
+
+	errs := ValidationErrors([]string{`// @Route( path = "/users", method = "PATCH" )`})
+	assert.Equal(t, 1, len(errs))
+	assert.EqualError(t, errs[0], "Unsupported method PATCH")
+}
+
 func TestAnnotationWithValidationError(t *testing.T) {
 	ClearRegisteredAnnotations()
 	RegisterAnnotation("X", []string{}, validateError)
@@ -57,6 +198,80 @@ func TestAnnotationWithValidationError(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestValidationErrors(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("X", []string{}, validateError)
+	RegisterExplainingAnnotation("Y", []string{"a"}, func(annot Annotation) (bool, error) {
+		if annot.Attributes["a"] == "" {
+			return false, fmt.Errorf("Y requires a non-empty 'a' attribute")
+		}
+		return true, nil
+	})
+
+	errs := ValidationErrors([]string{
+		`// @X( a = "A" )`,
+		`// @Y( a = "A" )`,
+		`// @Y( b = "B" )`,
+		`// @Z( a = "A" )`,
+	})
+	assert.Equal(t, 2, len(errs))
+	assert.EqualError(t, errs[0], "Annotation @X has invalid or missing attributes")
+	assert.EqualError(t, errs[1], "Y requires a non-empty 'a' attribute")
+
+	_, ok := ResolveAnnotation(`// @Y( a = "A" )`)
+	assert.True(t, ok)
+	_, ok = ResolveAnnotation(`// @Y( b = "B" )`)
+	assert.False(t, ok)
+}
+
+func TestValidationErrorsWithMultipleRegistrations(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("X", []string{}, validateError)
+	RegisterAnnotation("X", []string{}, validateOk)
+
+	assert.Empty(t, ValidationErrors([]string{`// @X( a = "A" )`}))
+}
+
+func TestLintAll(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("X", []string{"a", "description"}, validateOk, func(annot Annotation) string {
+		if annot.Attributes["description"] == "" {
+			return "@X has no description"
+		}
+		return ""
+	})
+	RegisterAnnotation("Y", []string{}, validateError, func(annot Annotation) string {
+		return "never reported for an invalid annotation"
+	})
+
+	errs, warnings := LintAll([]string{
+		`// @X( a = "A" )`,
+		`// @X( a = "A", description = "documented" )`,
+		`// @Y( a = "A" )`,
+	})
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, "Y", errs[0].AnnotationName)
+	assert.Equal(t, []AnnotationWarning{{AnnotationName: "X", Warning: "@X has no description"}}, warnings)
+
+	// warnings do not make an annotation invalid
+	_, ok := ResolveAnnotation(`// @X( a = "A" )`)
+	assert.True(t, ok)
+	assert.Empty(t, ValidationErrors([]string{`// @X( a = "A" )`}))
+}
+
+func TestLintAllWithDefaults(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotationWithParams("X", []ParamDef{{Name: "level", Default: "info"}}, nil, func(annot Annotation) string {
+		if annot.Attributes["level"] == "debug" {
+			return "@X should not log at level debug"
+		}
+		return ""
+	})
+
+	_, warnings := LintAll([]string{`// @X()`, `// @X( level = "debug" )`})
+	assert.Equal(t, []AnnotationWarning{{AnnotationName: "X", Warning: "@X should not log at level debug"}}, warnings)
+}
+
 func TestAnnotationWithTypicalCharacters(t *testing.T) {
 	ClearRegisteredAnnotations()
 	RegisterAnnotation("Doit", []string{}, validateOk)
@@ -68,6 +283,30 @@ func TestAnnotationWithTypicalCharacters(t *testing.T) {
 	assert.Equal(t, "/B", annotation.Attributes["b"])
 }
 
+func TestAnnotationWithUnquotedValues(t *testing.T) {
+	annotation, err := parseAnnotation(`// @Doit( a=false, b=60 )`)
+	assert.NoError(t, err)
+	assert.Equal(t, "false", annotation.Attributes["a"])
+	assert.Equal(t, "60", annotation.Attributes["b"])
+}
+
+func TestAnnotationWithQuotedValues(t *testing.T) {
+	annotation, err := parseAnnotation(`// @Doit( path = "/v1/search?q={q}&limit={limit}", list = "a, b", call = "f(x)", after = "A" )`)
+	assert.NoError(t, err)
+	assert.Equal(t, "/v1/search?q={q}&limit={limit}", annotation.Attributes["path"])
+	assert.Equal(t, "a, b", annotation.Attributes["list"])
+	assert.Equal(t, "f(x)", annotation.Attributes["call"])
+	assert.Equal(t, "A", annotation.Attributes["after"])
+}
+
+func TestAnnotationWithEscapedValues(t *testing.T) {
+	annotation, err := parseAnnotation(`// @Doit( quote = "say \"hi\"", backslash = "C:\\temp", regex = "/users/{id:\d+}" )`)
+	assert.NoError(t, err)
+	assert.Equal(t, `say "hi"`, annotation.Attributes["quote"])
+	assert.Equal(t, `C:\temp`, annotation.Attributes["backslash"])
+	assert.Equal(t, `/users/{id:\d+}`, annotation.Attributes["regex"])
+}
+
 func validateOk(annot Annotation) bool {
 	return true
 }
@@ -75,3 +314,362 @@ func validateOk(annot Annotation) bool {
 func validateError(annot Annotation) bool {
 	return false
 }
+
+func TestBareAnnotation(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("Logged", []string{}, validateOk)
+
+	annotation, ok := ResolveAnnotation(`// @Logged`)
+	assert.True(t, ok)
+	assert.Equal(t, "Logged", annotation.Name)
+	assert.Equal(t, map[string]string{}, annotation.Attributes)
+
+	annotation, ok = ResolveAnnotation(`// @Logged()`)
+	assert.True(t, ok)
+	assert.Equal(t, "Logged", annotation.Name)
+}
+
+func TestBareAnnotationWithinText(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("Logged", []string{}, validateOk)
+
+	_, ok := ResolveAnnotation(`// mail questions to me@Logged`)
+	assert.False(t, ok)
+
+	_, ok = ResolveAnnotation(`// @Logged because it is important`)
+	assert.False(t, ok)
+}
+
+func TestListAnnotations(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("Y", []string{"b"}, validateOk)
+	RegisterAnnotation("X", []string{"a", "c"}, validateOk)
+	RegisterAnnotation("Y", []string{"b"}, validateOk)
+
+	infos := ListAnnotations()
+	assert.Equal(t, []AnnotationInfo{
+		{Name: "X", ParamNames: []string{"a", "c"}},
+		{Name: "Y", ParamNames: []string{"b"}},
+	}, infos)
+}
+
+func TestUnregisteredNames(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("RestOperation", []string{"method", "path"}, validateOk)
+
+	names := UnregisteredNames([]string{
+		`// getPerson returns a person`,
+		`// @RestOperaton( method = "GET", path = "/person" )`,
+		`// @RestOperation( method = "GET", path = "/person" )`,
+		`// @Readonly`,
+		`// mail me at someone@example.com`,
+	})
+	assert.Equal(t, []string{"RestOperaton", "Readonly"}, names)
+	assert.True(t, IsRegistered("RestOperation"))
+	assert.False(t, IsRegistered("RestOperaton"))
+}
+
+func TestMustRegister(t *testing.T) {
+	ClearRegisteredAnnotations()
+	MustRegister("Event", []string{"aggregate"}, validateOk)
+	assert.True(t, IsRegistered("Event"))
+
+	assert.PanicsWithValue(t, "annotation: MustRegister called twice for annotation @Event", func() {
+		MustRegister("Event", []string{"aggregate"}, validateOk)
+	})
+}
+
+func TestAnnotationWithExpressionValues(t *testing.T) {
+	ClearRegisteredConstants()
+	defer ClearRegisteredConstants()
+	RegisterConstant("MaxRPS", 50)
+
+	annotation, err := parseAnnotation(`// @RateLimit( requestsPerSecond = ${MaxRPS}*2, burst = (${MaxRPS} + 10) / 3, delay = -1, name = "a*b" )`)
+	assert.NoError(t, err)
+	assert.Equal(t, "100", annotation.Attributes["requestspersecond"])
+	assert.Equal(t, "20", annotation.Attributes["burst"])
+	assert.Equal(t, "-1", annotation.Attributes["delay"])
+	assert.Equal(t, "a*b", annotation.Attributes["name"])
+}
+
+func TestAnnotationWithInvalidExpressionValues(t *testing.T) {
+	ClearRegisteredConstants()
+	defer ClearRegisteredConstants()
+	RegisterConstant("MaxRPS", 50)
+
+	_, err := parseAnnotation(`// @RateLimit( requestsPerSecond = ${MaxRPS}/0 )`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "division by zero")
+
+	_, err = parseAnnotation(`// @RateLimit( requestsPerSecond = ${MinRPS}*2 )`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Unknown constant MinRPS")
+
+	_, err = parseAnnotation(`// @RateLimit( requestsPerSecond = 2.5*2 )`)
+	assert.Error(t, err)
+}
+
+func TestAnnotationWithValuesThatAreNoExpression(t *testing.T) {
+	annotation, err := parseAnnotation(`// @SLO( latencyP99 = 200ms, type = pkg.Type, burst = 10*2 )`)
+	assert.NoError(t, err)
+	assert.Equal(t, "200ms", annotation.Attributes["latencyp99"])
+	assert.Equal(t, "pkg.Type", annotation.Attributes["type"])
+	assert.Equal(t, "20", annotation.Attributes["burst"])
+}
+
+func TestUnparseableAnnotationsAreReported(t *testing.T) {
+	ClearRegisteredAnnotations()
+	ClearRegisteredConstants()
+	RegisterAnnotation("RateLimit", []string{"requestsPerSecond"}, validateOk)
+
+	docLines := []string{
+		`// @RateLimit( requestsPerSecond = ${MinRPS}*2 )`,
+		`// @RateLimt( requestsPerSecond = ${MinRPS}*2 )`,
+		`// mail me at someone@example.com`,
+	}
+	errs := ValidationErrors(docLines)
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, "RateLimit", errs[0].AnnotationName)
+	assert.Contains(t, errs[0].Error(), "Unknown constant MinRPS")
+	assert.Equal(t, []string{"RateLimt"}, UnregisteredNames(docLines))
+}
+
+func TestRegistriesAreIndependent(t *testing.T) {
+	strict := NewRegistry()
+	strict.Register("Event", []string{"aggregate"}, func(annot Annotation) bool {
+		return annot.Attributes["aggregate"] != ""
+	})
+	lenient := NewRegistry()
+	lenient.Register("Event", []string{"aggregate"}, validateOk)
+
+	_, ok := strict.ResolveAnnotation(`// @Event()`)
+	assert.False(t, ok)
+	assert.Equal(t, 1, len(strict.Validate([]string{`// @Event()`})))
+
+	_, ok = lenient.ResolveAnnotation(`// @Event()`)
+	assert.True(t, ok)
+	assert.Empty(t, lenient.Validate([]string{`// @Event()`}))
+
+	lenient.Unregister("Event")
+	assert.False(t, lenient.IsRegistered("Event"))
+	assert.True(t, strict.IsRegistered("Event"))
+
+	info, found := strict.Get("Event")
+	assert.True(t, found)
+	assert.Equal(t, AnnotationInfo{Name: "Event", ParamNames: []string{"aggregate"}}, info)
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("Event", []string{"aggregate"}, validateOk)
+	defer ClearRegisteredAnnotations()
+
+	assert.True(t, DefaultRegistry.IsRegistered("Event"))
+	assert.False(t, NewRegistry().IsRegistered("Event"))
+}
+
+func TestParseAnnotationsIsCached(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("Event", []string{"aggregate"}, validateOk)
+	defer ClearRegisteredAnnotations()
+
+	docLines := []string{`// @Event( aggregate = "Tour" )`}
+	first := ParseAnnotations(docLines)
+	assert.Equal(t, 1, parseCache.len())
+
+	second := ParseAnnotations([]string{`// @Event( aggregate = "Tour" )`})
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, parseCache.len())
+
+	// callers cannot corrupt the cache by modifying the result
+	second[0] = Annotation{Name: "Other"}
+	assert.Equal(t, first, ParseAnnotations(docLines))
+	first[0].Attributes["aggregate"] = "Other"
+	assert.Equal(t, "Tour", ParseAnnotations(docLines)[0].Attributes["aggregate"])
+	annotation, _ := ResolveAnnotationByName(docLines, "Event")
+	annotation.Attributes["aggregate"] = "Other"
+	assert.Equal(t, "Tour", ParseAnnotations(docLines)[0].Attributes["aggregate"])
+
+	ClearCache()
+	assert.Equal(t, 0, parseCache.len())
+}
+
+func TestCacheIsClearedOnRegistration(t *testing.T) {
+	ClearRegisteredAnnotations()
+	defer ClearRegisteredAnnotations()
+
+	docLines := []string{`// @Event( aggregate = "Tour" )`}
+	assert.Empty(t, ParseAnnotations(docLines))
+
+	RegisterAnnotation("Event", []string{"aggregate"}, validateOk)
+	assert.Equal(t, 1, len(ParseAnnotations(docLines)))
+
+	DefaultRegistry.Unregister("Event")
+	assert.Empty(t, ParseAnnotations(docLines))
+}
+
+func TestRegisteringTwiceHasNoEffect(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("Event", []string{"aggregate"}, validateOk)
+	registry.RegisterWithParams("Tour", []ParamDef{{Name: "year", Required: true}}, nil)
+
+	docLines := []string{`// @Event( aggregate = "Tour" )`}
+	registry.ParseAnnotations(docLines)
+	assert.Equal(t, 1, parseCache.len())
+
+	registry.Register("Event", []string{"aggregate"}, validateOk)
+	registry.RegisterWithParams("Tour", []ParamDef{{Name: "year", Required: true}}, nil)
+	assert.Equal(t, 2, len(registry.snapshot()))
+	assert.Equal(t, 1, parseCache.len())
+
+	// another validator or other parameters are another registration
+	registry.Register("Event", []string{"aggregate"}, validateError)
+	registry.Register("Event", []string{"aggregate", "version"}, validateOk)
+	assert.Equal(t, 4, len(registry.snapshot()))
+
+	// function-literals cannot be told apart
+	validator := func(annot Annotation) bool { return true }
+	registry.Register("Event", []string{"aggregate"}, validator)
+	registry.Register("Event", []string{"aggregate"}, validator)
+	assert.Equal(t, 6, len(registry.snapshot()))
+	ClearCache()
+}
+
+func TestCacheIsClearedOnConstantRegistration(t *testing.T) {
+	ClearRegisteredAnnotations()
+	RegisterAnnotation("Cacheable", []string{"maxAge"}, validateOk)
+	defer ClearRegisteredAnnotations()
+	defer ClearRegisteredConstants()
+
+	docLines := []string{`// @Cacheable( maxAge = ${MinuteSeconds}*5 )`}
+	assert.Empty(t, ParseAnnotations(docLines))
+
+	RegisterConstant("MinuteSeconds", 60)
+	annotations := ParseAnnotations(docLines)
+	assert.Equal(t, 1, len(annotations))
+	assert.Equal(t, "300", annotations[0].Attributes["maxage"])
+}
+
+func TestCacheIsKeptPerRegistry(t *testing.T) {
+	strict := NewRegistry()
+	strict.Register("Event", []string{"aggregate"}, validateError)
+	lenient := NewRegistry()
+	lenient.Register("Event", []string{"aggregate"}, validateOk)
+
+	docLines := []string{`// @Event( aggregate = "Tour" )`}
+	assert.Empty(t, strict.ParseAnnotations(docLines))
+	assert.Equal(t, 1, len(lenient.ParseAnnotations(docLines)))
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newLRUCache(2)
+	r := NewRegistry()
+	a := newCacheKey(r, []string{"a"})
+	b := newCacheKey(r, []string{"b"})
+	c := newCacheKey(r, []string{"c"})
+
+	cache.put(a, []Annotation{{Name: "A"}}, cache.currentGeneration())
+	cache.put(b, []Annotation{{Name: "B"}}, cache.currentGeneration())
+	_, found := cache.get(a)
+	assert.True(t, found)
+
+	cache.put(c, []Annotation{{Name: "C"}}, cache.currentGeneration())
+	assert.Equal(t, 2, cache.len())
+	_, found = cache.get(b)
+	assert.False(t, found)
+	_, found = cache.get(a)
+	assert.True(t, found)
+	_, found = cache.get(c)
+	assert.True(t, found)
+}
+
+func TestCacheIgnoresParsesThatOverlapAClear(t *testing.T) {
+	cache := newLRUCache(2)
+	key := newCacheKey(NewRegistry(), []string{"a"})
+
+	generation := cache.currentGeneration()
+	cache.clear()
+	cache.put(key, []Annotation{{Name: "A"}}, generation)
+	_, found := cache.get(key)
+	assert.False(t, found)
+}
+
+func TestConcurrentRegistration(t *testing.T) {
+	ClearRegisteredAnnotations()
+	defer ClearRegisteredAnnotations()
+	defer ClearRegisteredConstants()
+
+	done := make(chan bool)
+	for i := 0; i < 4; i++ {
+		go func(i int) {
+			name := fmt.Sprintf("Event%d", i)
+			RegisterAnnotation(name, []string{"maxAge"}, validateOk)
+			RegisterConstant(name, i)
+			a, ok := ResolveAnnotations([]string{fmt.Sprintf("// @%s( maxAge = ${%s}+1 )", name, name)})
+			assert.True(t, ok)
+			assert.Equal(t, fmt.Sprintf("%d", i+1), a.Attributes["maxage"])
+			done <- true
+		}(i)
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+	assert.Equal(t, 4, len(ListAnnotations()))
+}
+
+// benchmarkDocLines returns the doc-lines of 500 annotated structs
+func benchmarkDocLines() [][]string {
+	docLines := [][]string{}
+	for i := 0; i < 500; i++ {
+		docLines = append(docLines, []string{
+			fmt.Sprintf("// Struct%d is a struct in a large code-base", i),
+			fmt.Sprintf(`// @Event( aggregate = "Aggregate%d" )`, i),
+			fmt.Sprintf(`// @RestService( path = "/api/struct%d" )`, i),
+			`// @GobEncodable()`,
+		})
+	}
+	return docLines
+}
+
+func registerBenchmarkAnnotations() {
+	ClearRegisteredAnnotations()
+	for _, name := range []string{"Event", "RestService", "GobEncodable", "Entity"} {
+		RegisterAnnotation(name, []string{}, validateOk)
+	}
+}
+
+// BenchmarkParseAnnotations runs 4 generators that each look up their annotation on 500 structs
+func BenchmarkParseAnnotations(b *testing.B) {
+	registerBenchmarkAnnotations()
+	defer ClearRegisteredAnnotations()
+	docLines := benchmarkDocLines()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, generator := range []string{"Event", "RestService", "GobEncodable", "Entity"} {
+			for _, lines := range docLines {
+				ResolveAnnotationByName(lines, generator)
+			}
+		}
+	}
+}
+
+// BenchmarkParseAnnotationsUncached is the baseline of BenchmarkParseAnnotations
+func BenchmarkParseAnnotationsUncached(b *testing.B) {
+	registerBenchmarkAnnotations()
+	defer ClearRegisteredAnnotations()
+	docLines := benchmarkDocLines()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, generator := range []string{"Event", "RestService", "GobEncodable", "Entity"} {
+			for _, lines := range docLines {
+				for _, a := range DefaultRegistry.parseAnnotations(lines) {
+					if a.Name == generator {
+						break
+					}
+				}
+			}
+		}
+	}
+}
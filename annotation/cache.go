@@ -0,0 +1,104 @@
+package annotation
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+)
+
+// cacheCapacity is the number of doc-line slices of which the parsed annotations are kept
+const cacheCapacity = 4096
+
+// parseCache remembers the parsed annotations of doc-lines, because every generator parses the doc-lines of the same
+// structs and operations over and over again
+var parseCache = newLRUCache(cacheCapacity)
+
+// ClearCache forgets all parsed annotations. The cache is cleared automatically when annotations or constants are
+// (un)registered.
+func ClearCache() {
+	parseCache.clear()
+}
+
+type cacheKey struct {
+	registry *Registry
+	doc      string
+}
+
+type cacheEntry struct {
+	key         cacheKey
+	annotations []Annotation
+}
+
+// lruCache evicts the least recently used entry once it is full
+type lruCache struct {
+	mutex      sync.Mutex
+	capacity   int
+	entries    map[cacheKey]*list.Element
+	order      *list.List
+	generation uint64 // incremented on every clear, so results of parses that overlap a registration are not kept
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		entries:  map[cacheKey]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+func newCacheKey(r *Registry, annotationDocline []string) cacheKey {
+	return cacheKey{registry: r, doc: strings.Join(annotationDocline, "\n")}
+}
+
+func (c *lruCache) get(key cacheKey) ([]Annotation, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).annotations, true
+}
+
+// currentGeneration is to be retrieved before parsing and passed to put
+func (c *lruCache) currentGeneration() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.generation
+}
+
+// put remembers the parsed annotations, unless the cache has been cleared since the parsing started: the annotations
+// may have been parsed with registrations that are outdated by now
+func (c *lruCache) put(key cacheKey, annotations []Annotation, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if element, found := c.entries[key]; found {
+		element.Value.(*cacheEntry).annotations = annotations
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, annotations: annotations})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *lruCache) len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+func (c *lruCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = map[cacheKey]*list.Element{}
+	c.order.Init()
+	c.generation++
+}
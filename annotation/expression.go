@@ -0,0 +1,124 @@
+package annotation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+var (
+	constantMutex    sync.RWMutex
+	constantRegistry = map[string]int{}
+)
+
+// constantPattern matches references to registered constants, like ${MaxRPS}
+var constantPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expressionPattern matches values that only consist of numbers, operators and parentheses, once the references to
+// constants are left out
+var expressionPattern = regexp.MustCompile(`^[0-9.+\-*/()]+$`)
+
+// RegisterConstant makes an integer constant available to attribute-values like "${MaxRPS}*2"
+func RegisterConstant(name string, value int) {
+	constantMutex.Lock()
+	defer constantMutex.Unlock()
+	defer ClearCache()
+	constantRegistry[name] = value
+}
+
+func ClearRegisteredConstants() {
+	constantMutex.Lock()
+	defer constantMutex.Unlock()
+	defer ClearCache()
+	constantRegistry = map[string]int{}
+}
+
+func lookupConstant(name string) (int, bool) {
+	constantMutex.RLock()
+	defer constantMutex.RUnlock()
+	value, found := constantRegistry[name]
+	return value, found
+}
+
+// isExpression tells whether a value that spans multiple tokens is meant to be calculated, like ${MaxRPS}*2
+func isExpression(value string) bool {
+	return expressionPattern.MatchString(constantPattern.ReplaceAllString(value, "0"))
+}
+
+// evaluateExpression calculates attribute-values composed of integers, registered constants and the
+// operators +, -, * and /
+func evaluateExpression(expression string) (int, error) {
+	var unknown error
+	resolved := constantPattern.ReplaceAllStringFunc(expression, func(ref string) string {
+		name := constantPattern.FindStringSubmatch(ref)[1]
+		value, found := lookupConstant(name)
+		if !found {
+			unknown = fmt.Errorf("Unknown constant %s in expression %s", name, expression)
+			return ref
+		}
+		return fmt.Sprintf("(%d)", value)
+	})
+	if unknown != nil {
+		return 0, unknown
+	}
+
+	expr, err := parser.ParseExpr(resolved)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid expression %s:%s", expression, err)
+	}
+	value, err := evaluate(expr)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid expression %s:%s", expression, err)
+	}
+	return value, nil
+}
+
+func evaluate(expr ast.Expr) (int, error) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.INT {
+			return 0, fmt.Errorf("%s is not an integer", e.Value)
+		}
+		return strconv.Atoi(e.Value)
+	case *ast.ParenExpr:
+		return evaluate(e.X)
+	case *ast.UnaryExpr:
+		x, err := evaluate(e.X)
+		if err != nil {
+			return 0, err
+		}
+		switch e.Op {
+		case token.SUB:
+			return -x, nil
+		case token.ADD:
+			return x, nil
+		}
+	case *ast.BinaryExpr:
+		x, err := evaluate(e.X)
+		if err != nil {
+			return 0, err
+		}
+		y, err := evaluate(e.Y)
+		if err != nil {
+			return 0, err
+		}
+		switch e.Op {
+		case token.ADD:
+			return x + y, nil
+		case token.SUB:
+			return x - y, nil
+		case token.MUL:
+			return x * y, nil
+		case token.QUO:
+			if y == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return x / y, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported operation")
+}
@@ -1 +1,375 @@
 package annotation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type annotationDescriptor struct {
+	name           string
+	paramNames     []string
+	params         []ParamDef // only for annotations that are registered with their parameter-definitions
+	validator      ExplainingValidationFunc
+	warnValidators []WarnValidator // only run for annotations that the validator accepts
+	identity       string          // empty when the validators cannot be told apart from other validators
+}
+
+// Registry holds the annotations that are known: annotations that are not registered are never resolved.
+// Tests can use their own registry, to avoid interfering with other tests.
+type Registry struct {
+	mutex       sync.RWMutex
+	descriptors []annotationDescriptor
+}
+
+// DefaultRegistry is the registry that is used by the package-level functions
+var DefaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{descriptors: []annotationDescriptor{}}
+}
+
+// Register adds an annotation with its parameter-names and validator. An annotation can be registered more than once:
+// it is valid when any of its validators accepts it. The warn-validators of that registration report the soft rules that
+// the valid annotation does not follow. Registering the same parameters and validator-functions again has no effect.
+func (r *Registry) Register(name string, paramNames []string, validator ValidationFunc, warnValidators ...WarnValidator) {
+	r.add(annotationDescriptor{
+		name:           name,
+		paramNames:     paramNames,
+		validator:      explainingValidator(name, validator),
+		warnValidators: warnValidators,
+		identity:       identityOf(name, paramNames, nil, validator, warnValidators),
+	})
+}
+
+// RegisterExplaining adds an annotation with a validator that explains why an annotation is invalid
+func (r *Registry) RegisterExplaining(name string, paramNames []string, validator ExplainingValidationFunc, warnValidators ...WarnValidator) {
+	r.add(annotationDescriptor{
+		name:           name,
+		paramNames:     paramNames,
+		validator:      validator,
+		warnValidators: warnValidators,
+		identity:       identityOf(name, paramNames, nil, validator, warnValidators),
+	})
+}
+
+// RegisterWithParams adds an annotation of which the required parameters are enforced before the validator is called:
+// omitted optional parameters get their default-value
+func (r *Registry) RegisterWithParams(name string, params []ParamDef, validator ExplainingValidationFunc, warnValidators ...WarnValidator) {
+	paramNames := []string{}
+	for _, p := range params {
+		paramNames = append(paramNames, p.Name)
+	}
+	r.add(annotationDescriptor{
+		name:           name,
+		paramNames:     paramNames,
+		params:         append([]ParamDef{}, params...),
+		validator:      paramValidator(name, params, validator),
+		warnValidators: warnValidators,
+		identity:       identityOf(name, paramNames, params, validator, warnValidators),
+	})
+}
+
+// add appends the registration, unless an identical registration is already present: the generators register their
+// annotations every time they run, which would otherwise add up and clear the cache every time
+func (r *Registry) add(descriptor annotationDescriptor) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if descriptor.identity != "" {
+		for _, existing := range r.descriptors {
+			if existing.identity == descriptor.identity {
+				return
+			}
+		}
+	}
+	defer ClearCache()
+	r.descriptors = append(r.descriptors, descriptor)
+}
+
+// closurePattern matches the names that the compiler gives to function-literals and method-values, like
+// eventAnnotation.Register.func1 and annotation.(*Registry).Validate-fm
+var closurePattern = regexp.MustCompile(`(\.func\d+(\.\d+)*|-fm)$`)
+
+// identityOf describes a registration by its name, parameters and validator-functions. It is empty when one of the
+// validators is a function-literal or a method-value: they share their code, whatever values they capture.
+func identityOf(name string, paramNames []string, params []ParamDef, validator interface{}, warnValidators []WarnValidator) string {
+	functions := []interface{}{validator}
+	for _, warnValidator := range warnValidators {
+		functions = append(functions, warnValidator)
+	}
+	names := []string{}
+	for _, function := range functions {
+		value := reflect.ValueOf(function)
+		if value.Kind() != reflect.Func {
+			return ""
+		}
+		if value.IsNil() {
+			names = append(names, "nil")
+			continue
+		}
+		f := runtime.FuncForPC(value.Pointer())
+		if f == nil || closurePattern.MatchString(f.Name()) {
+			return ""
+		}
+		names = append(names, f.Name())
+	}
+	return fmt.Sprintf("%s %q %+v %s", name, paramNames, params, strings.Join(names, " "))
+}
+
+// MustRegister adds an annotation like Register, but panics when an annotation with the same name has already been
+// registered
+func (r *Registry) MustRegister(name string, paramNames []string, validator ValidationFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	defer ClearCache()
+	for _, descriptor := range r.descriptors {
+		if descriptor.name == name {
+			panic(fmt.Sprintf("annotation: MustRegister called twice for annotation @%s", name))
+		}
+	}
+	r.descriptors = append(r.descriptors, annotationDescriptor{name: name, paramNames: paramNames, validator: explainingValidator(name, validator)})
+}
+
+// Unregister removes all registrations of the annotation
+func (r *Registry) Unregister(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	defer ClearCache()
+	descriptors := []annotationDescriptor{}
+	for _, descriptor := range r.descriptors {
+		if descriptor.name != name {
+			descriptors = append(descriptors, descriptor)
+		}
+	}
+	r.descriptors = descriptors
+}
+
+// Clear removes all annotations
+func (r *Registry) Clear() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	defer ClearCache()
+	r.descriptors = []annotationDescriptor{}
+}
+
+// Get describes the registered annotation with the given name
+func (r *Registry) Get(name string) (AnnotationInfo, bool) {
+	for _, descriptor := range r.snapshot() {
+		if descriptor.name == name {
+			return AnnotationInfo{Name: descriptor.name, ParamNames: append([]string{}, descriptor.paramNames...)}, true
+		}
+	}
+	return AnnotationInfo{}, false
+}
+
+// IsRegistered tells if an annotation with the given name has been registered
+func (r *Registry) IsRegistered(name string) bool {
+	_, found := r.Get(name)
+	return found
+}
+
+// List returns all registered annotations, sorted by name
+func (r *Registry) List() []AnnotationInfo {
+	infos := []AnnotationInfo{}
+	seen := make(map[string]bool)
+	for _, descriptor := range r.snapshot() {
+		if seen[descriptor.name] {
+			continue
+		}
+		seen[descriptor.name] = true
+		infos = append(infos, AnnotationInfo{
+			Name:       descriptor.name,
+			ParamNames: append([]string{}, descriptor.paramNames...),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// UnregisteredNames returns the names of the annotations in the doc-lines that have not been registered, which are most
+// likely typos
+func (r *Registry) UnregisteredNames(annotationDocline []string) []string {
+	names := []string{}
+	for _, line := range annotationDocline {
+		a, err := parseAnnotation(strings.TrimSpace(line))
+		if a.Name == "" || (err != nil && !isAnnotationLine(line)) {
+			continue
+		}
+		if !r.IsRegistered(a.Name) {
+			names = append(names, a.Name)
+		}
+	}
+	return names
+}
+
+// Validate explains why the registered annotations in the doc-lines are invalid: invalid annotations are ignored by
+// the generators
+func (r *Registry) Validate(annotationDocline []string) []ValidationError {
+	errs, _ := r.Lint(annotationDocline)
+	return errs
+}
+
+// Lint explains why the registered annotations in the doc-lines are invalid, like Validate, and also returns the
+// warnings of the registrations that accept the valid annotations
+func (r *Registry) Lint(annotationDocline []string) ([]ValidationError, []AnnotationWarning) {
+	descriptors := r.snapshot()
+	errs := []ValidationError{}
+	warnings := []AnnotationWarning{}
+	for _, line := range annotationDocline {
+		a, err := parseAnnotation(strings.TrimSpace(line))
+		if a.Name == "" {
+			continue
+		}
+		if err != nil {
+			// a registered annotation that cannot be parsed, like one with an invalid expression, is not used either
+			if isAnnotationLine(line) && r.IsRegistered(a.Name) {
+				errs = append(errs, ValidationError{AnnotationName: a.Name, Reason: err})
+			}
+			continue
+		}
+		registered, valid := false, false
+		var reason error
+		for _, descriptor := range descriptors {
+			if descriptor.name != a.Name {
+				continue
+			}
+			registered = true
+			resolved := withDefaults(a, descriptor.params)
+			ok, err := descriptor.validator(resolved)
+			if ok {
+				valid = true
+				for _, warnValidator := range descriptor.warnValidators {
+					if warning := warnValidator(resolved); warning != "" {
+						warnings = append(warnings, AnnotationWarning{AnnotationName: a.Name, Warning: warning})
+					}
+				}
+				break
+			}
+			if reason == nil {
+				reason = err
+			}
+		}
+		if registered && !valid {
+			if reason == nil {
+				reason = fmt.Errorf("Annotation @%s has invalid or missing attributes", a.Name)
+			}
+			errs = append(errs, ValidationError{AnnotationName: a.Name, Reason: reason})
+		}
+	}
+	return errs, warnings
+}
+
+// isAnnotationLine tells whether the doc-line starts with an annotation, to tell malformed annotations apart from text
+// that happens to contain an @
+func isAnnotationLine(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "/")), "@")
+}
+
+// ResolveAnnotation returns the annotation in the doc-line, when it is registered and valid
+func (r *Registry) ResolveAnnotation(annotationDocline string) (Annotation, bool) {
+	annotation, err := parseAnnotation(annotationDocline)
+	if err != nil {
+		return Annotation{}, false
+	}
+	for _, descriptor := range r.snapshot() {
+		if annotation.Name != descriptor.name {
+			continue
+		}
+
+		resolved := withDefaults(annotation, descriptor.params)
+		ok, _ := descriptor.validator(resolved)
+		if !ok {
+			continue
+		}
+
+		return resolved, true
+	}
+	return Annotation{}, false
+}
+
+// ResolveAnnotations returns the first valid annotation in the doc-lines
+func (r *Registry) ResolveAnnotations(annotationDocline []string) (Annotation, bool) {
+	annotations := r.ParseAnnotations(annotationDocline)
+	if len(annotations) == 0 {
+		return Annotation{}, false
+	}
+	return annotations[0], true
+}
+
+// ParseAnnotations returns all valid annotations in the order in which they appear in the doc-lines. The result is
+// cached, so it is cheap to parse the same doc-lines for every generator: callers get a copy they are free to modify.
+func (r *Registry) ParseAnnotations(annotationDocline []string) []Annotation {
+	key := newCacheKey(r, annotationDocline)
+	annotations, found := parseCache.get(key)
+	if !found {
+		generation := parseCache.currentGeneration()
+		annotations = r.parseAnnotations(annotationDocline)
+		parseCache.put(key, annotations, generation)
+	}
+	copied := make([]Annotation, 0, len(annotations))
+	for _, a := range annotations {
+		copied = append(copied, a.copy())
+	}
+	return copied
+}
+
+func (r *Registry) parseAnnotations(annotationDocline []string) []Annotation {
+	annotations := []Annotation{}
+	for _, line := range annotationDocline {
+		a, ok := r.ResolveAnnotation(strings.TrimSpace(line))
+		if ok {
+			annotations = append(annotations, a)
+		}
+	}
+	return annotations
+}
+
+// GetAll returns all valid annotations with the given name, in the order in which they appear in the doc-lines
+func (r *Registry) GetAll(annotationDocline []string, name string) []Annotation {
+	annotations := []Annotation{}
+	for _, a := range r.ParseAnnotations(annotationDocline) {
+		if a.Name == name {
+			annotations = append(annotations, a)
+		}
+	}
+	return annotations
+}
+
+// ResolveAnnotationByName returns the first valid annotation with the given name
+func (r *Registry) ResolveAnnotationByName(annotationDocline []string, name string) (Annotation, bool) {
+	for _, a := range r.ParseAnnotations(annotationDocline) {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Annotation{}, false
+}
+
+// GetAnnotation returns the first valid annotation with the given name, like ResolveAnnotationByName
+func (r *Registry) GetAnnotation(annotationDocline []string, name string) (Annotation, bool) {
+	return r.ResolveAnnotationByName(annotationDocline, name)
+}
+
+// GetAnnotationAttribute returns the value of an attribute of the first valid annotation with the given name. The name
+// of the attribute is case-insensitive.
+func (r *Registry) GetAnnotationAttribute(annotationDocline []string, annotName, attrName string) (string, bool) {
+	a, ok := r.GetAnnotation(annotationDocline, annotName)
+	if !ok {
+		return "", false
+	}
+	value, ok := a.Attributes[strings.ToLower(attrName)]
+	return value, ok
+}
+
+// snapshot returns the current registrations, so validators run without holding the lock
+func (r *Registry) snapshot() []annotationDescriptor {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.descriptors
+}
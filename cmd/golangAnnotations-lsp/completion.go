@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+)
+
+// complete returns the annotation-names or attribute-names that fit at the cursor
+func complete(line string, character int) []completionItem {
+	items := []completionItem{}
+
+	prefix := prefixAt(line, character)
+	if !isComment(prefix) {
+		return items
+	}
+	at := strings.LastIndex(prefix, "@")
+	if at < 0 {
+		return items
+	}
+	afterAt := prefix[at+1:]
+
+	open := strings.Index(afterAt, "(")
+	if open < 0 {
+		if !isIdentifier(afterAt) {
+			return items
+		}
+		// complete the name of the annotation, both bare and with parentheses
+		for _, info := range annotation.ListAnnotations() {
+			if !strings.HasPrefix(info.Name, afterAt) {
+				continue
+			}
+			items = append(items,
+				completionItem{Label: info.Name, Kind: completionItemKindKeyword, Detail: signature(info), InsertText: info.Name},
+				completionItem{Label: info.Name + "(", Kind: completionItemKindKeyword, Detail: signature(info), InsertText: info.Name + "( "},
+			)
+		}
+		return items
+	}
+
+	if strings.Contains(afterAt[open:], ")") {
+		return items
+	}
+	// complete the attributes that have not been used yet
+	name := strings.TrimSpace(afterAt[:open])
+	info, found := findAnnotation(name)
+	if !found {
+		return items
+	}
+	partial, used := attributesInProgress(afterAt[open+1:])
+	if partial == nil {
+		return items
+	}
+	for _, param := range info.ParamNames {
+		if used[param] || !strings.HasPrefix(param, strings.ToLower(*partial)) {
+			continue
+		}
+		items = append(items, completionItem{
+			Label:      param,
+			Kind:       completionItemKindProperty,
+			Detail:     signature(info),
+			InsertText: fmt.Sprintf(`%s = ""`, param),
+		})
+	}
+	return items
+}
+
+// describe explains the annotation under the cursor
+func describe(line string, character int) (*hover, bool) {
+	if !isComment(line) {
+		return nil, false
+	}
+	runes := []rune(line)
+	cursor := runeIndex(line, character)
+
+	for start := 0; start < len(runes); start++ {
+		if runes[start] != '@' {
+			continue
+		}
+		end := start + 1
+		for end < len(runes) && isIdentifierRune(runes[end]) {
+			end++
+		}
+		if cursor < start || cursor > end {
+			continue
+		}
+		info, found := findAnnotation(string(runes[start+1 : end]))
+		if !found {
+			return nil, false
+		}
+		text := fmt.Sprintf("**@%s**\n\n%s", info.Name, signature(info))
+		if len(info.ParamNames) > 0 {
+			text += "\n\nParameters: " + strings.Join(info.ParamNames, ", ")
+		} else {
+			text += "\n\nThis annotation has no parameters."
+		}
+		return &hover{Contents: markupContent{Kind: "markdown", Value: text}}, true
+	}
+	return nil, false
+}
+
+// attributesInProgress returns the name of the attribute being typed, or nil when a value is being typed,
+// and the attributes that were already used
+func attributesInProgress(attributes string) (*string, map[string]bool) {
+	used := make(map[string]bool)
+	parts := strings.Split(attributes, ",")
+	for _, part := range parts[:len(parts)-1] {
+		if idx := strings.Index(part, "="); idx >= 0 {
+			used[strings.ToLower(strings.TrimSpace(part[:idx]))] = true
+		}
+	}
+	last := parts[len(parts)-1]
+	if strings.Contains(last, "=") {
+		return nil, used
+	}
+	partial := strings.TrimSpace(last)
+	return &partial, used
+}
+
+func findAnnotation(name string) (annotation.AnnotationInfo, bool) {
+	for _, info := range annotation.ListAnnotations() {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return annotation.AnnotationInfo{}, false
+}
+
+func signature(info annotation.AnnotationInfo) string {
+	params := []string{}
+	for _, param := range info.ParamNames {
+		params = append(params, fmt.Sprintf(`%s = "..."`, param))
+	}
+	return fmt.Sprintf("@%s( %s )", info.Name, strings.Join(params, ", "))
+}
+
+func isComment(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "//")
+}
+
+func isIdentifier(s string) bool {
+	for _, r := range s {
+		if !isIdentifierRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func lineAt(text string, line int) string {
+	lines := strings.Split(text, "\n")
+	if line < 0 || line >= len(lines) {
+		return ""
+	}
+	return strings.TrimSuffix(lines[line], "\r")
+}
+
+// prefixAt returns the part of the line before the cursor: the protocol counts characters in utf-16 code-units
+func prefixAt(line string, character int) string {
+	runes := []rune(line)
+	return string(runes[:runeIndex(line, character)])
+}
+
+func runeIndex(line string, character int) int {
+	units := 0
+	for idx, r := range []rune(line) {
+		if units >= character {
+			return idx
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len([]rune(line))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/stretchr/testify/assert"
+)
+
+func registerTestAnnotations() {
+	annotation.ClearRegisteredAnnotations()
+	annotation.RegisterAnnotation("RestOperation", []string{"method", "path"}, func(annotation.Annotation) bool { return true })
+	annotation.RegisterAnnotation("RestService", []string{"path"}, func(annotation.Annotation) bool { return true })
+}
+
+func labels(items []completionItem) []string {
+	result := []string{}
+	for _, item := range items {
+		result = append(result, item.Label)
+	}
+	return result
+}
+
+func TestCompleteAnnotationName(t *testing.T) {
+	registerTestAnnotations()
+
+	line := "// @RestO"
+	items := complete(line, len(line))
+	assert.Equal(t, []string{"RestOperation", "RestOperation("}, labels(items))
+	assert.Equal(t, `@RestOperation( method = "...", path = "..." )`, items[0].Detail)
+}
+
+func TestCompleteAllAnnotationNames(t *testing.T) {
+	registerTestAnnotations()
+
+	line := "// @"
+	items := complete(line, len(line))
+	assert.Equal(t, []string{"RestOperation", "RestOperation(", "RestService", "RestService("}, labels(items))
+}
+
+func TestCompleteAttributeNames(t *testing.T) {
+	registerTestAnnotations()
+
+	line := `// @RestOperation( method = "GET", `
+	items := complete(line, len(line))
+	assert.Equal(t, []string{"path"}, labels(items))
+	assert.Equal(t, `path = ""`, items[0].InsertText)
+
+	line = `// @RestOperation( `
+	assert.Equal(t, []string{"method", "path"}, labels(complete(line, len(line))))
+}
+
+func TestNoCompletionOutsideComments(t *testing.T) {
+	registerTestAnnotations()
+
+	line := `s := "@Rest`
+	assert.Empty(t, complete(line, len(line)))
+
+	line = `// @RestOperation( method = "G`
+	assert.Empty(t, complete(line, len(line)))
+
+	line = `// @RestService( path = "/api" ) `
+	assert.Empty(t, complete(line, len(line)))
+}
+
+func TestHover(t *testing.T) {
+	registerTestAnnotations()
+
+	h, found := describe(`// @RestService( path = "/api" )`, 6)
+	assert.True(t, found)
+	assert.Contains(t, h.Contents.Value, "**@RestService**")
+	assert.Contains(t, h.Contents.Value, "Parameters: path")
+
+	_, found = describe(`// @RestService( path = "/api" )`, 25)
+	assert.False(t, found)
+}
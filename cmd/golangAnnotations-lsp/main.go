@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bufio"
+	"log"
+	"os"
+
+	"github.com/MarcGrol/golangAnnotations/generator/dbdoc/dbdocAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/event/eventAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/eventstore/eventstoreAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/fuzz/fuzzAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/gateway/gatewayAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/gob/gobAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/grpc/grpcAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/lambda/lambdaAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/proptest/proptestAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/rest/restAnnotation"
+)
+
+// golangAnnotations-lsp is a language-server that completes and explains annotations in go doc-comments.
+// It communicates with the editor via stdin and stdout, so all logging goes to stderr.
+func main() {
+	log.SetOutput(os.Stderr)
+
+	eventAnnotation.Register()
+	eventstoreAnnotation.Register()
+	restAnnotation.Register()
+	gobAnnotation.Register()
+	lambdaAnnotation.Register()
+	gatewayAnnotation.Register()
+	fuzzAnnotation.Register()
+	proptestAnnotation.Register()
+	dbdocAnnotation.Register()
+	grpcAnnotation.Register()
+
+	s := newServer(bufio.NewReader(os.Stdin), os.Stdout)
+	err := s.serve()
+	if err != nil {
+		log.Printf("Error serving language-server protocol: %s", err)
+		os.Exit(1)
+	}
+	os.Exit(s.exitCode())
+}
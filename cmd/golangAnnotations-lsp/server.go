@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+)
+
+type server struct {
+	in        *bufio.Reader
+	out       io.Writer
+	documents map[string]string
+	shutdown  bool
+	exited    bool
+}
+
+func newServer(in *bufio.Reader, out io.Writer) *server {
+	return &server{
+		in:        in,
+		out:       out,
+		documents: make(map[string]string),
+	}
+}
+
+// serve handles messages until the client sends exit or closes the connection
+func (s *server) serve() error {
+	for !s.exited {
+		body, err := readMessage(s.in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		err = json.Unmarshal(body, &req)
+		if err != nil {
+			log.Printf("Error decoding message: %s", err)
+			continue
+		}
+		err = s.handle(req)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exitCode follows the protocol: exiting without a preceding shutdown is an error
+func (s *server) exitCode() int {
+	if s.shutdown {
+		return 0
+	}
+	return 1
+}
+
+func (s *server) handle(req request) error {
+	switch req.Method {
+	case "initialize":
+		return s.reply(req, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": 1, // full document on every change
+				"completionProvider": map[string]interface{}{
+					"triggerCharacters": []string{"@", "(", ","},
+				},
+				"hoverProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "golangAnnotations-lsp"},
+		})
+
+	case "shutdown":
+		s.shutdown = true
+		return s.reply(req, nil)
+
+	case "exit":
+		s.exited = true
+		return nil
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(req.Params, &params); err == nil {
+			s.documents[params.TextDocument.URI] = params.TextDocument.Text
+		}
+		return nil
+
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(req.Params, &params); err == nil && len(params.ContentChanges) > 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		}
+		return nil
+
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(req.Params, &params); err == nil {
+			delete(s.documents, params.TextDocument.URI)
+		}
+		return nil
+
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.replyError(req, codeInvalidParams, err.Error())
+		}
+		line := lineAt(s.documents[params.TextDocument.URI], params.Position.Line)
+		return s.reply(req, complete(line, params.Position.Character))
+
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.replyError(req, codeInvalidParams, err.Error())
+		}
+		line := lineAt(s.documents[params.TextDocument.URI], params.Position.Line)
+		h, found := describe(line, params.Position.Character)
+		if !found {
+			return s.reply(req, nil)
+		}
+		return s.reply(req, h)
+
+	default:
+		// notifications without a handler are ignored, requests are not
+		if req.ID != nil {
+			return s.replyError(req, codeMethodNotFound, fmt.Sprintf("Method %s is not supported", req.Method))
+		}
+		return nil
+	}
+}
+
+func (s *server) reply(req request, result interface{}) error {
+	return writeMessage(s.out, response{JSONRPC: "2.0", ID: req.ID, Result: result})
+}
+
+func (s *server) replyError(req request, code int, message string) error {
+	return writeMessage(s.out, errorResponse{JSONRPC: "2.0", ID: req.ID, Error: responseError{Code: code, Message: message}})
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func frame(msg string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(msg), msg)
+}
+
+func TestServerCompletionSession(t *testing.T) {
+	registerTestAnnotations()
+
+	input := frame(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`) +
+		frame(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.go","text":"package a\n\n// @RestS\ntype S struct{}\n"}}}`) +
+		frame(`{"jsonrpc":"2.0","id":2,"method":"textDocument/completion","params":{"textDocument":{"uri":"file:///a.go"},"position":{"line":2,"character":9}}}`) +
+		frame(`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`) +
+		frame(`{"jsonrpc":"2.0","method":"exit"}`)
+
+	var output bytes.Buffer
+	s := newServer(bufio.NewReader(bytes.NewBufferString(input)), &output)
+	err := s.serve()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, s.exitCode())
+
+	reader := bufio.NewReader(&output)
+	responses := []map[string]interface{}{}
+	for {
+		body, err := readMessage(reader)
+		if err != nil {
+			break
+		}
+		var resp map[string]interface{}
+		assert.NoError(t, json.Unmarshal(body, &resp))
+		responses = append(responses, resp)
+	}
+	assert.Len(t, responses, 3)
+
+	items := responses[1]["result"].([]interface{})
+	assert.Len(t, items, 2)
+	assert.Equal(t, "RestService", items[0].(map[string]interface{})["label"])
+
+	_, hasResult := responses[2]["result"]
+	assert.True(t, hasResult)
+}
@@ -21,6 +21,24 @@ type Envelope struct {
 	EventData      string
 }
 
+// EventMigrations upgrades an envelope holding an old version of an event, by the name of that old event
+var EventMigrations = map[string]func(envelope *Envelope) (*Envelope, error){}
+
+// UpgradeEnvelope migrates the event in the envelope to its most recent version
+func UpgradeEnvelope(envelope *Envelope) (*Envelope, error) {
+	for {
+		migrate, exists := EventMigrations[envelope.EventTypeName]
+		if !exists {
+			return envelope, nil
+		}
+		upgraded, err := migrate(envelope)
+		if err != nil {
+			return nil, err
+		}
+		envelope = upgraded
+	}
+}
+
 const (
 	TourCreatedEventName = "TourCreated"
 
@@ -16,6 +16,11 @@ import (
 func (ts *TourService) HttpHandler() http.Handler {
 	router := mux.NewRouter().StrictSlash(true)
 	subRouter := router.PathPrefix("/api/tour").Subrouter()
+	ts.registerRoutes(subRouter)
+	return router
+}
+
+func (ts *TourService) registerRoutes(subRouter *mux.Router) {
 
 	subRouter.HandleFunc("/{year}", getTourOnUid(ts)).Methods("GET")
 
@@ -27,7 +32,6 @@ func (ts *TourService) HttpHandler() http.Handler {
 
 	subRouter.HandleFunc("/{year}/cyclist/{cyclistUid}", markCyclistAbondoned(ts)).Methods("DELETE")
 
-	return router
 }
 
 func getTourOnUid(service *TourService) http.HandlerFunc {
@@ -267,13 +271,14 @@ func handleError(err error, w http.ResponseWriter) {
 	}{
 		err.Error(),
 	}
-	blob, err := json.Marshal(errorBody)
-	if err != nil {
-		log.Printf("Error marshalling error response payload %+v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	blob, marshalErr := json.Marshal(errorBody)
+	if marshalErr != nil {
+		log.Printf("Error marshalling error response payload %+v", marshalErr)
+		http.Error(w, marshalErr.Error(), http.StatusInternalServerError)
+		return
 	}
-	w.WriteHeader(determineHttpCode(err))
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(determineHttpCode(err))
 	w.Write(blob)
 }
 
@@ -0,0 +1,21 @@
+// Generated automatically: do not edit manually
+
+package web
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// NewTourServiceTestServer serves the generated http-handler of the service on a local port until the test has finished
+func NewTourServiceTestServer(t *testing.T, svc *TourService) *url.URL {
+	server := httptest.NewServer(svc.HttpHandler())
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Error parsing url of test-server: %s", err)
+	}
+	return serverURL
+}
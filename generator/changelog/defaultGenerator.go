@@ -0,0 +1,17 @@
+package changelog
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+// Generate compares the rest-operations with the snapshot that was last recorded and describes the differences in the
+// "Unreleased"-section of the changelog. With updateSnapshot, the differences become a dated section and the current
+// operations are recorded as the new snapshot.
+func Generate(inputDir string, structs []model.Struct, updateSnapshot bool) error {
+	return DefaultGenerator.Generate(inputDir, structs, updateSnapshot)
+}
+
+// TakeSnapshot records the method, full path and annotations of every rest-operation
+func TakeSnapshot(structs []model.Struct) Snapshot {
+	return DefaultGenerator.TakeSnapshot(structs)
+}
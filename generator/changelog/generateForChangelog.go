@@ -0,0 +1,300 @@
+package changelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/generator/rest"
+	"github.com/MarcGrol/golangAnnotations/generator/rest/restAnnotation"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+const (
+	snapshotFileName  = "apiSnapshot.json"
+	changelogFileName = "CHANGELOG.md"
+	changelogHeader   = "# API changelog\n"
+	unreleasedTitle   = "Unreleased"
+)
+
+// Snapshot records the rest-operations of a package, to compare later versions of the api with
+type Snapshot struct {
+	Operations []OperationSnapshot `json:"operations"`
+}
+
+type OperationSnapshot struct {
+	Service     string   `json:"service"`
+	Name        string   `json:"name"`
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Annotations []string `json:"annotations"`
+}
+
+func (o OperationSnapshot) key() string {
+	return o.Service + "." + o.Name
+}
+
+// Entry lists the differences between two snapshots
+type Entry struct {
+	Title   string
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+func (e Entry) IsEmpty() bool {
+	return len(e.Added) == 0 && len(e.Removed) == 0 && len(e.Changed) == 0
+}
+
+// Generator keeps the changelog of the rest-operations, which it recognizes by the annotations in its registry
+type Generator struct {
+	registry *annotation.Registry
+	rest     *rest.Generator
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry, rest: rest.NewGenerator(registry)}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+// Generate compares the rest-operations with the snapshot that was last recorded and describes the differences in the
+// "Unreleased"-section of the changelog. With updateSnapshot, the differences become a dated section and the current
+// operations are recorded as the new snapshot.
+func (g *Generator) Generate(inputDir string, structs []model.Struct, updateSnapshot bool) error {
+	restAnnotation.RegisterIn(g.registry)
+
+	packageName, err := generationUtil.GetPackageName(structs)
+	if err != nil {
+		return err
+	}
+
+	serviceCount := 0
+	for _, s := range structs {
+		if g.rest.IsRestService(s) {
+			serviceCount++
+		}
+	}
+	if serviceCount == 0 {
+		return nil
+	}
+
+	targetDir, err := generationUtil.DetermineTargetPath(inputDir, packageName)
+	if err != nil {
+		return err
+	}
+	snapshotFile := fmt.Sprintf("%s/%s", targetDir, snapshotFileName)
+	changelogFile := fmt.Sprintf("%s/%s", targetDir, changelogFileName)
+
+	current := g.TakeSnapshot(structs)
+	previous, found, err := readSnapshot(snapshotFile)
+	if err != nil {
+		return err
+	}
+	if !found {
+		// the changelog is only maintained once a snapshot has been recorded
+		if updateSnapshot {
+			return writeSnapshot(snapshotFile, current)
+		}
+		return nil
+	}
+
+	entry := Compare(previous, current)
+	entry.Title = unreleasedTitle
+	if updateSnapshot {
+		entry.Title = time.Now().Format("2006-01-02")
+	}
+	err = writeChangelog(changelogFile, entry)
+	if err != nil {
+		log.Fatalf("Error generating changelog: %s", err)
+		return err
+	}
+	if updateSnapshot {
+		return writeSnapshot(snapshotFile, current)
+	}
+	return nil
+}
+
+// TakeSnapshot records the method, full path and annotations of every rest-operation
+func (g *Generator) TakeSnapshot(structs []model.Struct) Snapshot {
+	snapshot := Snapshot{Operations: []OperationSnapshot{}}
+	for _, s := range structs {
+		if !g.rest.IsRestService(s) {
+			continue
+		}
+		for _, o := range s.Operations {
+			if !g.rest.IsRestOperation(*o) {
+				continue
+			}
+			annotations := []string{}
+			for _, a := range g.registry.ParseAnnotations(o.DocLines) {
+				annotations = append(annotations, a.Name)
+			}
+			sort.Strings(annotations)
+			snapshot.Operations = append(snapshot.Operations, OperationSnapshot{
+				Service:     s.Name,
+				Name:        o.Name,
+				Method:      g.rest.GetRestOperationMethod(*o),
+				Path:        g.rest.GetRestServicePrefix(s, structs) + g.rest.GetRestOperationPath(*o),
+				Annotations: annotations,
+			})
+		}
+	}
+	sort.Slice(snapshot.Operations, func(i, j int) bool {
+		return snapshot.Operations[i].key() < snapshot.Operations[j].key()
+	})
+	return snapshot
+}
+
+// Compare describes the operations that have been added, removed or changed since the previous snapshot
+func Compare(previous Snapshot, current Snapshot) Entry {
+	entry := Entry{}
+
+	previousOperations := map[string]OperationSnapshot{}
+	for _, o := range previous.Operations {
+		previousOperations[o.key()] = o
+	}
+	currentOperations := map[string]OperationSnapshot{}
+	for _, o := range current.Operations {
+		currentOperations[o.key()] = o
+	}
+
+	for _, o := range current.Operations {
+		before, existed := previousOperations[o.key()]
+		if !existed {
+			entry.Added = append(entry.Added, fmt.Sprintf("`%s %s` (%s)", o.Method, o.Path, o.key()))
+			continue
+		}
+		if before.Method != o.Method {
+			entry.Changed = append(entry.Changed, fmt.Sprintf("%s: method changed from %s to %s", o.key(), before.Method, o.Method))
+		}
+		if before.Path != o.Path {
+			entry.Changed = append(entry.Changed, fmt.Sprintf("%s: path changed from `%s` to `%s`", o.key(), before.Path, o.Path))
+		}
+		for _, name := range missingFrom(o.Annotations, before.Annotations) {
+			entry.Changed = append(entry.Changed, fmt.Sprintf("%s: annotation @%s added", o.key(), name))
+		}
+		for _, name := range missingFrom(before.Annotations, o.Annotations) {
+			entry.Changed = append(entry.Changed, fmt.Sprintf("%s: annotation @%s removed", o.key(), name))
+		}
+	}
+	for _, o := range previous.Operations {
+		if _, exists := currentOperations[o.key()]; !exists {
+			entry.Removed = append(entry.Removed, fmt.Sprintf("`%s %s` (%s)", o.Method, o.Path, o.key()))
+		}
+	}
+	return entry
+}
+
+// missingFrom returns the names that are not in others
+func missingFrom(names []string, others []string) []string {
+	missing := []string{}
+	for _, name := range names {
+		found := false
+		for _, other := range others {
+			if name == other {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func readSnapshot(fileName string) (Snapshot, bool, error) {
+	snapshot := Snapshot{}
+	data, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return snapshot, false, nil
+	}
+	if err != nil {
+		return snapshot, false, err
+	}
+	err = json.Unmarshal(data, &snapshot)
+	if err != nil {
+		return snapshot, false, fmt.Errorf("Error reading api-snapshot %s:%s", fileName, err)
+	}
+	return snapshot, true, nil
+}
+
+func writeSnapshot(fileName string, snapshot Snapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(fileName), 0777)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, append(data, '\n'), 0644)
+}
+
+// writeChangelog replaces the "Unreleased"-section of the changelog with the entry, keeping all older sections
+func writeChangelog(fileName string, entry Entry) error {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	olderSections := removeSection(strings.TrimPrefix(string(data), changelogHeader), unreleasedTitle)
+
+	section := ""
+	if !entry.IsEmpty() {
+		t, err := template.New("changelog").Parse(changelogTemplate)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		err = t.Execute(&buf, entry)
+		if err != nil {
+			return err
+		}
+		section = buf.String()
+	}
+	if section == "" && olderSections == "" && data == nil {
+		return nil
+	}
+	return ioutil.WriteFile(fileName, []byte(changelogHeader+section+olderSections), 0644)
+}
+
+// removeSection removes the section with the given title, up to the next section
+func removeSection(sections string, title string) string {
+	start := strings.Index(sections, "\n## "+title+"\n")
+	if start < 0 {
+		return sections
+	}
+	end := strings.Index(sections[start+1:], "\n## ")
+	if end < 0 {
+		return sections[:start]
+	}
+	return sections[:start] + sections[start+1+end:]
+}
+
+var changelogTemplate string = `
+## {{.Title}}
+{{if .Added}}
+### Added
+{{range .Added}}
+- {{.}}{{end}}
+{{end}}{{if .Removed}}
+### Removed
+{{range .Removed}}
+- {{.}}{{end}}
+{{end}}{{if .Changed}}
+### Changed
+{{range .Changed}}
+- {{.}}{{end}}
+{{end}}`
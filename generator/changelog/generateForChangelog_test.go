@@ -0,0 +1,107 @@
+package changelog
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"io/ioutil"
+
+	"github.com/MarcGrol/golangAnnotations/generator/rest/restAnnotation"
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func service(operations ...*model.Operation) []model.Struct {
+	return []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @RestService( path = "/api" )`},
+			Name:        "MyService",
+			Operations:  operations,
+		},
+	}
+}
+
+func operation(name string, docLines ...string) *model.Operation {
+	return &model.Operation{
+		DocLines:      docLines,
+		Name:          name,
+		RelatedStruct: &model.Field{TypeName: "MyService"},
+		OutputArgs:    []model.Field{{TypeName: "error"}},
+	}
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	restAnnotation.Register()
+
+	previous := TakeSnapshot(service(
+		operation("getPerson", `// @RestOperation( method = "GET", path = "/person/{id}" )`),
+		operation("deletePerson", `// @RestOperation( method = "DELETE", path = "/person/{id}" )`),
+	))
+	current := TakeSnapshot(service(
+		operation("getPerson", `// @Cacheable( maxAge = 60 )`, `// @RestOperation( method = "GET", path = "/person/{uid}" )`),
+		operation("createPerson", `// @RestOperation( method = "POST", path = "/person" )`),
+	))
+
+	entry := Compare(previous, current)
+	assert.Equal(t, []string{"`POST /api/person` (MyService.createPerson)"}, entry.Added)
+	assert.Equal(t, []string{"`DELETE /api/person/{id}` (MyService.deletePerson)"}, entry.Removed)
+	assert.Equal(t, []string{
+		"MyService.getPerson: path changed from `/api/person/{id}` to `/api/person/{uid}`",
+		"MyService.getPerson: annotation @Cacheable added",
+	}, entry.Changed)
+
+	assert.True(t, Compare(current, current).IsEmpty())
+}
+
+func TestGenerateChangelog(t *testing.T) {
+	os.Remove("./testData/apiSnapshot.json")
+	os.Remove("./testData/CHANGELOG.md")
+
+	// without snapshot, there is nothing to compare with
+	err := Generate("testData", service(operation("getPerson", `// @RestOperation( method = "GET", path = "/person/{id}" )`)), false)
+	assert.Nil(t, err)
+	_, err = os.Stat("./testData/apiSnapshot.json")
+	assert.True(t, os.IsNotExist(err))
+
+	err = Generate("testData", service(operation("getPerson", `// @RestOperation( method = "GET", path = "/person/{id}" )`)), true)
+	assert.Nil(t, err)
+	_, err = os.Stat("./testData/apiSnapshot.json")
+	assert.NoError(t, err)
+	_, err = os.Stat("./testData/CHANGELOG.md")
+	assert.True(t, os.IsNotExist(err))
+
+	// the unreleased section is replaced on every run
+	changed := service(
+		operation("getPerson", `// @RestOperation( method = "GET", path = "/person/{id}" )`),
+		operation("createPerson", `// @RestOperation( method = "POST", path = "/person" )`),
+	)
+	for i := 0; i < 2; i++ {
+		err = Generate("testData", changed, false)
+		assert.Nil(t, err)
+	}
+	data, err := ioutil.ReadFile("./testData/CHANGELOG.md")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "## Unreleased"))
+	assert.Contains(t, string(data), "### Added\n\n- `POST /api/person` (MyService.createPerson)\n")
+
+	// updating the snapshot turns the unreleased section into a dated one
+	err = Generate("testData", changed, true)
+	assert.Nil(t, err)
+	data, err = ioutil.ReadFile("./testData/CHANGELOG.md")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "## Unreleased")
+	assert.Contains(t, string(data), "## "+time.Now().Format("2006-01-02")+"\n")
+
+	err = Generate("testData", changed, false)
+	assert.Nil(t, err)
+	data, err = ioutil.ReadFile("./testData/CHANGELOG.md")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "## Unreleased")
+	assert.Equal(t, 1, strings.Count(string(data), "(MyService.createPerson)"))
+
+	os.Remove("./testData/apiSnapshot.json")
+	os.Remove("./testData/CHANGELOG.md")
+}
@@ -0,0 +1,26 @@
+package dbdocAnnotation
+
+import "github.com/MarcGrol/golangAnnotations/annotation"
+
+const (
+	typeEntity = "Entity"
+	paramTable = "table"
+)
+
+// Register makes the annotation-registry aware of this annotation
+func Register() {
+	RegisterIn(annotation.DefaultRegistry)
+}
+
+// RegisterIn makes the given registry aware of this annotation
+func RegisterIn(registry *annotation.Registry) {
+	registry.Register(typeEntity, []string{paramTable}, validateEntityAnnotation)
+}
+
+func validateEntityAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeEntity {
+		table, hasTable := annot.Attributes[paramTable]
+		return hasTable && table != ""
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package dbdocAnnotation
+
+import (
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectEntityAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	annot, ok := annotation.ResolveAnnotations([]string{`// @Entity( table = "users" )`})
+	assert.True(t, ok)
+	assert.Equal(t, "users", annot.Attributes["table"])
+}
+
+func TestIncompleteEntityAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @Entity()`})
+	assert.False(t, ok)
+}
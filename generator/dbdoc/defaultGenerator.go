@@ -0,0 +1,17 @@
+package dbdoc
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+func Generate(inputDir string, structs []model.Struct) error {
+	return DefaultGenerator.Generate(inputDir, structs)
+}
+
+func IsEntity(s model.Struct) bool {
+	return DefaultGenerator.IsEntity(s)
+}
+
+func GetTableName(s model.Struct) string {
+	return DefaultGenerator.GetTableName(s)
+}
@@ -0,0 +1,191 @@
+package dbdoc
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/dbdoc/dbdocAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+// Column describes how a field of an entity is stored
+type Column struct {
+	Name        string
+	Type        string
+	Constraints string
+}
+
+// Relationship describes a field of an entity that refers to another entity
+type Relationship struct {
+	FromTable   string
+	Column      string
+	ToTable     string
+	Cardinality string
+}
+
+type Entity struct {
+	Name    string
+	Table   string
+	Columns []Column
+}
+
+type Schema struct {
+	PackageName   string
+	Entities      []Entity
+	Relationships []Relationship
+}
+
+// Generator documents the database-schema of the structs annotated with @Entity in its registry
+type Generator struct {
+	registry *annotation.Registry
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+func (g *Generator) Generate(inputDir string, structs []model.Struct) error {
+	dbdocAnnotation.RegisterIn(g.registry)
+
+	packageName, err := generationUtil.GetPackageName(structs)
+	if err != nil {
+		return err
+	}
+
+	tables := make(map[string]string)
+	for _, s := range structs {
+		if g.IsEntity(s) {
+			tables[s.Name] = g.GetTableName(s)
+		}
+	}
+
+	if len(tables) > 0 {
+		targetDir, err := generationUtil.DetermineTargetPath(inputDir, packageName)
+		if err != nil {
+			return err
+		}
+		target := fmt.Sprintf("%s/entities.md", targetDir)
+
+		data := g.buildSchema(packageName, structs, tables)
+		err = generationUtil.GenerateFileFromTemplate(data, "dbdoc", dbdocTemplate, customTemplateFuncs, target)
+		if err != nil {
+			log.Fatalf("Error generating database documentation for entities (%s)", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// buildSchema describes every field of an entity as a column:
+// fields of which the type is another entity become relationships as well
+func (g *Generator) buildSchema(packageName string, structs []model.Struct, tables map[string]string) Schema {
+	schema := Schema{PackageName: packageName}
+	for _, s := range structs {
+		if !g.IsEntity(s) {
+			continue
+		}
+		entity := Entity{Name: s.Name, Table: tables[s.Name]}
+		for _, f := range s.Fields {
+			name, constraints, stored := getColumn(f)
+			if !stored {
+				continue
+			}
+			entity.Columns = append(entity.Columns, Column{
+				Name:        name,
+				Type:        getColumnType(f),
+				Constraints: constraints,
+			})
+
+			toTable, isEntity := tables[f.TypeName]
+			if isEntity && f.PackageQualifier == "" {
+				cardinality := "one"
+				if f.IsSlice {
+					cardinality = "many"
+				}
+				schema.Relationships = append(schema.Relationships, Relationship{
+					FromTable:   entity.Table,
+					Column:      name,
+					ToTable:     toTable,
+					Cardinality: cardinality,
+				})
+			}
+		}
+		schema.Entities = append(schema.Entities, entity)
+	}
+	return schema
+}
+
+var customTemplateFuncs = template.FuncMap{}
+
+func (g *Generator) IsEntity(s model.Struct) bool {
+	_, ok := g.registry.ResolveAnnotationByName(s.DocLines, "Entity")
+	return ok
+}
+
+func (g *Generator) GetTableName(s model.Struct) string {
+	val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "Entity")
+	if ok {
+		return val.Attributes["table"]
+	}
+	return ""
+}
+
+// getColumn reads the column-name and constraints from the db-tag of the field, like `db:"email,unique,not null"`:
+// fields tagged with `db:"-"` are not stored
+func getColumn(f model.Field) (string, string, bool) {
+	parts := strings.Split(f.TagGet("db"), ",")
+	name := strings.TrimSpace(parts[0])
+	if name == "-" {
+		return "", "", false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	constraints := []string{}
+	for _, c := range parts[1:] {
+		if strings.TrimSpace(c) != "" {
+			constraints = append(constraints, strings.TrimSpace(c))
+		}
+	}
+	return name, strings.Join(constraints, ", "), true
+}
+
+func getColumnType(f model.Field) string {
+	typeName := f.TypeName
+	if f.PackageQualifier != "" {
+		typeName = fmt.Sprintf("%s.%s", f.PackageQualifier, typeName)
+	}
+	if f.IsPointer {
+		typeName = "*" + typeName
+	}
+	if f.IsSlice {
+		typeName = "[]" + typeName
+	}
+	return typeName
+}
+
+var dbdocTemplate string = `<!-- Generated automatically: do not edit manually -->
+
+# Entities of package {{.PackageName}}
+{{range .Entities}}
+## {{.Table}}
+
+Stores ` + "`{{.Name}}`" + `
+
+| Column | Type | Constraints |
+|--------|------|-------------|
+{{range .Columns}}| {{.Name}} | {{.Type}} | {{.Constraints}} |
+{{end}}{{end}}{{if .Relationships}}
+## Relationships
+
+| From | Column | To | Cardinality |
+|------|--------|----|-------------|
+{{range .Relationships}}| {{.FromTable}} | {{.Column}} | {{.ToTable}} | {{.Cardinality}} |
+{{end}}{{end}}`
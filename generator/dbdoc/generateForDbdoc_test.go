@@ -0,0 +1,76 @@
+package dbdoc
+
+import (
+	"os"
+	"testing"
+
+	"io/ioutil"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateForDbdoc(t *testing.T) {
+	os.Remove("./testData/entities.md")
+
+	s := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @Entity( table = "users" )`},
+			Name:        "User",
+			Fields: []model.Field{
+				{Name: "ID", TypeName: "string", Tag: "`db:\"id,primary key\"`"},
+				{Name: "Email", TypeName: "string", Tag: "`db:\"email,unique,not null\"`"},
+				{Name: "Created", TypeName: "Time", PackageQualifier: "time"},
+				{Name: "Profile", TypeName: "Profile", IsPointer: true, Tag: "`db:\"profile_id\"`"},
+				{Name: "Orders", TypeName: "Order", IsSlice: true},
+				{Name: "Cache", TypeName: "string", Tag: "`db:\"-\"`"},
+			},
+		},
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @Entity( table = "profiles" )`},
+			Name:        "Profile",
+		},
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @Entity( table = "orders" )`},
+			Name:        "Order",
+		},
+		{
+			PackageName: "testData",
+			Name:        "NotStored",
+		},
+	}
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/entities.md")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "## users")
+	assert.Contains(t, string(data), "| id | string | primary key |")
+	assert.Contains(t, string(data), "| email | string | unique, not null |")
+	assert.Contains(t, string(data), "| Created | time.Time |  |")
+	assert.Contains(t, string(data), "| users | profile_id | profiles | one |")
+	assert.Contains(t, string(data), "| users | Orders | orders | many |")
+	assert.NotContains(t, string(data), "Cache")
+	assert.NotContains(t, string(data), "NotStored")
+
+	os.Remove("./testData/entities.md")
+}
+
+func TestGenerateForDbdocWithoutEntities(t *testing.T) {
+	os.Remove("./testData/entities.md")
+
+	s := []model.Struct{
+		{
+			PackageName: "testData",
+			Name:        "NotStored",
+		},
+	}
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	_, err = os.Stat("./testData/entities.md")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,17 @@
+package event
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+func Generate(inputDir string, structs []model.Struct) error {
+	return DefaultGenerator.Generate(inputDir, structs)
+}
+
+func IsEvent(s model.Struct) bool {
+	return DefaultGenerator.IsEvent(s)
+}
+
+func GetAggregateName(s model.Struct) string {
+	return DefaultGenerator.GetAggregateName(s)
+}
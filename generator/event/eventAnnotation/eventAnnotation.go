@@ -1,15 +1,28 @@
 package eventAnnotation
 
-import "github.com/MarcGrol/golangAnnotations/annotation"
+import (
+	"strconv"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+)
 
 const (
-	typeEvent      = "Event"
-	paramAggregate = "aggregate"
+	typeEvent         = "Event"
+	typeEventVersion  = "EventVersion"
+	paramAggregate    = "aggregate"
+	paramVersion      = "version"
+	paramMigratedFrom = "migratedfrom"
 )
 
-// Register makes the annotation-registry aware of this annotation
+// Register makes the annotation-registry aware of these annotations
 func Register() {
-	annotation.RegisterAnnotation(typeEvent, []string{paramAggregate}, validateEventAnnotation)
+	RegisterIn(annotation.DefaultRegistry)
+}
+
+// RegisterIn makes the given registry aware of these annotations
+func RegisterIn(registry *annotation.Registry) {
+	registry.Register(typeEvent, []string{paramAggregate}, validateEventAnnotation)
+	registry.Register(typeEventVersion, []string{paramVersion, paramMigratedFrom}, validateEventVersionAnnotation)
 }
 
 func validateEventAnnotation(annot annotation.Annotation) bool {
@@ -19,3 +32,20 @@ func validateEventAnnotation(annot annotation.Annotation) bool {
 	}
 	return false
 }
+
+func validateEventVersionAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeEventVersion {
+		version, err := strconv.Atoi(annot.Attributes[paramVersion])
+		if err != nil || version < 1 {
+			return false
+		}
+		migratedFrom, hasMigratedFrom := annot.Attributes[paramMigratedFrom]
+		if !hasMigratedFrom {
+			return true
+		}
+		// an event can only be migrated from an older version
+		from, err := strconv.Atoi(migratedFrom)
+		return err == nil && from >= 1 && from < version
+	}
+	return false
+}
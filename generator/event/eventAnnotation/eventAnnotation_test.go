@@ -31,3 +31,30 @@ func TestEmptyEventAnnotation(t *testing.T) {
 	_, ok := annotation.ResolveAnnotations([]string{`// @Event( aggregate = "")`})
 	assert.False(t, ok)
 }
+
+func TestCorrectEventVersionAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	annot, ok := annotation.ResolveAnnotations([]string{`// @EventVersion( version = 2, migratedFrom = 1 )`})
+	assert.True(t, ok)
+	assert.Equal(t, "2", annot.Attributes["version"])
+	assert.Equal(t, "1", annot.Attributes["migratedfrom"])
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @EventVersion( version = 1 )`})
+	assert.True(t, ok)
+}
+
+func TestInvalidEventVersionAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @EventVersion( migratedFrom = 1 )`})
+	assert.False(t, ok)
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @EventVersion( version = 2, migratedFrom = 2 )`})
+	assert.False(t, ok)
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @EventVersion( version = "two" )`})
+	assert.False(t, ok)
+}
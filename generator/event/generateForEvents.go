@@ -2,9 +2,9 @@ package event
 
 import (
 	"fmt"
-	"html/template"
 	"log"
 	"strings"
+	"text/template"
 
 	"github.com/MarcGrol/golangAnnotations/annotation"
 	"github.com/MarcGrol/golangAnnotations/generator/event/eventAnnotation"
@@ -22,8 +22,34 @@ type Structs struct {
 	Structs     []model.Struct
 }
 
-func Generate(inputDir string, structs []model.Struct) error {
-	eventAnnotation.Register()
+// Migration describes how an event is upgraded from an old version to a newer one
+type Migration struct {
+	FunctionName string
+	OldEvent     string
+	NewEvent     string
+	FromVersion  string
+	ToVersion    string
+}
+
+type Migrations struct {
+	PackageName string
+	Migrations  []Migration
+}
+
+// Generator generates envelopes and aggregates for the structs annotated with @Event in its registry
+type Generator struct {
+	registry *annotation.Registry
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+func (g *Generator) Generate(inputDir string, structs []model.Struct) error {
+	eventAnnotation.RegisterIn(g.registry)
 
 	packageName, err := generationUtil.GetPackageName(structs)
 	if err != nil {
@@ -32,13 +58,13 @@ func Generate(inputDir string, structs []model.Struct) error {
 	aggregates := make(map[string]map[string]string)
 	eventCount := 0
 	for _, s := range structs {
-		if IsEvent(s) {
-			events, ok := aggregates[GetAggregateName(s)]
+		if g.IsEvent(s) {
+			events, ok := aggregates[g.GetAggregateName(s)]
 			if !ok {
 				events = make(map[string]string)
 			}
 			events[s.Name] = s.Name
-			aggregates[GetAggregateName(s)] = events
+			aggregates[g.GetAggregateName(s)] = events
 			eventCount++
 		}
 	}
@@ -56,7 +82,7 @@ func Generate(inputDir string, structs []model.Struct) error {
 				AggregateMap: aggregates,
 			}
 
-			err = generationUtil.GenerateFileFromTemplate(data, "aggregates", aggregateTemplate, customTemplateFuncs, target)
+			err = generationUtil.GenerateFileFromTemplate(data, "aggregates", aggregateTemplate, g.templateFuncs(), target)
 			if err != nil {
 				log.Fatalf("Error generating aggregates (%s)", err)
 				return err
@@ -69,32 +95,124 @@ func Generate(inputDir string, structs []model.Struct) error {
 				PackageName: packageName,
 				Structs:     structs,
 			}
-			err = generationUtil.GenerateFileFromTemplate(data, "wrappers", wrappersTemplate, customTemplateFuncs, target)
+			err = generationUtil.GenerateFileFromTemplate(data, "wrappers", wrappersTemplate, g.templateFuncs(), target)
 			if err != nil {
 				log.Fatalf("Error generating wrappers for structs (%s)", err)
 				return err
 			}
 		}
+		err = g.generateMigrations(targetDir, packageName, structs)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-var customTemplateFuncs = template.FuncMap{
-	"IsEvent":          IsEvent,
-	"GetAggregateName": GetAggregateName,
-	"ToFirstUpper":     ToFirstUpper,
+func (g *Generator) generateMigrations(targetDir string, packageName string, structs []model.Struct) error {
+	migrations, err := g.collectMigrations(structs)
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+	{
+		target := fmt.Sprintf("%s/eventMigrations.go", targetDir)
+
+		data := Migrations{
+			PackageName: packageName,
+			Migrations:  migrations,
+		}
+		err = generationUtil.GenerateFileFromTemplate(data, "migrations", migrationsTemplate, g.templateFuncs(), target)
+		if err != nil {
+			log.Fatalf("Error generating event migrations (%s)", err)
+			return err
+		}
+	}
+	for _, m := range migrations {
+		// the stub is filled in by the user: never overwrite it once it has been edited
+		target := fmt.Sprintf("%s/%s.go", targetDir, ToFirstLower(m.FunctionName))
+		if generationUtil.IsEditedFile(target) {
+			log.Printf("Keeping %s: it has been edited", target)
+			continue
+		}
+
+		data := struct {
+			PackageName string
+			Migration
+		}{
+			PackageName: packageName,
+			Migration:   m,
+		}
+		err = generationUtil.GenerateFileFromTemplate(data, "migrationStub", migrationStubTemplate, g.templateFuncs(), target)
+		if err != nil {
+			log.Fatalf("Error generating stub for event migration %s (%s)", m.FunctionName, err)
+			return err
+		}
+	}
+	return nil
 }
 
-func IsEvent(s model.Struct) bool {
-	annotation, ok := annotation.ResolveAnnotations(s.DocLines)
-	if !ok || annotation.Name != "Event" {
-		return false
+// collectMigrations finds the versioned events that are migrated from an older version:
+// versions of an event share a name that ends with the version, like TourCreatedV1 and TourCreatedV2
+func (g *Generator) collectMigrations(structs []model.Struct) ([]Migration, error) {
+	events := make(map[string]bool)
+	for _, s := range structs {
+		if g.IsEvent(s) {
+			events[s.Name] = true
+		}
+	}
+
+	migrations := []Migration{}
+	for _, s := range structs {
+		val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "EventVersion")
+		if !ok {
+			continue
+		}
+		if !g.IsEvent(s) {
+			return nil, fmt.Errorf("Struct %s has an @EventVersion but is not an @Event", s.Name)
+		}
+		fromVersion := val.Attributes["migratedfrom"]
+		if fromVersion == "" {
+			continue
+		}
+		toVersion := val.Attributes["version"]
+		suffix := "V" + toVersion
+		if !strings.HasSuffix(s.Name, suffix) {
+			return nil, fmt.Errorf("Event %s has version %s, so its name must end with %s", s.Name, toVersion, suffix)
+		}
+		baseName := strings.TrimSuffix(s.Name, suffix)
+		oldEvent := baseName + "V" + fromVersion
+		if !events[oldEvent] {
+			return nil, fmt.Errorf("Event %s is migrated from %s, which is not an @Event", s.Name, oldEvent)
+		}
+		migrations = append(migrations, Migration{
+			FunctionName: fmt.Sprintf("Migrate%sV%sToV%s", baseName, fromVersion, toVersion),
+			OldEvent:     oldEvent,
+			NewEvent:     s.Name,
+			FromVersion:  fromVersion,
+			ToVersion:    toVersion,
+		})
 	}
+	return migrations, nil
+}
+
+func (g *Generator) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"IsEvent":          g.IsEvent,
+		"GetAggregateName": g.GetAggregateName,
+		"ToFirstUpper":     ToFirstUpper,
+	}
+}
+
+func (g *Generator) IsEvent(s model.Struct) bool {
+	_, ok := g.registry.ResolveAnnotationByName(s.DocLines, "Event")
 	return ok
 }
 
-func GetAggregateName(s model.Struct) string {
-	val, ok := annotation.ResolveAnnotations(s.DocLines)
+func (g *Generator) GetAggregateName(s model.Struct) string {
+	val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "Event")
 	if ok {
 		return val.Attributes["aggregate"]
 	}
@@ -108,6 +226,13 @@ func ToFirstUpper(in string) string {
 	return strings.ToUpper(fmt.Sprintf("%c", in[0])) + in[1:]
 }
 
+func ToFirstLower(in string) string {
+	if len(in) == 0 {
+		return in
+	}
+	return strings.ToLower(fmt.Sprintf("%c", in[0])) + in[1:]
+}
+
 var aggregateTemplate string = `
 // Generated automatically: do not edit manually
 
@@ -193,6 +318,24 @@ type Envelope struct {
 	EventData      string
 }
 
+// EventMigrations upgrades an envelope holding an old version of an event, by the name of that old event
+var EventMigrations = map[string]func(envelope *Envelope) (*Envelope, error){}
+
+// UpgradeEnvelope migrates the event in the envelope to its most recent version
+func UpgradeEnvelope(envelope *Envelope) (*Envelope, error) {
+	for {
+		migrate, exists := EventMigrations[envelope.EventTypeName]
+		if !exists {
+			return envelope, nil
+		}
+		upgraded, err := migrate(envelope)
+		if err != nil {
+			return nil, err
+		}
+		envelope = upgraded
+	}
+}
+
 const (
 {{range .Structs}}
 {{if IsEvent . }}
@@ -253,3 +396,45 @@ func UnWrap{{.Name}}(envelop *Envelope) (*{{.Name}},error) {
 {{end}}
 {{end}}
 `
+
+var migrationsTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+func init() {
+{{range .Migrations}}
+	EventMigrations[{{.OldEvent}}EventName] = func(envelope *Envelope) (*Envelope, error) {
+		old, err := UnWrap{{.OldEvent}}(envelope)
+		if err != nil {
+			return nil, err
+		}
+		migrated, err := {{.FunctionName}}(old)
+		if err != nil {
+			return nil, err
+		}
+		upgraded, err := migrated.Wrap(envelope.AggregateUid)
+		if err != nil {
+			return nil, err
+		}
+		// the migrated event keeps the identity and position of the original
+		upgraded.Uuid = envelope.Uuid
+		upgraded.SequenceNumber = envelope.SequenceNumber
+		upgraded.Timestamp = envelope.Timestamp
+		return upgraded, nil
+	}
+{{end}}
+}
+`
+
+var migrationStubTemplate string = `// Code generated as a starting point: remove this line when implementing the migration, to prevent it from being overwritten
+
+package {{.PackageName}}
+
+import "fmt"
+
+// {{.FunctionName}} converts version {{.FromVersion}} of the event into version {{.ToVersion}}
+func {{.FunctionName}}(old *{{.OldEvent}}) (*{{.NewEvent}}, error) {
+	return nil, fmt.Errorf("{{.FunctionName}} is not implemented")
+}
+`
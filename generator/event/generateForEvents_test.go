@@ -55,3 +55,75 @@ func TestGenerateForEvents(t *testing.T) {
 	os.Remove("./testData/wrappers.go")
 
 }
+
+func TestGenerateForEventsWithMigration(t *testing.T) {
+	os.Remove("./testData/aggregates.go")
+	os.Remove("./testData/wrappers.go")
+	os.Remove("./testData/eventMigrations.go")
+	os.Remove("./testData/migrateTourCreatedV1ToV2.go")
+
+	s := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @Event(aggregate = "Tour")`},
+			Name:        "TourCreatedV1",
+		},
+		{
+			PackageName: "testData",
+			DocLines: []string{
+				`// @Event(aggregate = "Tour")`,
+				`// @EventVersion(version = 2, migratedFrom = 1)`,
+			},
+			Name: "TourCreatedV2",
+		},
+	}
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/wrappers.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "func UpgradeEnvelope(envelope *Envelope) (*Envelope, error) {")
+	assert.Contains(t, string(data), "func (s *TourCreatedV2) Wrap(uid string) (*Envelope,error) {")
+
+	data, err = ioutil.ReadFile("./testData/eventMigrations.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "EventMigrations[TourCreatedV1EventName] = func(envelope *Envelope) (*Envelope, error) {")
+	assert.Contains(t, string(data), "migrated, err := MigrateTourCreatedV1ToV2(old)")
+
+	data, err = ioutil.ReadFile("./testData/migrateTourCreatedV1ToV2.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "func MigrateTourCreatedV1ToV2(old *TourCreatedV1) (*TourCreatedV2, error) {")
+
+	// an edited stub must survive regeneration
+	edited := "package testData\n\n// implemented by hand\n"
+	err = ioutil.WriteFile("./testData/migrateTourCreatedV1ToV2.go", []byte(edited), 0644)
+	assert.NoError(t, err)
+	err = Generate("testData", s)
+	assert.Nil(t, err)
+	data, err = ioutil.ReadFile("./testData/migrateTourCreatedV1ToV2.go")
+	assert.NoError(t, err)
+	assert.Equal(t, edited, string(data))
+
+	os.Remove("./testData/aggregates.go")
+	os.Remove("./testData/wrappers.go")
+	os.Remove("./testData/eventMigrations.go")
+	os.Remove("./testData/migrateTourCreatedV1ToV2.go")
+}
+
+func TestGenerateForEventsWithMigrationFromUnknownEvent(t *testing.T) {
+	s := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines: []string{
+				`// @Event(aggregate = "Tour")`,
+				`// @EventVersion(version = 3, migratedFrom = 2)`,
+			},
+			Name: "TourCreatedV3",
+		},
+	}
+	err := Generate("testData", s)
+	assert.Error(t, err)
+
+	os.Remove("./testData/aggregates.go")
+	os.Remove("./testData/wrappers.go")
+}
@@ -0,0 +1,18 @@
+package eventstore
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+func Generate(inputDir string, structs []model.Struct) error {
+	return DefaultGenerator.Generate(inputDir, structs)
+}
+
+func IsEventStore(s model.Struct) bool {
+	return DefaultGenerator.IsEventStore(s)
+}
+
+// GetBackend returns the database the store is generated for: defaults to postgres
+func GetBackend(s model.Struct) string {
+	return DefaultGenerator.GetBackend(s)
+}
@@ -0,0 +1,26 @@
+package eventstoreAnnotation
+
+import "github.com/MarcGrol/golangAnnotations/annotation"
+
+const (
+	typeEventStore = "EventStore"
+	paramBackend   = "backend"
+)
+
+// Register makes the annotation-registry aware of this annotation
+func Register() {
+	RegisterIn(annotation.DefaultRegistry)
+}
+
+// RegisterIn makes the given registry aware of this annotation
+func RegisterIn(registry *annotation.Registry) {
+	registry.Register(typeEventStore, []string{paramBackend}, validateEventStoreAnnotation)
+}
+
+func validateEventStoreAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeEventStore {
+		backend := annot.Attributes[paramBackend]
+		return backend == "" || backend == "postgres"
+	}
+	return false
+}
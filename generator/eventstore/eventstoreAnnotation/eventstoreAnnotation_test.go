@@ -0,0 +1,26 @@
+package eventstoreAnnotation
+
+import (
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectEventStoreAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	annot, ok := annotation.ResolveAnnotations([]string{`// @EventStore( backend = "postgres" )`})
+	assert.True(t, ok)
+	assert.Equal(t, "EventStore", annot.Name)
+	assert.Equal(t, "postgres", annot.Attributes["backend"])
+}
+
+func TestUnsupportedBackendEventStoreAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @EventStore( backend = "mongo" )`})
+	assert.False(t, ok)
+}
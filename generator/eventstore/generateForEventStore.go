@@ -0,0 +1,260 @@
+package eventstore
+
+import (
+	"fmt"
+	"log"
+	"text/template"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/event"
+	"github.com/MarcGrol/golangAnnotations/generator/event/eventAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/eventstore/eventstoreAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+type EventStoreData struct {
+	PackageName string
+	Backend     string
+}
+
+// Generator generates a postgres event-store for structs annotated with @EventStore, looking the annotations up
+// in its registry
+type Generator struct {
+	registry *annotation.Registry
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+func (g *Generator) Generate(inputDir string, structs []model.Struct) error {
+	eventstoreAnnotation.RegisterIn(g.registry)
+	eventAnnotation.RegisterIn(g.registry)
+
+	packageName, err := generationUtil.GetPackageName(structs)
+	if err != nil {
+		return err
+	}
+
+	storeCount := 0
+	eventCount := 0
+	backend := ""
+	for _, s := range structs {
+		if g.IsEventStore(s) {
+			storeCount++
+			backend = g.GetBackend(s)
+		}
+		if event.IsEvent(s) {
+			eventCount++
+		}
+	}
+
+	if storeCount > 1 {
+		return fmt.Errorf("Package %s has %d @EventStore annotations: only one is supported", packageName, storeCount)
+	}
+	if storeCount > 0 {
+		if eventCount == 0 {
+			// the generated store persists the envelopes that are generated for events
+			return fmt.Errorf("Package %s has an @EventStore but no @Event", packageName)
+		}
+		targetDir, err := generationUtil.DetermineTargetPath(inputDir, packageName)
+		if err != nil {
+			return err
+		}
+		target := fmt.Sprintf("%s/eventStore.go", targetDir)
+
+		data := EventStoreData{
+			PackageName: packageName,
+			Backend:     backend,
+		}
+		err = generationUtil.GenerateFileFromTemplate(data, "eventStore", postgresTemplate, customTemplateFuncs, target)
+		if err != nil {
+			log.Fatalf("Error generating event-store (%s)", err)
+			return err
+		}
+	}
+	return nil
+}
+
+var customTemplateFuncs = template.FuncMap{}
+
+func (g *Generator) IsEventStore(s model.Struct) bool {
+	_, ok := g.registry.ResolveAnnotationByName(s.DocLines, "EventStore")
+	return ok
+}
+
+// GetBackend returns the database the store is generated for: defaults to postgres
+func (g *Generator) GetBackend(s model.Struct) string {
+	val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "EventStore")
+	if ok && val.Attributes["backend"] != "" {
+		return val.Attributes["backend"]
+	}
+	return "postgres"
+}
+
+var postgresTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// EventStoreSchema creates the single table in which the events of all aggregates are stored
+const EventStoreSchema = "CREATE TABLE IF NOT EXISTS events (" +
+	"aggregate_id TEXT NOT NULL, " +
+	"version INTEGER NOT NULL, " +
+	"event_type TEXT NOT NULL, " +
+	"event_data TEXT NOT NULL, " +
+	"PRIMARY KEY (aggregate_id, version))"
+
+// ConcurrencyError is returned when the aggregate was modified since it was loaded
+type ConcurrencyError struct {
+	AggregateID     string
+	ExpectedVersion int
+	ActualVersion   int
+}
+
+func (e *ConcurrencyError) Error() string {
+	return fmt.Sprintf("Aggregate %s has version %d instead of expected version %d",
+		e.AggregateID, e.ActualVersion, e.ExpectedVersion)
+}
+
+// EventStore stores envelopes in {{.Backend}}, and notifies subscribers within this process of appended events
+type EventStore struct {
+	db          *sql.DB
+	mutex       sync.RWMutex
+	lastID      int
+	subscribers map[string]map[int]func(Envelope)
+}
+
+func NewEventStore(db *sql.DB) *EventStore {
+	return &EventStore{
+		db:          db,
+		subscribers: make(map[string]map[int]func(Envelope)),
+	}
+}
+
+// CreateSchema creates the events-table when it does not exist yet
+func (es *EventStore) CreateSchema(ctx context.Context) error {
+	_, err := es.db.ExecContext(ctx, EventStoreSchema)
+	return err
+}
+
+// Append stores the events when the aggregate still has the expected version; it returns a *ConcurrencyError otherwise
+func (es *EventStore) Append(ctx context.Context, aggregateID string, events []Envelope, expectedVersion int) error {
+	tx, err := es.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var actualVersion int
+	err = tx.QueryRowContext(ctx,
+		"SELECT COALESCE(MAX(version), 0) FROM events WHERE aggregate_id = $1", aggregateID).Scan(&actualVersion)
+	if err != nil {
+		return err
+	}
+	if actualVersion != expectedVersion {
+		return &ConcurrencyError{AggregateID: aggregateID, ExpectedVersion: expectedVersion, ActualVersion: actualVersion}
+	}
+
+	appended := []Envelope{}
+	for idx, envelope := range events {
+		version := expectedVersion + idx + 1
+		// the primary-key protects against concurrent transactions that passed the version-check as well
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO events (aggregate_id, version, event_type, event_data) VALUES ($1, $2, $3, $4)",
+			aggregateID, version, envelope.EventTypeName, envelope.EventData)
+		if err != nil {
+			return err
+		}
+		envelope.AggregateUid = aggregateID
+		envelope.SequenceNumber = uint64(version)
+		appended = append(appended, envelope)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	for _, envelope := range appended {
+		es.publish(envelope)
+	}
+	return nil
+}
+
+// Load returns all events of the aggregate in the order in which they were appended,
+// upgraded to the most recent version of each event
+func (es *EventStore) Load(ctx context.Context, aggregateID string) ([]Envelope, error) {
+	rows, err := es.db.QueryContext(ctx,
+		"SELECT version, event_type, event_data FROM events WHERE aggregate_id = $1 ORDER BY version", aggregateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	envelopes := []Envelope{}
+	for rows.Next() {
+		envelope := Envelope{AggregateUid: aggregateID}
+		var version int
+		err = rows.Scan(&version, &envelope.EventTypeName, &envelope.EventData)
+		if err != nil {
+			return nil, err
+		}
+		envelope.SequenceNumber = uint64(version)
+		upgraded, err := UpgradeEnvelope(&envelope)
+		if err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, *upgraded)
+	}
+	return envelopes, rows.Err()
+}
+
+// Subscribe calls the handler for every appended event of the given type, until the context is done
+func (es *EventStore) Subscribe(ctx context.Context, eventType string, handler func(Envelope)) error {
+	if handler == nil {
+		return fmt.Errorf("Missing handler for subscription to %s", eventType)
+	}
+
+	es.mutex.Lock()
+	es.lastID++
+	id := es.lastID
+	if _, found := es.subscribers[eventType]; !found {
+		es.subscribers[eventType] = make(map[int]func(Envelope))
+	}
+	es.subscribers[eventType][id] = handler
+	es.mutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		es.mutex.Lock()
+		delete(es.subscribers[eventType], id)
+		es.mutex.Unlock()
+	}()
+	return nil
+}
+
+func (es *EventStore) publish(envelope Envelope) {
+	es.mutex.RLock()
+	handlers := []func(Envelope){}
+	for _, handler := range es.subscribers[envelope.EventTypeName] {
+		handlers = append(handlers, handler)
+	}
+	es.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(envelope)
+	}
+}
+`
@@ -0,0 +1,52 @@
+package eventstore
+
+import (
+	"os"
+	"testing"
+
+	"io/ioutil"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateForEventStore(t *testing.T) {
+	os.Remove("./testData/eventStore.go")
+
+	s := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @EventStore( backend = "postgres" )`},
+			Name:        "Store",
+		},
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @Event( aggregate = "Tour" )`},
+			Name:        "TourCreated",
+		},
+	}
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/eventStore.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "func (es *EventStore) Append(ctx context.Context, aggregateID string, events []Envelope, expectedVersion int) error {")
+	assert.Contains(t, string(data), "func (es *EventStore) Load(ctx context.Context, aggregateID string) ([]Envelope, error) {")
+	assert.Contains(t, string(data), "upgraded, err := UpgradeEnvelope(&envelope)")
+	assert.Contains(t, string(data), "func (es *EventStore) Subscribe(ctx context.Context, eventType string, handler func(Envelope)) error {")
+	assert.Contains(t, string(data), "INSERT INTO events (aggregate_id, version, event_type, event_data) VALUES ($1, $2, $3, $4)")
+
+	os.Remove("./testData/eventStore.go")
+}
+
+func TestGenerateForEventStoreWithoutEvents(t *testing.T) {
+	s := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @EventStore()`},
+			Name:        "Store",
+		},
+	}
+	err := Generate("testData", s)
+	assert.Error(t, err)
+}
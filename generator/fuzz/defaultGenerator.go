@@ -0,0 +1,21 @@
+package fuzz
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+func Generate(inputDir string, operations []model.Operation) error {
+	return DefaultGenerator.Generate(inputDir, operations)
+}
+
+func IsFuzz(o model.Operation) bool {
+	return DefaultGenerator.IsFuzz(o)
+}
+
+func IsPure(o model.Operation) bool {
+	return DefaultGenerator.IsPure(o)
+}
+
+func HasPureOperations(operations []model.Operation) bool {
+	return DefaultGenerator.HasPureOperations(operations)
+}
@@ -0,0 +1,26 @@
+package fuzzAnnotation
+
+import "github.com/MarcGrol/golangAnnotations/annotation"
+
+const (
+	typeFuzz  = "Fuzz"
+	paramPure = "pure"
+)
+
+// Register makes the annotation-registry aware of this annotation
+func Register() {
+	RegisterIn(annotation.DefaultRegistry)
+}
+
+// RegisterIn makes the given registry aware of this annotation
+func RegisterIn(registry *annotation.Registry) {
+	registry.Register(typeFuzz, []string{paramPure}, validateFuzzAnnotation)
+}
+
+func validateFuzzAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeFuzz {
+		pure := annot.Attributes[paramPure]
+		return pure == "" || pure == "true" || pure == "false"
+	}
+	return false
+}
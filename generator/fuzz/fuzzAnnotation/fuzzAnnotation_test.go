@@ -0,0 +1,34 @@
+package fuzzAnnotation
+
+import (
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectFuzzAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	annot, ok := annotation.ResolveAnnotations([]string{`// @Fuzz()`})
+	assert.True(t, ok)
+	assert.Equal(t, "Fuzz", annot.Name)
+}
+
+func TestPureFuzzAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	annot, ok := annotation.ResolveAnnotations([]string{`// @Fuzz( pure = "true" )`})
+	assert.True(t, ok)
+	assert.Equal(t, "true", annot.Attributes["pure"])
+}
+
+func TestInvalidPureFuzzAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @Fuzz( pure = "maybe" )`})
+	assert.False(t, ok)
+}
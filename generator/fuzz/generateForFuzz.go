@@ -0,0 +1,233 @@
+package fuzz
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/fuzz/fuzzAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+type Operations struct {
+	PackageName string
+	Operations  []model.Operation
+}
+
+// Generator generates fuzz-tests for operations annotated with @Fuzz, as resolved in its registry
+type Generator struct {
+	registry *annotation.Registry
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+func (g *Generator) Generate(inputDir string, operations []model.Operation) error {
+	fuzzAnnotation.RegisterIn(g.registry)
+
+	fuzzOperations := []model.Operation{}
+	for _, o := range operations {
+		if g.IsFuzz(o) {
+			err := validateFuzzOperation(o)
+			if err != nil {
+				return err
+			}
+			fuzzOperations = append(fuzzOperations, o)
+		}
+	}
+
+	if len(fuzzOperations) > 0 {
+		packageName, err := generationUtil.GetPackageNameOfOperations(operations)
+		if err != nil {
+			return err
+		}
+		targetDir, err := generationUtil.DetermineTargetPath(inputDir, packageName)
+		if err != nil {
+			return err
+		}
+		target := fmt.Sprintf("%s/fuzzOperations_test.go", targetDir)
+
+		data := Operations{
+			PackageName: packageName,
+			Operations:  fuzzOperations,
+		}
+		err = generationUtil.GenerateFileFromTemplate(data, "fuzz", fuzzTemplate, g.templateFuncs(), target)
+		if err != nil {
+			log.Fatalf("Error generating fuzz tests for operations (%s)", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func validateFuzzOperation(o model.Operation) error {
+	if len(o.InputArgs) == 0 {
+		return fmt.Errorf("Fuzzed operation %s must have at least one argument", o.Name)
+	}
+	for _, arg := range o.InputArgs {
+		if !isFuzzable(arg) {
+			return fmt.Errorf("Argument %s of fuzzed operation %s has unsupported type %s", arg.Name, o.Name, arg.TypeName)
+		}
+	}
+	return nil
+}
+
+// isFuzzable tells if the testing-package can generate values for this type
+func isFuzzable(f model.Field) bool {
+	if f.IsPointer || f.PackageQualifier != "" {
+		return false
+	}
+	if f.IsSlice {
+		return f.TypeName == "byte"
+	}
+	switch f.TypeName {
+	case "string", "bool", "byte", "rune",
+		"int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return true
+	}
+	return false
+}
+
+func (g *Generator) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"IsFuzz":            g.IsFuzz,
+		"IsPure":            g.IsPure,
+		"HasPureOperations": g.HasPureOperations,
+		"ToFirstUpper":      ToFirstUpper,
+		"GetSeedValues":     GetSeedValues,
+		"GetFuzzParams":     GetFuzzParams,
+		"GetInvocation":     GetInvocation,
+		"GetResultNames":    GetResultNames,
+		"GetPrefixedNames":  GetPrefixedNames,
+	}
+}
+
+func (g *Generator) IsFuzz(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "Fuzz")
+	return ok
+}
+
+func (g *Generator) IsPure(o model.Operation) bool {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "Fuzz")
+	if ok {
+		return val.Attributes["pure"] == "true"
+	}
+	return false
+}
+
+func (g *Generator) HasPureOperations(operations []model.Operation) bool {
+	for _, o := range operations {
+		if g.IsPure(o) && len(o.OutputArgs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func ToFirstUpper(in string) string {
+	if len(in) == 0 {
+		return in
+	}
+	return strings.ToUpper(fmt.Sprintf("%c", in[0])) + in[1:]
+}
+
+// GetSeedValues returns the zero-values of all arguments, to seed the corpus: they are converted to the types of the
+// arguments, because the fuzzing engine does not accept an untyped 0 for an int64
+func GetSeedValues(o model.Operation) string {
+	values := []string{}
+	for _, arg := range o.InputArgs {
+		values = append(values, fmt.Sprintf("%s(%s)", arg.TypeKey(), arg.ZeroValueLiteral()))
+	}
+	return strings.Join(values, ", ")
+}
+
+func GetFuzzParams(o model.Operation) string {
+	params := []string{}
+	for idx, arg := range o.InputArgs {
+		typeName := arg.TypeName
+		if arg.IsSlice {
+			typeName = "[]" + typeName
+		}
+		params = append(params, fmt.Sprintf("%s %s", argName(arg, idx), typeName))
+	}
+	return strings.Join(params, ", ")
+}
+
+func GetInvocation(o model.Operation) string {
+	args := []string{}
+	for idx, arg := range o.InputArgs {
+		args = append(args, argName(arg, idx))
+	}
+	if o.RelatedStruct != nil {
+		return fmt.Sprintf("(&%s{}).%s(%s)", o.RelatedStruct.TypeName, o.Name, strings.Join(args, ", "))
+	}
+	return fmt.Sprintf("%s(%s)", o.Name, strings.Join(args, ", "))
+}
+
+// GetResultNames returns a comma-separated list of variable-names to store the results in
+func GetResultNames(o model.Operation, prefix string) string {
+	return strings.Join(GetPrefixedNames(o, prefix), ", ")
+}
+
+func GetPrefixedNames(o model.Operation, prefix string) []string {
+	names := []string{}
+	for idx := range o.OutputArgs {
+		names = append(names, fmt.Sprintf("%s%d", prefix, idx))
+	}
+	return names
+}
+
+func argName(arg model.Field, idx int) string {
+	if arg.Name == "" || arg.Name == "_" {
+		return fmt.Sprintf("arg%d", idx)
+	}
+	return arg.Name
+}
+
+var fuzzTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+{{if HasPureOperations .Operations }}
+	"reflect"
+{{end}}
+	"testing"
+)
+
+{{range .Operations}}
+{{ $operationName := .Name }}
+func FuzzTest{{ToFirstUpper .Name}}(f *testing.F) {
+	f.Add({{GetSeedValues . }})
+	f.Fuzz(func(t *testing.T, {{GetFuzzParams . }}) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("{{.Name}} panicked: %v", r)
+			}
+		}()
+
+		{{if and (IsPure .) .OutputArgs }}
+			{{GetResultNames . "first"}} := {{GetInvocation . }}
+			{{GetResultNames . "second"}} := {{GetInvocation . }}
+			{{range $idx, $name := GetPrefixedNames . "first"}}
+				if !reflect.DeepEqual({{$name}}, second{{$idx}}) {
+					t.Errorf("{{$operationName}} is not idempotent: result %d differs on repeated invocation: %v != %v", {{$idx}}, {{$name}}, second{{$idx}})
+				}
+			{{end}}
+		{{else}}
+			{{GetInvocation . }}
+		{{end}}
+	})
+}
+{{end}}
+`
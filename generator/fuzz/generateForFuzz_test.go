@@ -0,0 +1,79 @@
+package fuzz
+
+import (
+	"os"
+	"testing"
+
+	"io/ioutil"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateForFuzz(t *testing.T) {
+	os.Remove("./testData/fuzzOperations_test.go")
+
+	o := []model.Operation{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @Fuzz( pure = "true" )`},
+			Name:        "reverse",
+			InputArgs: []model.Field{
+				{Name: "in", TypeName: "string"},
+				{Name: "count", TypeName: "int"},
+			},
+			OutputArgs: []model.Field{
+				{TypeName: "string"},
+				{TypeName: "error"},
+			},
+		},
+		{
+			PackageName:   "testData",
+			DocLines:      []string{`// @Fuzz()`},
+			Name:          "parse",
+			RelatedStruct: &model.Field{Name: "p", TypeName: "Parser", IsPointer: true},
+			InputArgs: []model.Field{
+				{Name: "data", TypeName: "byte", IsSlice: true},
+			},
+			OutputArgs: []model.Field{
+				{TypeName: "error"},
+			},
+		},
+		{
+			PackageName: "testData",
+			Name:        "notFuzzed",
+		},
+	}
+	err := Generate("testData", o)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/fuzzOperations_test.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "func FuzzTestReverse(f *testing.F) {")
+	assert.Contains(t, string(data), `f.Add(string(""), int(0))`)
+	assert.Contains(t, string(data), "f.Fuzz(func(t *testing.T, in string, count int) {")
+	assert.Contains(t, string(data), "first0, first1 := reverse(in, count)")
+	assert.Contains(t, string(data), "if !reflect.DeepEqual(first1, second1) {")
+
+	assert.Contains(t, string(data), "func FuzzTestParse(f *testing.F) {")
+	assert.Contains(t, string(data), "f.Add([]byte(nil))")
+	assert.Contains(t, string(data), "(&Parser{}).parse(data)")
+	assert.NotContains(t, string(data), "notFuzzed")
+
+	os.Remove("./testData/fuzzOperations_test.go")
+}
+
+func TestGenerateForFuzzWithUnsupportedArgument(t *testing.T) {
+	o := []model.Operation{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @Fuzz()`},
+			Name:        "store",
+			InputArgs: []model.Field{
+				{Name: "p", TypeName: "Person"},
+			},
+		},
+	}
+	err := Generate("testData", o)
+	assert.Error(t, err)
+}
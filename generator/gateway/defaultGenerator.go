@@ -0,0 +1,22 @@
+package gateway
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+func Generate(inputDir string, structs []model.Struct) error {
+	return DefaultGenerator.Generate(inputDir, structs)
+}
+
+func IsGateway(s model.Struct) bool {
+	return DefaultGenerator.IsGateway(s)
+}
+
+func GetGatewayTarget(s model.Struct) string {
+	return DefaultGenerator.GetGatewayTarget(s)
+}
+
+// GetGatewayUpstream returns the url to which the gateway forwards requests: defaults to http://localhost:8080
+func GetGatewayUpstream(s model.Struct) string {
+	return DefaultGenerator.GetGatewayUpstream(s)
+}
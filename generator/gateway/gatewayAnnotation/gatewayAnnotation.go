@@ -0,0 +1,29 @@
+package gatewayAnnotation
+
+import "github.com/MarcGrol/golangAnnotations/annotation"
+
+const (
+	typeGateway   = "Gateway"
+	paramTarget   = "target"
+	paramUpstream = "upstream"
+)
+
+// Register makes the annotation-registry aware of this annotation
+func Register() {
+	RegisterIn(annotation.DefaultRegistry)
+}
+
+// RegisterIn makes the given registry aware of this annotation
+func RegisterIn(registry *annotation.Registry) {
+	registry.Register(typeGateway, []string{paramTarget, paramUpstream}, validateGatewayAnnotation)
+}
+
+func validateGatewayAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeGateway {
+		switch annot.Attributes[paramTarget] {
+		case "aws", "kong", "nginx":
+			return true
+		}
+	}
+	return false
+}
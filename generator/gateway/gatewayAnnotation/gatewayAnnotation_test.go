@@ -0,0 +1,34 @@
+package gatewayAnnotation
+
+import (
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectGatewayAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	annot, ok := annotation.ResolveAnnotations([]string{`// @Gateway( target = "kong", upstream = "http://orders:8080" )`})
+	assert.True(t, ok)
+	assert.Equal(t, "kong", annot.Attributes["target"])
+	assert.Equal(t, "http://orders:8080", annot.Attributes["upstream"])
+}
+
+func TestInvalidTargetGatewayAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @Gateway( target = "traefik" )`})
+	assert.False(t, ok)
+}
+
+func TestIncompleteGatewayAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @Gateway()`})
+	assert.False(t, ok)
+}
@@ -0,0 +1,327 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/gateway/gatewayAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/generator/rest"
+	"github.com/MarcGrol/golangAnnotations/generator/rest/restAnnotation"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+const defaultUpstream = "http://localhost:8080"
+
+type GatewayData struct {
+	Service  string
+	Upstream string
+	Routes   []Route
+}
+
+// Route is a rest-operation as seen by the gateway: Path is the full path, including the path of the service
+type Route struct {
+	Name   string
+	Method string
+	Path   string
+	Params []PathParam
+}
+
+type PathParam struct {
+	Name     string
+	Pattern  string // optional: the regular expression of a path-parameter like {id:[0-9]+}
+	IsNumber bool
+}
+
+// Location groups the routes that share the same path into a single nginx location-block
+type Location struct {
+	Regex   string
+	Methods []string
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}:]+)(?::([^}]+))?\}`)
+
+// Generator generates api-gateway configuration for services annotated with @Gateway: the rest-annotations of the
+// services are resolved in the same registry
+type Generator struct {
+	registry *annotation.Registry
+	rest     *rest.Generator
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry, rest: rest.NewGenerator(registry)}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+func (g *Generator) Generate(inputDir string, structs []model.Struct) error {
+	gatewayAnnotation.RegisterIn(g.registry)
+	restAnnotation.RegisterIn(g.registry)
+
+	packageName, err := generationUtil.GetPackageName(structs)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range structs {
+		if !g.IsGateway(s) {
+			continue
+		}
+		if !g.rest.IsRestService(s) {
+			return fmt.Errorf("Struct %s is annotated with @Gateway but is not a @RestService", s.Name)
+		}
+
+		targetDir, err := generationUtil.DetermineTargetPath(inputDir, packageName)
+		if err != nil {
+			return err
+		}
+		data := GatewayData{
+			Service:  s.Name,
+			Upstream: g.GetGatewayUpstream(s),
+			Routes:   g.getRoutes(s, structs),
+		}
+		switch g.GetGatewayTarget(s) {
+		case "aws":
+			err = generateAWS(fmt.Sprintf("%s/gateway%s.json", targetDir, s.Name), data)
+		case "kong":
+			target := fmt.Sprintf("%s/gateway%s.yaml", targetDir, s.Name)
+			err = generationUtil.GenerateFileFromTemplate(data, "kong", kongTemplate, customTemplateFuncs, target)
+		case "nginx":
+			target := fmt.Sprintf("%s/gateway%s.conf", targetDir, s.Name)
+			err = generationUtil.GenerateFileFromTemplate(data, "nginx", nginxTemplate, customTemplateFuncs, target)
+		}
+		if err != nil {
+			log.Fatalf("Error generating gateway-configuration for service %s: %s", s.Name, err)
+			return err
+		}
+	}
+	return nil
+}
+
+var customTemplateFuncs = template.FuncMap{
+	"ToKongPath":   ToKongPath,
+	"GetLocations": GetLocations,
+	"YamlQuote":    yamlQuote,
+	"ToLower":      strings.ToLower,
+}
+
+func (g *Generator) IsGateway(s model.Struct) bool {
+	_, ok := g.registry.ResolveAnnotationByName(s.DocLines, "Gateway")
+	return ok
+}
+
+func (g *Generator) GetGatewayTarget(s model.Struct) string {
+	val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "Gateway")
+	if ok {
+		return val.Attributes["target"]
+	}
+	return ""
+}
+
+// GetGatewayUpstream returns the url to which the gateway forwards requests: defaults to http://localhost:8080
+func (g *Generator) GetGatewayUpstream(s model.Struct) string {
+	val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "Gateway")
+	if ok && val.Attributes["upstream"] != "" {
+		return strings.TrimSuffix(val.Attributes["upstream"], "/")
+	}
+	return defaultUpstream
+}
+
+func (g *Generator) getRoutes(s model.Struct, structs []model.Struct) []Route {
+	prefix := g.rest.GetRestServicePrefix(s, structs)
+	routes := []Route{}
+	for _, o := range s.Operations {
+		if !g.rest.IsRestOperation(*o) {
+			continue
+		}
+		route := Route{
+			Name:   o.Name,
+			Method: g.rest.GetRestOperationMethod(*o),
+			Path:   prefix + g.rest.GetRestOperationPath(*o),
+		}
+		for _, match := range pathParamPattern.FindAllStringSubmatch(route.Path, -1) {
+			param := PathParam{Name: match[1], Pattern: match[2]}
+			for _, arg := range o.InputArgs {
+				if arg.Name == param.Name {
+					param.IsNumber = rest.IsNumber(arg)
+				}
+			}
+			route.Params = append(route.Params, param)
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// ToPathRegex converts a path like /api/person/{uid} into an anchored regular expression like ^/api/person/[^/]+$
+func ToPathRegex(path string) string {
+	regex := ""
+	pos := 0
+	for _, match := range pathParamPattern.FindAllStringSubmatchIndex(path, -1) {
+		regex += regexp.QuoteMeta(path[pos:match[0]])
+		if match[4] >= 0 {
+			regex += "(" + path[match[4]:match[5]] + ")"
+		} else {
+			regex += "[^/]+"
+		}
+		pos = match[1]
+	}
+	return "^" + regex + regexp.QuoteMeta(path[pos:]) + "$"
+}
+
+// ToKongPath converts a path into a regex-path of kong, which is anchored at the start implicitly
+func ToKongPath(path string) string {
+	return "~" + strings.TrimPrefix(ToPathRegex(path), "^")
+}
+
+// toSwaggerPath strips the regular expressions from the path-parameters, which swagger does not support
+func toSwaggerPath(path string) string {
+	return pathParamPattern.ReplaceAllString(path, "{$1}")
+}
+
+// GetLocations groups the routes by path, because nginx only uses the first location that matches a request
+func GetLocations(routes []Route) []Location {
+	locations := []Location{}
+	indexOf := map[string]int{}
+	for _, r := range routes {
+		regex := ToPathRegex(r.Path)
+		idx, found := indexOf[regex]
+		if !found {
+			idx = len(locations)
+			indexOf[regex] = idx
+			locations = append(locations, Location{Regex: regex})
+		}
+		locations[idx].Methods = append(locations[idx].Methods, r.Method)
+	}
+	return locations
+}
+
+func yamlQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+type awsAPI struct {
+	Swagger string                             `json:"swagger"`
+	Info    awsInfo                            `json:"info"`
+	Paths   map[string]map[string]awsOperation `json:"paths"`
+}
+
+type awsInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type awsOperation struct {
+	OperationID string                 `json:"operationId"`
+	Parameters  []awsParameter         `json:"parameters,omitempty"`
+	Responses   map[string]awsResponse `json:"responses"`
+	Integration awsIntegration         `json:"x-amazon-apigateway-integration"`
+}
+
+type awsParameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+	Type     string `json:"type"`
+}
+
+type awsResponse struct {
+	Description string `json:"description"`
+}
+
+type awsIntegration struct {
+	Type                string            `json:"type"`
+	HTTPMethod          string            `json:"httpMethod"`
+	URI                 string            `json:"uri"`
+	PassthroughBehavior string            `json:"passthroughBehavior"`
+	RequestParameters   map[string]string `json:"requestParameters,omitempty"`
+}
+
+// generateAWS writes a swagger-definition with http-proxy integrations that can be imported into AWS API Gateway
+func generateAWS(targetFileName string, data GatewayData) error {
+	log.Printf("Using json to generate target %s\n", targetFileName)
+
+	api := awsAPI{
+		Swagger: "2.0",
+		Info: awsInfo{
+			Title:       data.Service,
+			Description: "Generated automatically: do not edit manually",
+			Version:     "1.0",
+		},
+		Paths: map[string]map[string]awsOperation{},
+	}
+	for _, r := range data.Routes {
+		path := toSwaggerPath(r.Path)
+		operation := awsOperation{
+			OperationID: r.Name,
+			Responses:   map[string]awsResponse{"default": {Description: "Response of the upstream service"}},
+			Integration: awsIntegration{
+				Type:                "http_proxy",
+				HTTPMethod:          r.Method,
+				URI:                 data.Upstream + path,
+				PassthroughBehavior: "when_no_match",
+			},
+		}
+		for _, p := range r.Params {
+			paramType := "string"
+			if p.IsNumber {
+				paramType = "integer"
+			}
+			operation.Parameters = append(operation.Parameters, awsParameter{Name: p.Name, In: "path", Required: true, Type: paramType})
+			if operation.Integration.RequestParameters == nil {
+				operation.Integration.RequestParameters = map[string]string{}
+			}
+			operation.Integration.RequestParameters["integration.request.path."+p.Name] = "method.request.path." + p.Name
+		}
+		if api.Paths[path] == nil {
+			api.Paths[path] = map[string]awsOperation{}
+		}
+		api.Paths[path][strings.ToLower(r.Method)] = operation
+	}
+
+	blob, err := json.MarshalIndent(api, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(targetFileName), 0777)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(targetFileName, append(blob, '\n'), 0644)
+}
+
+var kongTemplate string = `# Generated automatically: do not edit manually
+
+_format_version: "3.0"
+
+services:
+- name: {{ToLower .Service}}
+  url: {{YamlQuote .Upstream}}
+  routes:{{$service := .Service}}{{range .Routes}}
+  - name: {{ToLower $service}}-{{.Name}}
+    methods:
+    - {{.Method}}
+    paths:
+    - {{YamlQuote (ToKongPath .Path)}}
+    strip_path: false{{end}}
+`
+
+var nginxTemplate string = `# Generated automatically: do not edit manually
+{{$upstream := .Upstream}}{{range GetLocations .Routes}}
+location ~ "{{.Regex}}" {
+    limit_except{{range .Methods}} {{.}}{{end}} {
+        deny all;
+    }
+    proxy_pass {{$upstream}};
+}
+{{end}}`
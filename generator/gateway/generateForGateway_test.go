@@ -0,0 +1,125 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func serviceWithGateway(gatewayAnnotation string) []model.Struct {
+	return []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines: []string{
+				gatewayAnnotation,
+				`// @RestService( path = "/api" )`,
+			},
+			Name: "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{`// @RestOperation( method = "GET", path = "/person/{uid}" )`},
+					Name:          "getPerson",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "uid", TypeName: "string"}},
+					OutputArgs:    []model.Field{{TypeName: "Person"}, {TypeName: "error"}},
+				},
+				{
+					DocLines:      []string{`// @RestOperation( method = "PUT", path = "/person/{uid}" )`},
+					Name:          "updatePerson",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "uid", TypeName: "string"}, {Name: "person", TypeName: "Person"}},
+					OutputArgs:    []model.Field{{TypeName: "Person"}, {TypeName: "error"}},
+				},
+				{
+					DocLines:      []string{`// @RestOperation( method = "GET", path = "/order/{id:[0-9]+}" )`},
+					Name:          "getOrder",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "id", TypeName: "int"}},
+					OutputArgs:    []model.Field{{TypeName: "Order"}, {TypeName: "error"}},
+				},
+			},
+		},
+	}
+}
+
+func TestGenerateForKong(t *testing.T) {
+	os.Remove("./testData/gatewayMyService.yaml")
+
+	err := Generate("testData", serviceWithGateway(`// @Gateway( target = "kong", upstream = "http://persons:8080/" )`))
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/gatewayMyService.yaml")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "- name: myservice\n  url: 'http://persons:8080'\n  routes:\n")
+	assert.Contains(t, string(data), "  - name: myservice-getPerson\n    methods:\n    - GET\n    paths:\n    - '~/api/person/[^/]+$'\n    strip_path: false\n")
+	assert.Contains(t, string(data), "  - name: myservice-updatePerson\n    methods:\n    - PUT\n")
+	assert.Contains(t, string(data), "    - '~/api/order/([0-9]+)$'\n")
+
+	os.Remove("./testData/gatewayMyService.yaml")
+}
+
+func TestGenerateForNginx(t *testing.T) {
+	os.Remove("./testData/gatewayMyService.conf")
+
+	err := Generate("testData", serviceWithGateway(`// @Gateway( target = "nginx" )`))
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/gatewayMyService.conf")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "location ~ \"^/api/person/[^/]+$\" {\n    limit_except GET PUT {\n        deny all;\n    }\n    proxy_pass http://localhost:8080;\n}\n")
+	assert.Contains(t, string(data), "location ~ \"^/api/order/([0-9]+)$\" {\n    limit_except GET {\n")
+
+	os.Remove("./testData/gatewayMyService.conf")
+}
+
+func TestGenerateForAWS(t *testing.T) {
+	os.Remove("./testData/gatewayMyService.json")
+
+	err := Generate("testData", serviceWithGateway(`// @Gateway( target = "aws", upstream = "https://persons.example.com" )`))
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/gatewayMyService.json")
+	assert.NoError(t, err)
+
+	api := awsAPI{}
+	err = json.Unmarshal(data, &api)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0", api.Swagger)
+	assert.Equal(t, 2, len(api.Paths))
+
+	getPerson := api.Paths["/api/person/{uid}"]["get"]
+	assert.Equal(t, "getPerson", getPerson.OperationID)
+	assert.Equal(t, []awsParameter{{Name: "uid", In: "path", Required: true, Type: "string"}}, getPerson.Parameters)
+	assert.Equal(t, "http_proxy", getPerson.Integration.Type)
+	assert.Equal(t, "GET", getPerson.Integration.HTTPMethod)
+	assert.Equal(t, "https://persons.example.com/api/person/{uid}", getPerson.Integration.URI)
+	assert.Equal(t, "method.request.path.uid", getPerson.Integration.RequestParameters["integration.request.path.uid"])
+	assert.Equal(t, "PUT", api.Paths["/api/person/{uid}"]["put"].Integration.HTTPMethod)
+
+	getOrder := api.Paths["/api/order/{id}"]["get"]
+	assert.Equal(t, "integer", getOrder.Parameters[0].Type)
+
+	os.Remove("./testData/gatewayMyService.json")
+}
+
+func TestGenerateForGatewayWithoutRestService(t *testing.T) {
+	s := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @Gateway( target = "kong" )`},
+			Name:        "MyService",
+		},
+	}
+	err := Generate("testData", s)
+	assert.Error(t, err)
+}
+
+func TestToPathRegex(t *testing.T) {
+	assert.Equal(t, `^/api/person$`, ToPathRegex("/api/person"))
+	assert.Equal(t, `^/api/person/[^/]+/address\.json$`, ToPathRegex("/api/person/{uid}/address.json"))
+	assert.Equal(t, `^/api/order/([0-9]+)$`, ToPathRegex("/api/order/{id:[0-9]+}"))
+}
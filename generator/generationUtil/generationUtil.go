@@ -2,12 +2,13 @@ package generationUtil
 
 import (
 	"fmt"
-	"html/template"
+	"io/ioutil"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"text/template"
 
 	"github.com/MarcGrol/golangAnnotations/model"
 )
@@ -25,6 +26,19 @@ func GetPackageName(structs []model.Struct) (string, error) {
 	return packageName, nil
 }
 
+func GetPackageNameOfOperations(operations []model.Operation) (string, error) {
+	if len(operations) == 0 {
+		return "", fmt.Errorf("Need at least one operation to determine package-name")
+	}
+	packageName := operations[0].PackageName
+	for _, o := range operations {
+		if o.PackageName != packageName {
+			return "", fmt.Errorf("List of operations has multiple package-names")
+		}
+	}
+	return packageName, nil
+}
+
 func DetermineTargetPath(inputDir string, packageName string) (string, error) {
 	goPath := os.Getenv("GOPATH")
 	if goPath == "" {
@@ -48,6 +62,37 @@ func DetermineTargetPath(inputDir string, packageName string) (string, error) {
 	}
 }
 
+// DetermineImportPath derives the import-path of a directory from its location within GOPATH
+func DetermineImportPath(dir string) (string, error) {
+	goPath := os.Getenv("GOPATH")
+	if goPath == "" {
+		return "", fmt.Errorf("GOPATH not set")
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("Error determining absolute path of %s:%s", dir, err)
+	}
+
+	for _, p := range filepath.SplitList(goPath) {
+		srcDir := filepath.Join(p, "src") + string(filepath.Separator)
+		if strings.HasPrefix(absDir, srcDir) {
+			return filepath.ToSlash(strings.TrimPrefix(absDir, srcDir)), nil
+		}
+	}
+	return "", fmt.Errorf("Code %s lives outside GOPATH:%s", absDir, goPath)
+}
+
+// IsEditedFile tells if an existing file no longer starts with a "// Code generated"-header:
+// such a file has been taken over by the user and must not be overwritten
+func IsEditedFile(fileName string) bool {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return false
+	}
+	return !strings.HasPrefix(strings.TrimSpace(string(data)), "// Code generated")
+}
+
 func GenerateFileFromTemplate(data interface{}, templateName string, templateString string, funcMap template.FuncMap, targetFileName string) error {
 	log.Printf("Using template '%s' to generate target %s\n", templateName, targetFileName)
 
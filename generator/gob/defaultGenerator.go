@@ -0,0 +1,13 @@
+package gob
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+func Generate(inputDir string, structs []model.Struct) error {
+	return DefaultGenerator.Generate(inputDir, structs)
+}
+
+func IsGobEncodable(s model.Struct) bool {
+	return DefaultGenerator.IsGobEncodable(s)
+}
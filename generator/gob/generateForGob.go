@@ -0,0 +1,146 @@
+package gob
+
+import (
+	"fmt"
+	"log"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/generator/gob/gobAnnotation"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+type Structs struct {
+	PackageName string
+	Structs     []model.Struct
+}
+
+// Generator generates gob-encoders for structs annotated with @GobEncodable in its registry
+type Generator struct {
+	registry *annotation.Registry
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+func (g *Generator) Generate(inputDir string, structs []model.Struct) error {
+	gobAnnotation.RegisterIn(g.registry)
+
+	packageName, err := generationUtil.GetPackageName(structs)
+	if err != nil {
+		return err
+	}
+
+	gobCount := 0
+	for _, s := range structs {
+		if g.IsGobEncodable(s) {
+			gobCount++
+			for _, f := range s.PrivateFields {
+				log.Printf("Warning: Field %s.%s is unexported and will not be gob-encoded.", s.Name, GetFieldName(f))
+			}
+		}
+	}
+
+	if gobCount > 0 {
+		targetDir, err := generationUtil.DetermineTargetPath(inputDir, packageName)
+		if err != nil {
+			return err
+		}
+		target := fmt.Sprintf("%s/gobEncoding.go", targetDir)
+
+		data := Structs{
+			PackageName: packageName,
+			Structs:     structs,
+		}
+		err = generationUtil.GenerateFileFromTemplate(data, "gob", gobTemplate, g.templateFuncs(), target)
+		if err != nil {
+			log.Fatalf("Error generating gob encoding for structs (%s)", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Generator) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"IsGobEncodable": g.IsGobEncodable,
+		"IsExported":     IsExported,
+		"GetFieldName":   GetFieldName,
+	}
+}
+
+func (g *Generator) IsGobEncodable(s model.Struct) bool {
+	_, ok := g.registry.ResolveAnnotationByName(s.DocLines, "GobEncodable")
+	return ok
+}
+
+// GetFieldName returns the name by which a field is accessed: embedded fields are accessed by their type-name
+func GetFieldName(f model.Field) string {
+	if f.Name == "" {
+		return f.TypeName
+	}
+	return f.Name
+}
+
+// IsExported tells if a field is visible to encoding/gob
+func IsExported(f model.Field) bool {
+	r, _ := utf8.DecodeRuneInString(GetFieldName(f))
+	return unicode.IsUpper(r)
+}
+
+var gobTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+func init() {
+{{range .Structs}}
+{{if IsGobEncodable . }}
+	gob.Register({{.Name}}{})
+{{end}}
+{{end}}
+}
+
+{{range .Structs}}
+{{if IsGobEncodable . }}
+
+// gob{{.Name}} has the same fields as {{.Name}} but lacks its methods, to prevent endless recursion
+type gob{{.Name}} {{.Name}}
+
+func (s {{.Name}}) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(gob{{.Name}}(s))
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *{{.Name}}) GobDecode(data []byte) error {
+	var decoded gob{{.Name}}
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded)
+	if err != nil {
+		return err
+	}
+	{{range .Fields}}
+	{{if IsExported . }}
+	s.{{GetFieldName . }} = decoded.{{GetFieldName . }}
+	{{end}}
+	{{end}}
+	return nil
+}
+{{end}}
+{{end}}
+`
@@ -0,0 +1,46 @@
+package gob
+
+import (
+	"os"
+	"testing"
+
+	"io/ioutil"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateForGob(t *testing.T) {
+	os.Remove("./testData/gobEncoding.go")
+
+	s := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @GobEncodable()`},
+			Name:        "MyStruct",
+			Fields: []model.Field{
+				{Name: "StringField", TypeName: "string"},
+				{Name: "privateField", TypeName: "int"},
+				{Name: "SliceField", TypeName: "MyStruct", IsSlice: true},
+			},
+		},
+		{
+			PackageName: "testData",
+			Name:        "OtherStruct",
+		},
+	}
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/gobEncoding.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "gob.Register(MyStruct{})")
+	assert.Contains(t, string(data), "func (s MyStruct) GobEncode() ([]byte, error) {")
+	assert.Contains(t, string(data), "func (s *MyStruct) GobDecode(data []byte) error {")
+	assert.Contains(t, string(data), "s.StringField = decoded.StringField")
+	assert.Contains(t, string(data), "s.SliceField = decoded.SliceField")
+	assert.NotContains(t, string(data), "privateField")
+	assert.NotContains(t, string(data), "OtherStruct")
+
+	os.Remove("./testData/gobEncoding.go")
+}
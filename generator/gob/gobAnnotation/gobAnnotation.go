@@ -0,0 +1,21 @@
+package gobAnnotation
+
+import "github.com/MarcGrol/golangAnnotations/annotation"
+
+const (
+	typeGobEncodable = "GobEncodable"
+)
+
+// Register makes the annotation-registry aware of this annotation
+func Register() {
+	RegisterIn(annotation.DefaultRegistry)
+}
+
+// RegisterIn makes the given registry aware of this annotation
+func RegisterIn(registry *annotation.Registry) {
+	registry.Register(typeGobEncodable, []string{}, validateGobEncodableAnnotation)
+}
+
+func validateGobEncodableAnnotation(annot annotation.Annotation) bool {
+	return annot.Name == typeGobEncodable
+}
@@ -0,0 +1,25 @@
+package gobAnnotation
+
+import (
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectGobEncodableAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	annot, ok := annotation.ResolveAnnotations([]string{`// @GobEncodable()`})
+	assert.True(t, ok)
+	assert.Equal(t, "GobEncodable", annot.Name)
+}
+
+func TestOtherAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @Event( aggregate = "test" )`})
+	assert.False(t, ok)
+}
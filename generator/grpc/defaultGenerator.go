@@ -0,0 +1,23 @@
+package grpc
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+// Generate writes a .proto-file with the services and messages of all interfaces annotated with @GrpcService, and a
+// grpc-server per service that delegates to an implementation of the interface. The type-map file is optional.
+func Generate(inputDir string, interfaces []model.Interface, structs []model.Struct, typeMapFile string) error {
+	return DefaultGenerator.Generate(inputDir, interfaces, structs, typeMapFile)
+}
+
+func IsGrpcService(iface model.Interface) bool {
+	return DefaultGenerator.IsGrpcService(iface)
+}
+
+func GetProtoPackage(iface model.Interface) string {
+	return DefaultGenerator.GetProtoPackage(iface)
+}
+
+func IsGrpcStream(o model.Operation) bool {
+	return DefaultGenerator.IsGrpcStream(o)
+}
@@ -0,0 +1,725 @@
+package grpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/generator/grpc/grpcAnnotation"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+// defaultTypeMap maps the builtin go-types onto proto-types: the entries of a type-map file are added to it
+var defaultTypeMap = map[string]string{
+	"string":  "string",
+	"bool":    "bool",
+	"int":     "int64",
+	"int32":   "int32",
+	"int64":   "int64",
+	"uint":    "uint64",
+	"uint32":  "uint32",
+	"uint64":  "uint64",
+	"float32": "float",
+	"float64": "double",
+	"[]byte":  "bytes",
+}
+
+// scalarGoTypes are the go-types of the scalar proto-types in the generated pb-code
+var scalarGoTypes = map[string]string{
+	"string":   "string",
+	"bool":     "bool",
+	"bytes":    "[]byte",
+	"int32":    "int32",
+	"sint32":   "int32",
+	"sfixed32": "int32",
+	"int64":    "int64",
+	"sint64":   "int64",
+	"sfixed64": "int64",
+	"uint32":   "uint32",
+	"fixed32":  "uint32",
+	"uint64":   "uint64",
+	"fixed64":  "uint64",
+	"float":    "float32",
+	"double":   "float64",
+}
+
+type ServiceData struct {
+	PackageName   string
+	PbPackageName string
+	PbImportPath  string
+	Name          string
+	Methods       []Method
+}
+
+// Method is an rpc of a service: the arguments and results of the interface-method are the fields of its request- and
+// response-message
+type Method struct {
+	Name       string // name of the rpc
+	GoName     string // name of the method of the interface
+	HasContext bool   // the interface-method takes a context.Context as its first argument
+	IsStream   bool   // the interface-method returns a channel: every item is sent as a response of its own
+	Request    Message
+	Response   Message
+}
+
+type Message struct {
+	Name   string
+	Fields []MessageField
+}
+
+type MessageField struct {
+	Name      string // snake_case name in the .proto-file
+	GoName    string // name of the field in the generated pb-code
+	Type      string // scalar proto-type or the name of a message
+	Repeated  bool
+	Number    int
+	IsMessage bool
+	PbGoType  string      // only for scalars: the go-type of the field in the generated pb-code
+	Field     model.Field // the struct-field or argument from which the field is derived
+}
+
+type ProtoData struct {
+	ProtoPackage string
+	GoPackage    string
+	Services     []ServiceData
+	Messages     []Message
+}
+
+type HelpersData struct {
+	PackageName   string
+	PbPackageName string
+	PbImportPath  string
+	Messages      []Message // only the messages of structs: requests and responses are converted by the servers
+}
+
+// Generator generates grpc-servers for interfaces annotated with @GrpcService in its registry
+type Generator struct {
+	registry *annotation.Registry
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+// Generate writes a .proto-file with the services and messages of all interfaces annotated with @GrpcService, and a
+// grpc-server per service that delegates to an implementation of the interface. The type-map file is optional.
+func (g *Generator) Generate(inputDir string, interfaces []model.Interface, structs []model.Struct, typeMapFile string) error {
+	grpcAnnotation.RegisterIn(g.registry)
+
+	services := []model.Interface{}
+	for _, iface := range interfaces {
+		if g.IsGrpcService(iface) {
+			services = append(services, iface)
+		}
+	}
+	if len(services) == 0 {
+		return nil
+	}
+
+	typeMap, err := loadTypeMap(typeMapFile)
+	if err != nil {
+		return err
+	}
+
+	packageName := services[0].PackageName
+	protoPackage := g.GetProtoPackage(services[0])
+	for _, iface := range services {
+		if g.GetProtoPackage(iface) != protoPackage {
+			return fmt.Errorf("The @GrpcServices of package %s have different proto-packages: %s and %s", packageName, protoPackage, g.GetProtoPackage(iface))
+		}
+	}
+
+	targetDir, err := generationUtil.DetermineTargetPath(inputDir, packageName)
+	if err != nil {
+		return err
+	}
+	importPath, err := generationUtil.DetermineImportPath(targetDir)
+	if err != nil {
+		return err
+	}
+	pbPackageName := packageName + "pb"
+
+	b := newMessageBuilder(typeMap, structs)
+	protoData := ProtoData{
+		ProtoPackage: protoPackage,
+		GoPackage:    importPath + "/" + pbPackageName,
+	}
+	for _, iface := range services {
+		service := ServiceData{
+			PackageName:   packageName,
+			PbPackageName: pbPackageName,
+			PbImportPath:  protoData.GoPackage,
+			Name:          iface.Name,
+		}
+		for _, m := range iface.Methods {
+			method, err := b.method(iface, m)
+			if err != nil {
+				return err
+			}
+			service.Methods = append(service.Methods, method)
+			protoData.Messages = append(protoData.Messages, method.Request, method.Response)
+		}
+		protoData.Services = append(protoData.Services, service)
+	}
+	protoData.Messages = append(protoData.Messages, b.messages...)
+	err = validateMessageNames(protoData.Messages)
+	if err != nil {
+		return err
+	}
+
+	target := fmt.Sprintf("%s/%s/%s.proto", targetDir, pbPackageName, packageName)
+	err = generationUtil.GenerateFileFromTemplate(protoData, "proto", protoTemplate, customTemplateFuncs, target)
+	if err != nil {
+		log.Fatalf("Error generating proto-file for package %s: %s", packageName, err)
+		return err
+	}
+
+	for _, service := range protoData.Services {
+		target := fmt.Sprintf("%s/grpc%sServer.go", targetDir, service.Name)
+		err = generationUtil.GenerateFileFromTemplate(service, "grpcServer", serverTemplate, customTemplateFuncs, target)
+		if err != nil {
+			log.Fatalf("Error generating grpc-server for service %s: %s", service.Name, err)
+			return err
+		}
+	}
+
+	helpersData := HelpersData{
+		PackageName:   packageName,
+		PbPackageName: pbPackageName,
+		PbImportPath:  protoData.GoPackage,
+		Messages:      b.messages,
+	}
+	target = fmt.Sprintf("%s/grpcHelpers.go", targetDir)
+	err = generationUtil.GenerateFileFromTemplate(helpersData, "grpcHelpers", helpersTemplate, customTemplateFuncs, target)
+	if err != nil {
+		log.Fatalf("Error generating grpc-helpers for package %s: %s", packageName, err)
+		return err
+	}
+	return nil
+}
+
+func (g *Generator) IsGrpcService(iface model.Interface) bool {
+	_, ok := g.registry.ResolveAnnotationByName(iface.DocLines, "GrpcService")
+	return ok
+}
+
+func (g *Generator) GetProtoPackage(iface model.Interface) string {
+	val, ok := g.registry.ResolveAnnotationByName(iface.DocLines, "GrpcService")
+	if ok {
+		return val.Attributes["protopackage"]
+	}
+	return ""
+}
+
+func (g *Generator) IsGrpcStream(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "GrpcStream")
+	return ok
+}
+
+// loadTypeMap reads the mapping of go-types onto scalar proto-types from a json-file, like {"int": "sint64", "Status":
+// "string"}: its entries are added to the defaults, or replace them
+func loadTypeMap(typeMapFile string) (map[string]string, error) {
+	typeMap := map[string]string{}
+	for goType, protoType := range defaultTypeMap {
+		typeMap[goType] = protoType
+	}
+	if typeMapFile == "" {
+		return typeMap, nil
+	}
+
+	data, err := ioutil.ReadFile(typeMapFile)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading type-map %s: %s", typeMapFile, err)
+	}
+	entries := map[string]string{}
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing type-map %s: %s", typeMapFile, err)
+	}
+	for goType, protoType := range entries {
+		if _, ok := scalarGoTypes[protoType]; !ok {
+			return nil, fmt.Errorf("Type-map %s maps %s onto %s, which is not a scalar proto-type", typeMapFile, goType, protoType)
+		}
+		typeMap[goType] = protoType
+	}
+	return typeMap, nil
+}
+
+// messageBuilder derives the messages of the structs of the package, the first time they are used
+type messageBuilder struct {
+	typeMap  map[string]string
+	structs  map[string]model.Struct
+	seen     map[string]bool
+	messages []Message
+}
+
+func newMessageBuilder(typeMap map[string]string, structs []model.Struct) *messageBuilder {
+	b := &messageBuilder{
+		typeMap: typeMap,
+		structs: map[string]model.Struct{},
+		seen:    map[string]bool{},
+	}
+	for _, s := range structs {
+		b.structs[s.Name] = s
+	}
+	return b
+}
+
+func (b *messageBuilder) method(iface model.Interface, m model.Operation) (Method, error) {
+	method := Method{
+		Name:     ToFirstUpper(m.Name),
+		GoName:   m.Name,
+		IsStream: IsGrpcStream(m),
+	}
+	method.Request.Name = method.Name + "Request"
+	method.Response.Name = method.Name + "Response"
+
+	args := m.InputArgs
+	if len(args) > 0 && isContext(args[0]) {
+		method.HasContext = true
+		args = args[1:]
+	}
+	for idx, arg := range args {
+		if isContext(arg) {
+			return method, fmt.Errorf("Method %s.%s takes a context.Context that is not its first argument", iface.Name, m.Name)
+		}
+		field, err := b.messageField(arg.Name, arg, idx+1)
+		if err != nil {
+			return method, fmt.Errorf("Method %s.%s has an unsupported argument %s: %s", iface.Name, m.Name, arg.Name, err)
+		}
+		method.Request.Fields = append(method.Request.Fields, field)
+	}
+
+	results := m.OutputArgs
+	if len(results) == 0 || results[len(results)-1].TypeName != "error" {
+		return method, fmt.Errorf("Method %s.%s must return an error as its last result", iface.Name, m.Name)
+	}
+	results = results[:len(results)-1]
+
+	if method.IsStream {
+		if len(results) != 1 || !results[0].IsChannel {
+			return method, fmt.Errorf("Method %s.%s has a @GrpcStream but does not return a channel and an error", iface.Name, m.Name)
+		}
+		item := results[0]
+		item.IsChannel = false
+		field, err := b.messageField("item", item, 1)
+		if err != nil {
+			return method, fmt.Errorf("Method %s.%s streams an unsupported item: %s", iface.Name, m.Name, err)
+		}
+		method.Response.Fields = append(method.Response.Fields, field)
+		return method, nil
+	}
+
+	for idx, result := range results {
+		if result.IsChannel {
+			return method, fmt.Errorf("Method %s.%s returns a channel without a @GrpcStream", iface.Name, m.Name)
+		}
+		name := result.Name
+		if name == "" && len(results) == 1 {
+			name = "result"
+		} else if name == "" {
+			name = fmt.Sprintf("result%d", idx+1)
+		}
+		field, err := b.messageField(name, result, idx+1)
+		if err != nil {
+			return method, fmt.Errorf("Method %s.%s has an unsupported result: %s", iface.Name, m.Name, err)
+		}
+		method.Response.Fields = append(method.Response.Fields, field)
+	}
+	return method, nil
+}
+
+func (b *messageBuilder) messageField(name string, f model.Field, number int) (MessageField, error) {
+	field := MessageField{
+		Name:   toSnakeCase(name),
+		Number: number,
+		Field:  f,
+	}
+	field.GoName = goCamelCase(field.Name)
+
+	if f.IsMap || f.IsChannel || f.IsVariadic {
+		return field, fmt.Errorf("maps, channels and variadic arguments are not supported")
+	}
+	if f.PackageQualifier != "" {
+		return field, fmt.Errorf("type %s.%s of another package is not supported", f.PackageQualifier, f.TypeName)
+	}
+
+	if protoType, ok := b.typeMap["[]byte"]; ok && f.IsSlice && !f.IsPointer && f.TypeName == "byte" {
+		field.Type = protoType
+		field.PbGoType = scalarGoTypes[protoType]
+		return field, nil
+	}
+	if protoType, ok := b.typeMap[f.TypeName]; ok {
+		if f.IsPointer {
+			return field, fmt.Errorf("pointer to %s is not supported", f.TypeName)
+		}
+		field.Type = protoType
+		field.PbGoType = scalarGoTypes[protoType]
+		field.Repeated = f.IsSlice
+		return field, nil
+	}
+	if s, ok := b.structs[f.TypeName]; ok {
+		if f.IsSlice && f.IsPointer {
+			return field, fmt.Errorf("slice of pointers to %s is not supported", f.TypeName)
+		}
+		field.Type = s.Name
+		field.IsMessage = true
+		field.Repeated = f.IsSlice
+		return field, b.addStruct(s)
+	}
+	return field, fmt.Errorf("no proto-type for %s: add it to the type-map", f.TypeName)
+}
+
+// addStruct adds the message of the struct: the struct is marked before its fields are visited, so self-referencing
+// structs end the recursion
+func (b *messageBuilder) addStruct(s model.Struct) error {
+	if b.seen[s.Name] {
+		return nil
+	}
+	b.seen[s.Name] = true
+
+	message := Message{Name: s.Name}
+	for idx, f := range s.Fields {
+		field, err := b.messageField(fieldName(f), f, idx+1)
+		if err != nil {
+			return fmt.Errorf("field %s.%s: %s", s.Name, fieldName(f), err)
+		}
+		message.Fields = append(message.Fields, field)
+	}
+	b.messages = append(b.messages, message)
+	return nil
+}
+
+func validateMessageNames(messages []Message) error {
+	seen := map[string]bool{}
+	for _, m := range messages {
+		if seen[m.Name] {
+			return fmt.Errorf("Message %s is declared more than once: rename the struct or the method", m.Name)
+		}
+		seen[m.Name] = true
+	}
+	return nil
+}
+
+func isContext(f model.Field) bool {
+	return f.PackageQualifier == "context" && f.TypeName == "Context"
+}
+
+// fieldName returns the name of a struct-field: an embedded field is named after its type
+func fieldName(f model.Field) string {
+	if f.Name == "" {
+		return f.TypeName
+	}
+	return f.Name
+}
+
+// toSnakeCase converts a go-name like UserID into the proto-style user_id
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for idx, r := range runes {
+		if unicode.IsUpper(r) {
+			startsWord := idx > 0 && (!unicode.IsUpper(runes[idx-1]) ||
+				(idx+1 < len(runes) && unicode.IsLower(runes[idx+1])))
+			if startsWord && runes[idx-1] != '_' {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// goCamelCase returns the name that protoc-gen-go gives to the go-field of a proto-field: user_id becomes UserId
+func goCamelCase(name string) string {
+	var b []byte
+	for idx := 0; idx < len(name); idx++ {
+		c := name[idx]
+		switch {
+		case c == '_' && idx == 0:
+			b = append(b, 'X')
+		case c == '_' && idx+1 < len(name) && isASCIILower(name[idx+1]):
+			// the underscore is dropped: the next letter is capitalized
+		case '0' <= c && c <= '9':
+			b = append(b, c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; idx+1 < len(name) && isASCIILower(name[idx+1]); idx++ {
+				b = append(b, name[idx+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+func ToFirstUpper(in string) string {
+	if len(in) == 0 {
+		return in
+	}
+	return strings.ToUpper(in[0:1]) + in[1:]
+}
+
+func ToFirstLower(in string) string {
+	if len(in) == 0 {
+		return in
+	}
+	return strings.ToLower(in[0:1]) + in[1:]
+}
+
+// GetGoType returns the go-type of the argument or struct-field from which the field is derived
+func GetGoType(f MessageField) string {
+	goType := f.Field.TypeName
+	if f.Field.IsPointer {
+		goType = "*" + goType
+	}
+	if f.Field.IsSlice {
+		goType = "[]" + goType
+	}
+	return goType
+}
+
+// GetFieldName returns the name of the struct-field from which the field is derived
+func GetFieldName(f MessageField) string {
+	return fieldName(f.Field)
+}
+
+// GetArgVar returns the name of the local variable that holds an argument of the interface-method
+func GetArgVar(f MessageField) string {
+	return "arg" + f.GoName
+}
+
+// GetResultVar returns the name of the local variable that holds a result of the interface-method
+func GetResultVar(f MessageField) string {
+	return "result" + f.GoName
+}
+
+// ToProto returns the statement that converts the go-value src into the field dst of a proto-message
+func ToProto(dst string, src string, f MessageField) string {
+	convert := func(value string) string {
+		if f.IsMessage {
+			return fmt.Sprintf("%sToProto(%s)", ToFirstLower(f.Type), value)
+		}
+		return convertScalar(elementGoType(f), f.PbGoType, value)
+	}
+	switch {
+	case f.Repeated:
+		return fmt.Sprintf("for _, item := range %s {\n\t%s = append(%s, %s)\n}", src, dst, dst, convert("item"))
+	case f.IsMessage && f.Field.IsPointer:
+		return fmt.Sprintf("if %s != nil {\n\t%s = %s\n}", src, dst, convert("*"+src))
+	default:
+		return fmt.Sprintf("%s = %s", dst, convert(src))
+	}
+}
+
+// FromProto returns the statement that converts the field src of a proto-message into the go-value dst
+func FromProto(dst string, src string, f MessageField) string {
+	convert := func(value string) string {
+		if f.IsMessage {
+			return fmt.Sprintf("%sFromProto(%s)", ToFirstLower(f.Type), value)
+		}
+		return convertScalar(f.PbGoType, elementGoType(f), value)
+	}
+	switch {
+	case f.Repeated:
+		return fmt.Sprintf("for _, item := range %s {\n\t%s = append(%s, %s)\n}", src, dst, dst, convert("item"))
+	case f.IsMessage && f.Field.IsPointer:
+		return fmt.Sprintf("if %s != nil {\n\tvalue := %s\n\t%s = &value\n}", src, convert(src), dst)
+	default:
+		return fmt.Sprintf("%s = %s", dst, convert(src))
+	}
+}
+
+// elementGoType returns the go-type of a single value of a scalar field
+func elementGoType(f MessageField) string {
+	if f.Type == "bytes" && f.Field.IsSlice && f.Field.TypeName == "byte" {
+		return "[]byte"
+	}
+	return f.Field.TypeName
+}
+
+// convertScalar converts a value with a type conversion, when its type differs
+func convertScalar(fromType string, toType string, value string) string {
+	if fromType == toType {
+		return value
+	}
+	return fmt.Sprintf("%s(%s)", toType, value)
+}
+
+var customTemplateFuncs = template.FuncMap{
+	"ToFirstLower": ToFirstLower,
+	"GetGoType":    GetGoType,
+	"GetFieldName": GetFieldName,
+	"GetArgVar":    GetArgVar,
+	"GetResultVar": GetResultVar,
+	"ToProto":      ToProto,
+	"FromProto":    FromProto,
+}
+
+var protoTemplate string = `// Generated automatically: do not edit manually
+
+syntax = "proto3";
+
+package {{.ProtoPackage}};
+
+option go_package = "{{.GoPackage}}";
+{{range .Services}}
+service {{.Name}} {
+{{- range .Methods}}
+  rpc {{.Name}}({{.Request.Name}}) returns ({{if .IsStream}}stream {{end}}{{.Response.Name}});
+{{- end}}
+}
+{{end}}
+{{- range .Messages}}
+message {{.Name}} {
+{{- range .Fields}}
+  {{if .Repeated}}repeated {{end}}{{.Type}} {{.Name}} = {{.Number}};
+{{- end}}
+}
+{{end}}`
+
+var serverTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"context"
+
+	{{.PbPackageName}} "{{.PbImportPath}}"
+)
+
+{{ $service := .Name }}
+{{ $pb := .PbPackageName }}
+
+// {{.Name}}GrpcServer serves {{.Name}} over grpc: every rpc is delegated to the implementation
+type {{.Name}}GrpcServer struct {
+	{{$pb}}.Unimplemented{{.Name}}Server
+	impl {{.Name}}
+}
+
+func New{{.Name}}GrpcServer(impl {{.Name}}) *{{.Name}}GrpcServer {
+	return &{{.Name}}GrpcServer{impl: impl}
+}
+
+{{range .Methods}}
+{{if .IsStream}}
+// {{.Name}} sends every item of the channel as a response of its own, until the channel is closed
+func (server *{{$service}}GrpcServer) {{.Name}}(req *{{$pb}}.{{.Request.Name}}, stream {{$pb}}.{{$service}}_{{.Name}}Server) error {
+	{{range .Request.Fields}}
+		var {{GetArgVar .}} {{GetGoType .}}
+		{{FromProto (GetArgVar .) (printf "req.%s" .GoName) .}}
+	{{end}}
+	items, err := server.impl.{{.GoName}}({{if .HasContext}}stream.Context(), {{end}}{{range .Request.Fields}}{{GetArgVar .}}, {{end}})
+	if err != nil {
+		return grpcError(err)
+	}
+	for item := range items {
+		resp := &{{$pb}}.{{.Response.Name}}{}
+		{{range .Response.Fields}}
+			{{ToProto (printf "resp.%s" .GoName) "item" .}}
+		{{end}}
+		err = stream.Send(resp)
+		if err != nil {
+			// keep receiving until the channel is closed, so an implementation that ignores the context does not
+			// block forever
+			go func() {
+				for range items {
+				}
+			}()
+			return err
+		}
+	}
+	return nil
+}
+{{else}}
+func (server *{{$service}}GrpcServer) {{.Name}}(ctx context.Context, req *{{$pb}}.{{.Request.Name}}) (*{{$pb}}.{{.Response.Name}}, error) {
+	{{range .Request.Fields}}
+		var {{GetArgVar .}} {{GetGoType .}}
+		{{FromProto (GetArgVar .) (printf "req.%s" .GoName) .}}
+	{{end}}
+	{{range .Response.Fields}}{{GetResultVar .}}, {{end}}err := server.impl.{{.GoName}}({{if .HasContext}}ctx, {{end}}{{range .Request.Fields}}{{GetArgVar .}}, {{end}})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &{{$pb}}.{{.Response.Name}}{}
+	{{range .Response.Fields}}
+		{{ToProto (printf "resp.%s" .GoName) (GetResultVar .) .}}
+	{{end}}
+	return resp, nil
+}
+{{end}}
+{{end}}
+`
+
+var helpersTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"github.com/MarcGrol/microgen/lib/myerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+{{if .Messages}}
+	{{.PbPackageName}} "{{.PbImportPath}}"
+{{end}}
+)
+
+{{ $pb := .PbPackageName }}
+
+// grpcError reports the error with the grpc status-code that matches its kind
+func grpcError(err error) error {
+	if myerrors.IsNotFoundError(err) {
+		return status.Error(codes.NotFound, err.Error())
+	} else if myerrors.IsInternalError(err) {
+		return status.Error(codes.Internal, err.Error())
+	} else if myerrors.IsInvalidInputError(err) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	} else if myerrors.IsNotAuthorizedError(err) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	} else {
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+{{range .Messages}}
+// {{ToFirstLower .Name}}ToProto converts a {{.Name}} into its message
+func {{ToFirstLower .Name}}ToProto(in {{.Name}}) *{{$pb}}.{{.Name}} {
+	out := &{{$pb}}.{{.Name}}{}
+	{{range .Fields}}
+		{{ToProto (printf "out.%s" .GoName) (printf "in.%s" (GetFieldName .)) .}}
+	{{end}}
+	return out
+}
+
+// {{ToFirstLower .Name}}FromProto converts the message into a {{.Name}}: a nil-message results in the zero-value
+func {{ToFirstLower .Name}}FromProto(in *{{$pb}}.{{.Name}}) {{.Name}} {
+	out := {{.Name}}{}
+	if in == nil {
+		return out
+	}
+	{{range .Fields}}
+		{{FromProto (printf "out.%s" (GetFieldName .)) (printf "in.%s" .GoName) .}}
+	{{end}}
+	return out
+}
+{{end}}
+`
@@ -0,0 +1,203 @@
+package grpc
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func userInterfaces() []model.Interface {
+	return []model.Interface{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @GrpcService( protoPackage = "myapp.v1" )`},
+			Name:        "UserService",
+			Methods: []model.Operation{
+				{
+					PackageName: "testData",
+					Name:        "GetUser",
+					InputArgs:   []model.Field{{Name: "ctx", TypeName: "Context", PackageQualifier: "context"}, {Name: "userID", TypeName: "int"}},
+					OutputArgs:  []model.Field{{TypeName: "User", IsPointer: true}, {TypeName: "error"}},
+				},
+				{
+					PackageName: "testData",
+					Name:        "CreateUser",
+					InputArgs:   []model.Field{{Name: "ctx", TypeName: "Context", PackageQualifier: "context"}, {Name: "user", TypeName: "User"}},
+					OutputArgs:  []model.Field{{TypeName: "error"}},
+				},
+				{
+					PackageName: "testData",
+					DocLines:    []string{`// @GrpcStream( direction = "server" )`},
+					Name:        "ListUsers",
+					InputArgs:   []model.Field{{Name: "names", TypeName: "string", IsSlice: true}},
+					OutputArgs:  []model.Field{{TypeName: "User", IsChannel: true}, {TypeName: "error"}},
+				},
+			},
+		},
+	}
+}
+
+func userStructs() []model.Struct {
+	return []model.Struct{
+		{
+			PackageName: "testData",
+			Name:        "User",
+			Fields: []model.Field{
+				{Name: "UserID", TypeName: "int"},
+				{Name: "Name", TypeName: "string"},
+				{Name: "Status", TypeName: "Status"},
+				{Name: "Avatar", TypeName: "byte", IsSlice: true},
+				{Name: "Address", TypeName: "Address", IsPointer: true},
+				{Name: "Tags", TypeName: "string", IsSlice: true},
+			},
+		},
+		{
+			PackageName: "testData",
+			Name:        "Address",
+			Fields: []model.Field{
+				{Name: "City", TypeName: "string"},
+			},
+		},
+	}
+}
+
+func cleanup() {
+	os.RemoveAll("./testData")
+}
+
+func TestGenerateForGrpc(t *testing.T) {
+	cleanup()
+	defer cleanup()
+
+	typeMapFile := writeTypeMap(t, `{"Status": "string"}`)
+	defer os.Remove(typeMapFile)
+
+	err := Generate("testData", userInterfaces(), userStructs(), typeMapFile)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/testDatapb/testData.proto")
+	assert.NoError(t, err)
+	proto := string(data)
+	assert.Contains(t, proto, `package myapp.v1;`)
+	assert.Contains(t, proto, `option go_package = "github.com/MarcGrol/golangAnnotations/generator/grpc/testData/testDatapb";`)
+	assert.Contains(t, proto, "service UserService {\n"+
+		"  rpc GetUser(GetUserRequest) returns (GetUserResponse);\n"+
+		"  rpc CreateUser(CreateUserRequest) returns (CreateUserResponse);\n"+
+		"  rpc ListUsers(ListUsersRequest) returns (stream ListUsersResponse);\n"+
+		"}")
+	assert.Contains(t, proto, "message GetUserRequest {\n  int64 user_id = 1;\n}")
+	assert.Contains(t, proto, "message GetUserResponse {\n  User result = 1;\n}")
+	assert.Contains(t, proto, "message CreateUserResponse {\n}")
+	assert.Contains(t, proto, "message ListUsersRequest {\n  repeated string names = 1;\n}")
+	assert.Contains(t, proto, "message ListUsersResponse {\n  User item = 1;\n}")
+	assert.Contains(t, proto, "message User {\n"+
+		"  int64 user_id = 1;\n"+
+		"  string name = 2;\n"+
+		"  string status = 3;\n"+
+		"  bytes avatar = 4;\n"+
+		"  Address address = 5;\n"+
+		"  repeated string tags = 6;\n"+
+		"}")
+	assert.Contains(t, proto, "message Address {\n  string city = 1;\n}")
+
+	data, err = ioutil.ReadFile("./testData/grpcUserServiceServer.go")
+	assert.NoError(t, err)
+	server := string(data)
+	assert.Contains(t, server, `testDatapb "github.com/MarcGrol/golangAnnotations/generator/grpc/testData/testDatapb"`)
+	assert.Contains(t, server, "testDatapb.UnimplementedUserServiceServer\n\timpl UserService")
+	assert.Contains(t, server, "func NewUserServiceGrpcServer(impl UserService) *UserServiceGrpcServer {")
+
+	// a unary method
+	assert.Contains(t, server, "func (server *UserServiceGrpcServer) GetUser(ctx context.Context, req *testDatapb.GetUserRequest) (*testDatapb.GetUserResponse, error) {")
+	assert.Contains(t, server, "argUserId = int(req.UserId)")
+	assert.Contains(t, server, "resultResult, err := server.impl.GetUser(ctx, argUserId, )")
+	assert.Contains(t, server, "if resultResult != nil {\n\tresp.Result = userToProto(*resultResult)\n}")
+
+	// a method with a struct argument
+	assert.Contains(t, server, "var argUser User")
+	assert.Contains(t, server, "argUser = userFromProto(req.User)")
+	assert.Contains(t, server, "err := server.impl.CreateUser(ctx, argUser, )")
+
+	// a server-streaming method
+	assert.Contains(t, server, "func (server *UserServiceGrpcServer) ListUsers(req *testDatapb.ListUsersRequest, stream testDatapb.UserService_ListUsersServer) error {")
+	assert.Contains(t, server, "for _, item := range req.Names {\n\targNames = append(argNames, item)\n}")
+	assert.Contains(t, server, "items, err := server.impl.ListUsers(argNames, )")
+	assert.Contains(t, server, "resp.Item = userToProto(item)")
+	assert.Contains(t, server, "err = stream.Send(resp)")
+	assert.Contains(t, server, "go func() {\n\t\t\t\tfor range items {\n\t\t\t\t}\n\t\t\t}()\n\t\t\treturn err")
+
+	data, err = ioutil.ReadFile("./testData/grpcHelpers.go")
+	assert.NoError(t, err)
+	helpers := string(data)
+	assert.Contains(t, helpers, "return status.Error(codes.NotFound, err.Error())")
+	assert.Contains(t, helpers, "func userToProto(in User) *testDatapb.User {")
+	assert.Contains(t, helpers, "out.UserId = int64(in.UserID)")
+	assert.Contains(t, helpers, "out.Status = string(in.Status)")
+	assert.Contains(t, helpers, "out.Avatar = in.Avatar")
+	assert.Contains(t, helpers, "if in.Address != nil {\n\tout.Address = addressToProto(*in.Address)\n}")
+	assert.Contains(t, helpers, "func userFromProto(in *testDatapb.User) User {")
+	assert.Contains(t, helpers, "out.UserID = int(in.UserId)")
+	assert.Contains(t, helpers, "out.Status = Status(in.Status)")
+	assert.Contains(t, helpers, "if in.Address != nil {\n\tvalue := addressFromProto(in.Address)\n\tout.Address = &value\n}")
+}
+
+func TestGenerateForGrpcWithoutServices(t *testing.T) {
+	cleanup()
+	defer cleanup()
+
+	err := Generate("testData", []model.Interface{{PackageName: "testData", Name: "Plain"}}, userStructs(), "")
+	assert.Nil(t, err)
+
+	_, err = os.Stat("./testData")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGenerateForGrpcWithUnmappedType(t *testing.T) {
+	cleanup()
+	defer cleanup()
+
+	// Status is not in the default type-map
+	err := Generate("testData", userInterfaces(), userStructs(), "")
+	assert.EqualError(t, err, "Method UserService.GetUser has an unsupported result: field User.Status: no proto-type for Status: add it to the type-map")
+}
+
+func TestGenerateForGrpcWithInvalidStream(t *testing.T) {
+	interfaces := userInterfaces()
+	interfaces[0].Methods[2].OutputArgs = []model.Field{{TypeName: "User", IsSlice: true}, {TypeName: "error"}}
+
+	typeMapFile := writeTypeMap(t, `{"Status": "string"}`)
+	defer os.Remove(typeMapFile)
+
+	err := Generate("testData", interfaces, userStructs(), typeMapFile)
+	assert.EqualError(t, err, "Method UserService.ListUsers has a @GrpcStream but does not return a channel and an error")
+}
+
+func TestInvalidTypeMap(t *testing.T) {
+	typeMapFile := writeTypeMap(t, `{"Status": "varchar"}`)
+	defer os.Remove(typeMapFile)
+
+	_, err := loadTypeMap(typeMapFile)
+	assert.EqualError(t, err, "Type-map "+typeMapFile+" maps Status onto varchar, which is not a scalar proto-type")
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, "user_id", toSnakeCase("UserID"))
+	assert.Equal(t, "user_id", toSnakeCase("userID"))
+	assert.Equal(t, "http_server", toSnakeCase("HTTPServer"))
+	assert.Equal(t, "name", toSnakeCase("name"))
+	assert.Equal(t, "UserId", goCamelCase("user_id"))
+	assert.Equal(t, "HttpServer2", goCamelCase("http_server2"))
+	assert.Equal(t, "Item", goCamelCase("item"))
+}
+
+func writeTypeMap(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "typeMap*.json")
+	assert.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(content)
+	assert.NoError(t, err)
+	return f.Name()
+}
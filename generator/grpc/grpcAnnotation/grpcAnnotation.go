@@ -0,0 +1,34 @@
+package grpcAnnotation
+
+import "github.com/MarcGrol/golangAnnotations/annotation"
+
+const (
+	typeGrpcService   = "GrpcService"
+	typeGrpcStream    = "GrpcStream"
+	paramProtoPackage = "protopackage"
+	paramDirection    = "direction"
+)
+
+// Register makes the annotation-registry aware of these annotations
+func Register() {
+	RegisterIn(annotation.DefaultRegistry)
+}
+
+// RegisterIn makes the given registry aware of these annotations
+func RegisterIn(registry *annotation.Registry) {
+	registry.Register(typeGrpcService, []string{paramProtoPackage}, validateGrpcServiceAnnotation)
+	registry.Register(typeGrpcStream, []string{paramDirection}, validateGrpcStreamAnnotation)
+}
+
+func validateGrpcServiceAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeGrpcService {
+		val, hasPackage := annot.Attributes[paramProtoPackage]
+		return hasPackage && val != ""
+	}
+	return false
+}
+
+func validateGrpcStreamAnnotation(annot annotation.Annotation) bool {
+	// only server-streaming is supported: the interface-method returns a channel of items
+	return annot.Name == typeGrpcStream && annot.Attributes[paramDirection] == "server"
+}
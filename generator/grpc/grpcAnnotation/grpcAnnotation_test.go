@@ -0,0 +1,36 @@
+package grpcAnnotation
+
+import (
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectGrpcServiceAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	annot, ok := annotation.ResolveAnnotations([]string{`// @GrpcService( protoPackage = "myapp.v1" )`})
+	assert.True(t, ok)
+	assert.Equal(t, "myapp.v1", annot.Attributes["protopackage"])
+}
+
+func TestIncompleteGrpcServiceAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @GrpcService()`})
+	assert.False(t, ok)
+}
+
+func TestGrpcStreamAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @GrpcStream( direction = "server" )`})
+	assert.True(t, ok)
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @GrpcStream( direction = "bidi" )`})
+	assert.False(t, ok)
+}
@@ -0,0 +1,18 @@
+package lambda
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+func Generate(inputDir string, structs []model.Struct) error {
+	return DefaultGenerator.Generate(inputDir, structs)
+}
+
+func IsLambda(s model.Struct) bool {
+	return DefaultGenerator.IsLambda(s)
+}
+
+// GetFunctionName returns the name of the lambda-function: defaults to the name of the service
+func GetFunctionName(s model.Struct) string {
+	return DefaultGenerator.GetFunctionName(s)
+}
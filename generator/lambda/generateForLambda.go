@@ -0,0 +1,116 @@
+package lambda
+
+import (
+	"fmt"
+	"log"
+	"text/template"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/generator/lambda/lambdaAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/rest"
+	"github.com/MarcGrol/golangAnnotations/generator/rest/restAnnotation"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+type Structs struct {
+	PackageName string
+	Structs     []model.Struct
+}
+
+// Generator generates lambda-handlers for services annotated with @Lambda, resolving the annotations in its registry
+type Generator struct {
+	registry *annotation.Registry
+	rest     *rest.Generator
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry, rest: rest.NewGenerator(registry)}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+func (g *Generator) Generate(inputDir string, structs []model.Struct) error {
+	lambdaAnnotation.RegisterIn(g.registry)
+	restAnnotation.RegisterIn(g.registry)
+
+	packageName, err := generationUtil.GetPackageName(structs)
+	if err != nil {
+		return err
+	}
+
+	lambdaCount := 0
+	for _, s := range structs {
+		if g.IsLambda(s) {
+			if !g.rest.IsRestService(s) {
+				return fmt.Errorf("Struct %s is annotated with @Lambda but is not a @RestService", s.Name)
+			}
+			lambdaCount++
+		}
+	}
+
+	if lambdaCount > 0 {
+		targetDir, err := generationUtil.DetermineTargetPath(inputDir, packageName)
+		if err != nil {
+			return err
+		}
+		target := fmt.Sprintf("%s/adapter.go", targetDir)
+
+		data := Structs{
+			PackageName: packageName,
+			Structs:     structs,
+		}
+		err = generationUtil.GenerateFileFromTemplate(data, "lambda", lambdaTemplate, g.templateFuncs(), target)
+		if err != nil {
+			log.Fatalf("Error generating lambda adapters for structs (%s)", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Generator) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"IsLambda":        g.IsLambda,
+		"GetFunctionName": g.GetFunctionName,
+	}
+}
+
+func (g *Generator) IsLambda(s model.Struct) bool {
+	_, ok := g.registry.ResolveAnnotationByName(s.DocLines, "Lambda")
+	return ok
+}
+
+// GetFunctionName returns the name of the lambda-function: defaults to the name of the service
+func (g *Generator) GetFunctionName(s model.Struct) string {
+	val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "Lambda")
+	if ok && val.Attributes["name"] != "" {
+		return val.Attributes["name"]
+	}
+	return s.Name
+}
+
+var lambdaTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
+)
+
+{{range .Structs}}
+{{if IsLambda . }}
+
+// {{.Name}}FunctionName is the name of the lambda-function that serves {{.Name}}
+const {{.Name}}FunctionName = "{{GetFunctionName . }}"
+
+// StartLambda serves the generated http-handler of {{.Name}} as a lambda-function behind api-gateway
+func (ts *{{.Name}}) StartLambda() {
+	lambda.Start(httpadapter.New(ts.HttpHandler()).ProxyWithContext)
+}
+{{end}}
+{{end}}
+`
@@ -0,0 +1,63 @@
+package lambda
+
+import (
+	"os"
+	"testing"
+
+	"io/ioutil"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateForLambda(t *testing.T) {
+	os.Remove("./testData/adapter.go")
+
+	s := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines: []string{
+				`// @Lambda()`,
+				`// @RestService( path = "/api" )`,
+			},
+			Name: "MyService",
+		},
+		{
+			PackageName: "testData",
+			DocLines: []string{
+				`// @Lambda( name = "MyFunction" )`,
+				`// @RestService( path = "/other" )`,
+			},
+			Name: "OtherService",
+		},
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @RestService( path = "/plain" )`},
+			Name:        "PlainService",
+		},
+	}
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/adapter.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `const MyServiceFunctionName = "MyService"`)
+	assert.Contains(t, string(data), `const OtherServiceFunctionName = "MyFunction"`)
+	assert.Contains(t, string(data), "func (ts *MyService) StartLambda() {")
+	assert.Contains(t, string(data), "lambda.Start(httpadapter.New(ts.HttpHandler()).ProxyWithContext)")
+	assert.NotContains(t, string(data), "PlainService")
+
+	os.Remove("./testData/adapter.go")
+}
+
+func TestGenerateForLambdaWithoutRestService(t *testing.T) {
+	s := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @Lambda()`},
+			Name:        "MyService",
+		},
+	}
+	err := Generate("testData", s)
+	assert.Error(t, err)
+}
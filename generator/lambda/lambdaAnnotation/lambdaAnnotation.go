@@ -0,0 +1,22 @@
+package lambdaAnnotation
+
+import "github.com/MarcGrol/golangAnnotations/annotation"
+
+const (
+	typeLambda = "Lambda"
+	paramName  = "name"
+)
+
+// Register makes the annotation-registry aware of this annotation
+func Register() {
+	RegisterIn(annotation.DefaultRegistry)
+}
+
+// RegisterIn makes the given registry aware of this annotation
+func RegisterIn(registry *annotation.Registry) {
+	registry.Register(typeLambda, []string{paramName}, validateLambdaAnnotation)
+}
+
+func validateLambdaAnnotation(annot annotation.Annotation) bool {
+	return annot.Name == typeLambda
+}
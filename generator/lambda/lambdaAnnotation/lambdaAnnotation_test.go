@@ -0,0 +1,34 @@
+package lambdaAnnotation
+
+import (
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectLambdaAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	annot, ok := annotation.ResolveAnnotations([]string{`// @Lambda()`})
+	assert.True(t, ok)
+	assert.Equal(t, "Lambda", annot.Name)
+}
+
+func TestCorrectLambdaAnnotationWithName(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	annot, ok := annotation.ResolveAnnotations([]string{`// @Lambda( name = "MyFunction" )`})
+	assert.True(t, ok)
+	assert.Equal(t, "MyFunction", annot.Attributes["name"])
+}
+
+func TestOtherAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @Event( aggregate = "test" )`})
+	assert.False(t, ok)
+}
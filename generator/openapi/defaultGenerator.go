@@ -0,0 +1,11 @@
+package openapi
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+// Generate writes an openapi-document describing the rest-operations of all rest-services to the target-file. Nothing
+// is written when the package has no rest-services.
+func Generate(structs []model.Struct, config Config, target string) error {
+	return DefaultGenerator.Generate(structs, config, target)
+}
@@ -0,0 +1,519 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/generator/rest"
+	"github.com/MarcGrol/golangAnnotations/generator/rest/restAnnotation"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+const (
+	openAPIVersion = "3.0.3"
+	defaultVersion = "1.0.0"
+	errorSchema    = "Error"
+	problemSchema  = "Problem"
+)
+
+// Config describes the api as a whole: the title defaults to the name of the package
+type Config struct {
+	Title     string
+	Version   string
+	ServerURL string
+}
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Servers    []Server                        `json:"servers,omitempty"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Tags        []string              `json:"tags,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how an operation with an @APIKey or an @OAuth2 is authenticated
+type SecurityScheme struct {
+	Type  string      `json:"type"`
+	Name  string      `json:"name,omitempty"`
+	In    string      `json:"in,omitempty"`
+	Flows *OAuthFlows `json:"flows,omitempty"`
+}
+
+type OAuthFlows struct {
+	AuthorizationCode *OAuthFlow `json:"authorizationCode"`
+}
+
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl"`
+	TokenURL         string            `json:"tokenUrl"`
+	Scopes           map[string]string `json:"scopes"`
+}
+
+// Schema is the subset of the json-schema of openapi that go-types map onto
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}:]+)(?::[^}]+)?\}`)
+
+// schemeNamePattern matches the characters that are not allowed in the name of a component
+var schemeNamePattern = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// Generator generates an OpenAPI-spec from the rest-annotations in its registry
+type Generator struct {
+	registry *annotation.Registry
+	rest     *rest.Generator
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry, rest: rest.NewGenerator(registry)}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+// Generate writes an openapi-document describing the rest-operations of all rest-services to the target-file. Nothing
+// is written when the package has no rest-services.
+func (g *Generator) Generate(structs []model.Struct, config Config, target string) error {
+	restAnnotation.RegisterIn(g.registry)
+
+	packageName, err := generationUtil.GetPackageName(structs)
+	if err != nil {
+		return err
+	}
+	doc, found := g.buildDocument(packageName, structs, config)
+	if !found {
+		return nil
+	}
+
+	log.Printf("Using json to generate target %s\n", target)
+	blob, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(target), 0777)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(target, append(blob, '\n'), 0644)
+}
+
+func (g *Generator) buildDocument(packageName string, structs []model.Struct, config Config) (Document, bool) {
+	doc := Document{
+		OpenAPI: openAPIVersion,
+		Info:    Info{Title: config.Title, Version: config.Version},
+		Paths:   map[string]map[string]Operation{},
+	}
+	if doc.Info.Title == "" {
+		doc.Info.Title = packageName
+	}
+	if doc.Info.Version == "" {
+		doc.Info.Version = defaultVersion
+	}
+	if config.ServerURL != "" {
+		doc.Servers = []Server{{URL: config.ServerURL}}
+	}
+
+	schemas := newSchemaBuilder(structs)
+	security := newSecurityBuilder()
+	found := false
+	for _, s := range structs {
+		if !g.rest.IsRestService(s) {
+			continue
+		}
+		found = true
+		prefix := g.rest.GetRestServicePrefix(s, structs)
+		for _, o := range s.Operations {
+			if !g.rest.IsRestOperation(*o) {
+				continue
+			}
+			path := pathParamPattern.ReplaceAllString(prefix+g.rest.GetRestOperationPath(*o), "{$1}")
+			if doc.Paths[path] == nil {
+				doc.Paths[path] = map[string]Operation{}
+			}
+			operation := g.buildOperation(s, *o, schemas)
+			operation.Security = g.securityOf(*o, security)
+			doc.Paths[path][strings.ToLower(g.rest.GetRestOperationMethod(*o))] = operation
+		}
+	}
+	doc.Components.Schemas = schemas.components
+	if len(security.schemes) > 0 {
+		doc.Components.SecuritySchemes = security.schemes
+	}
+	return doc, found
+}
+
+func (g *Generator) buildOperation(s model.Struct, o model.Operation, schemas *schemaBuilder) Operation {
+	operation := Operation{
+		OperationID: o.Name,
+		Tags:        []string{s.Name},
+		Description: o.Description,
+		Deprecated:  g.rest.IsDeprecated(o),
+		Responses:   map[string]Response{},
+	}
+	for _, arg := range o.InputArgs {
+		if rest.IsPrimitive(arg) {
+			operation.Parameters = append(operation.Parameters, Parameter{
+				Name:     arg.Name,
+				In:       "path",
+				Required: true,
+				Schema:   schemas.schemaOf(arg),
+			})
+		}
+	}
+	if g.rest.HasInput(o) && rest.GetInputArgType(o) != "" {
+		for _, arg := range o.InputArgs {
+			if arg.Name == rest.GetInputArgName(o) {
+				operation.RequestBody = &RequestBody{
+					Required: true,
+					Content:  map[string]MediaType{"application/json": {Schema: schemas.schemaOf(arg)}},
+				}
+			}
+		}
+	}
+
+	output, hasOutput := g.responseOf(o, schemas.structs)
+	switch {
+	case hasOutput && g.rest.IsStreamResponse(o):
+		// every line of the response is a single item of the channel
+		operation.Responses["200"] = Response{
+			Description: "Stream of items",
+			Content:     map[string]MediaType{g.rest.GetStreamContentType(o): {Schema: schemas.schemaOf(output)}},
+		}
+	case hasOutput:
+		operation.Responses["200"] = Response{
+			Description: "OK",
+			Content:     map[string]MediaType{"application/json": {Schema: schemas.schemaOf(output)}},
+		}
+	default:
+		operation.Responses["204"] = Response{Description: "No Content"}
+	}
+
+	if g.rest.UsesProblemDetails(s, o) {
+		operation.Responses["default"] = Response{
+			Description: "Problem",
+			Content:     map[string]MediaType{"application/problem+json": {Schema: schemas.problem()}},
+		}
+	} else {
+		operation.Responses["default"] = Response{
+			Description: "Error",
+			Content:     map[string]MediaType{"application/json": {Schema: schemas.error()}},
+		}
+	}
+	return operation
+}
+
+// responseOf returns the type of the response body: the responseField of the returned struct when it has one
+func (g *Generator) responseOf(o model.Operation, structs []model.Struct) (model.Field, bool) {
+	for _, arg := range o.OutputArgs {
+		if arg.TypeName == "error" {
+			continue
+		}
+		if name := g.rest.GetResponseField(o); name != "" {
+			for _, s := range structs {
+				if s.Name != arg.TypeName {
+					continue
+				}
+				for _, f := range s.Fields {
+					if f.Name == name && !f.IsEmbedded {
+						return f, true
+					}
+				}
+			}
+		}
+		return arg, true
+	}
+	return model.Field{}, false
+}
+
+// securityOf returns the security-requirement of the operation: a single requirement listing all its schemes, because
+// the generated handler checks both the api-key and the bearer-token of an operation that has them
+func (g *Generator) securityOf(o model.Operation, security *securityBuilder) []map[string][]string {
+	requirement := map[string][]string{}
+	if g.rest.HasAPIKey(o) {
+		in := "header"
+		if g.rest.IsAPIKeyInQuery(o) {
+			in = "query"
+		}
+		requirement[security.apiKey(in, g.rest.GetAPIKeyName(o))] = []string{}
+	}
+	if val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "OAuth2"); ok {
+		scopes := strings.Fields(val.Attributes["scopes"])
+		requirement[security.oauth2(val.Attributes["authorizationurl"], val.Attributes["tokenurl"], scopes)] = scopes
+	}
+	if len(requirement) == 0 {
+		return nil
+	}
+	return []map[string][]string{requirement}
+}
+
+// securityBuilder describes every distinct api-key and oauth2-flow of the operations as a security-scheme
+type securityBuilder struct {
+	schemes map[string]*SecurityScheme
+	names   map[string]string
+}
+
+func newSecurityBuilder() *securityBuilder {
+	return &securityBuilder{schemes: map[string]*SecurityScheme{}, names: map[string]string{}}
+}
+
+// apiKey returns the name of the scheme of an api-key, like apiKey-header-X-API-Key
+func (b *securityBuilder) apiKey(in string, name string) string {
+	schemeName := schemeNamePattern.ReplaceAllString(fmt.Sprintf("apiKey-%s-%s", in, name), "_")
+	if _, found := b.schemes[schemeName]; !found {
+		b.schemes[schemeName] = &SecurityScheme{Type: "apiKey", Name: name, In: in}
+	}
+	return schemeName
+}
+
+// oauth2 returns the name of the scheme of an oauth2-flow: oauth2, followed by a number when the operations use
+// several authorization-servers. The scopes are added to the scopes of the flow.
+func (b *securityBuilder) oauth2(authorizationURL string, tokenURL string, scopes []string) string {
+	key := authorizationURL + " " + tokenURL
+	schemeName, found := b.names[key]
+	if !found {
+		schemeName = "oauth2"
+		if len(b.names) > 0 {
+			schemeName = fmt.Sprintf("oauth2-%d", len(b.names)+1)
+		}
+		b.names[key] = schemeName
+		b.schemes[schemeName] = &SecurityScheme{
+			Type: "oauth2",
+			Flows: &OAuthFlows{AuthorizationCode: &OAuthFlow{
+				AuthorizationURL: authorizationURL,
+				TokenURL:         tokenURL,
+				Scopes:           map[string]string{},
+			}},
+		}
+	}
+	for _, scope := range scopes {
+		b.schemes[schemeName].Flows.AuthorizationCode.Scopes[scope] = ""
+	}
+	return schemeName
+}
+
+// schemaBuilder describes the structs of the package as reusable component-schemas, the first time they are used
+type schemaBuilder struct {
+	structs    []model.Struct
+	components map[string]*Schema
+}
+
+func newSchemaBuilder(structs []model.Struct) *schemaBuilder {
+	return &schemaBuilder{structs: structs, components: map[string]*Schema{}}
+}
+
+func (b *schemaBuilder) schemaOf(f model.Field) *Schema {
+	var schema *Schema
+	if f.IsMap {
+		value := b.schemaOfType(f.MapValuePackage, f.MapValueTypeName)
+		if f.MapValueIsPointer {
+			value = nullable(value)
+		}
+		schema = &Schema{Type: "object", AdditionalProperties: value}
+	} else if f.TypeName == "byte" && f.IsSlice && f.PackageQualifier == "" {
+		// encoding/json encodes a byte-slice as a base64-string
+		return &Schema{Type: "string", Format: "byte"}
+	} else {
+		schema = b.schemaOfType(f.PackageQualifier, f.TypeName)
+	}
+	if f.IsPointer {
+		schema = nullable(schema)
+	}
+	if f.IsSlice || f.IsVariadic {
+		schema = &Schema{Type: "array", Items: schema}
+	}
+	return schema
+}
+
+// nullable marks the schema as nullable: a reference can have no siblings, so it is wrapped
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+	}
+	copied := *schema
+	copied.Nullable = true
+	return &copied
+}
+
+func (b *schemaBuilder) schemaOfType(packageQualifier string, typeName string) *Schema {
+	if packageQualifier == "time" && typeName == "Time" {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if packageQualifier != "" {
+		// types of other packages are not known: anything goes
+		return &Schema{}
+	}
+	switch typeName {
+	case "string":
+		return &Schema{Type: "string"}
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "byte", "rune":
+		return &Schema{Type: "integer", Format: "int32"}
+	case "int64", "uint64":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float32":
+		return &Schema{Type: "number", Format: "float"}
+	case "float64":
+		return &Schema{Type: "number", Format: "double"}
+	}
+	for _, s := range b.structs {
+		if s.Name == typeName {
+			return b.ref(s)
+		}
+	}
+	return &Schema{}
+}
+
+func (b *schemaBuilder) ref(s model.Struct) *Schema {
+	ref := &Schema{Ref: "#/components/schemas/" + s.Name}
+	if _, found := b.components[s.Name]; found {
+		return ref
+	}
+	// register before describing the fields, so self-referencing structs end the recursion
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.components[s.Name] = schema
+	b.addProperties(schema, s, map[string]bool{})
+	sort.Strings(schema.Required)
+	return ref
+}
+
+// addProperties adds the fields of the struct as named by encoding/json: the fields of embedded structs are promoted
+func (b *schemaBuilder) addProperties(schema *Schema, s model.Struct, visited map[string]bool) {
+	if visited[s.Name] {
+		return
+	}
+	visited[s.Name] = true
+	for _, f := range s.Fields {
+		name, omitEmpty, skip := jsonName(f)
+		if skip {
+			continue
+		}
+		if f.IsEmbedded && f.TagGet("json") == "" && f.PackageQualifier == "" {
+			if embedded, found := b.findStruct(f.TypeName); found {
+				b.addProperties(schema, embedded, visited)
+				continue
+			}
+		}
+		schema.Properties[name] = b.schemaOf(f)
+		if !omitEmpty && !f.IsPointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func (b *schemaBuilder) findStruct(name string) (model.Struct, bool) {
+	for _, s := range b.structs {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return model.Struct{}, false
+}
+
+// jsonName returns the name of the property of a field, according to its json-tag
+func jsonName(f model.Field) (string, bool, bool) {
+	tag := f.TagGet("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	omitEmpty := false
+	for _, option := range parts[1:] {
+		omitEmpty = omitEmpty || option == "omitempty"
+	}
+	return name, omitEmpty, false
+}
+
+// error describes the body that the generated handlers respond with on errors
+func (b *schemaBuilder) error() *Schema {
+	b.components[errorSchema] = &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"ErrorMessage": {Type: "string"}},
+		Required:   []string{"ErrorMessage"},
+	}
+	return &Schema{Ref: "#/components/schemas/" + errorSchema}
+}
+
+// problem describes the problem details (RFC 7807) that operations with @ProblemDetails respond with on errors
+func (b *schemaBuilder) problem() *Schema {
+	b.components[problemSchema] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"type":     {Type: "string"},
+			"title":    {Type: "string"},
+			"status":   {Type: "integer", Format: "int32"},
+			"detail":   {Type: "string"},
+			"instance": {Type: "string"},
+		},
+		Required: []string{"status", "title", "type"},
+	}
+	return &Schema{Ref: "#/components/schemas/" + problemSchema}
+}
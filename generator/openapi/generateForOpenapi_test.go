@@ -0,0 +1,232 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func tourStructs() []model.Struct {
+	return []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @RestService( path = "/api")`},
+			Name:        "TourService",
+			Operations: []*model.Operation{
+				{
+					PackageName: "testData",
+					DocLines:    []string{`// @RestOperation( method = "GET", path = "/tour/{year:[0-9]+}" )`},
+					Name:        "getTour",
+					Description: "Returns the tour of a year",
+					InputArgs:   []model.Field{{Name: "year", TypeName: "int"}},
+					OutputArgs:  []model.Field{{TypeName: "Tour", IsPointer: true}, {TypeName: "error"}},
+				},
+				{
+					PackageName: "testData",
+					DocLines:    []string{`// @RestOperation( method = "GET", path = "/tour" )`},
+					Name:        "getTours",
+					OutputArgs:  []model.Field{{TypeName: "Tour", IsSlice: true}, {TypeName: "error"}},
+				},
+				{
+					PackageName: "testData",
+					DocLines:    []string{`// @RestOperation( method = "POST", path = "/tour/{year}/etappe" )`},
+					Name:        "createEtappe",
+					InputArgs:   []model.Field{{Name: "year", TypeName: "int"}, {Name: "etappe", TypeName: "Etappe"}},
+					OutputArgs:  []model.Field{{TypeName: "Etappe"}, {TypeName: "error"}},
+				},
+				{
+					PackageName: "testData",
+					DocLines:    []string{`// @RestOperation( method = "DELETE", path = "/tour/{year}" )`, `// @Deprecated( replacedBy = "none" )`},
+					Name:        "deleteTour",
+					InputArgs:   []model.Field{{Name: "year", TypeName: "int"}},
+					OutputArgs:  []model.Field{{TypeName: "error"}},
+				},
+			},
+		},
+		{
+			PackageName: "testData",
+			Name:        "Tour",
+			Fields: []model.Field{
+				{Name: "Year", TypeName: "int", Tag: "`json:\"year\"`"},
+				{Name: "Etappes", TypeName: "Etappe", IsSlice: true, Tag: "`json:\"etappes,omitempty\"`"},
+				{Name: "Winner", TypeName: "string", IsPointer: true, Tag: "`json:\"winner\"`"},
+				{Name: "Secret", TypeName: "string", Tag: "`json:\"-\"`"},
+			},
+		},
+		{
+			PackageName: "testData",
+			Name:        "Etappe",
+			Fields: []model.Field{
+				{TypeName: "Audit", IsEmbedded: true},
+				{Name: "Day", TypeName: "Time", PackageQualifier: "time", Tag: "`json:\"day\"`"},
+				{Name: "Length", TypeName: "float64"},
+				{Name: "Results", IsMap: true, MapKeyTypeName: "string", MapValueTypeName: "int"},
+			},
+		},
+		{
+			PackageName: "testData",
+			Name:        "Audit",
+			Fields: []model.Field{
+				{Name: "CreatedBy", TypeName: "string", Tag: "`json:\"createdBy\"`"},
+			},
+		},
+	}
+}
+
+func TestGenerateForOpenapi(t *testing.T) {
+	os.Remove("./testData/openapi.json")
+
+	err := Generate(tourStructs(), Config{Title: "Tour", ServerURL: "https://tour.example.com"}, "./testData/openapi.json")
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/openapi.json")
+	assert.NoError(t, err)
+	doc := Document{}
+	err = json.Unmarshal(data, &doc)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, Info{Title: "Tour", Version: "1.0.0"}, doc.Info)
+	assert.Equal(t, []Server{{URL: "https://tour.example.com"}}, doc.Servers)
+
+	// regular expressions of path-parameters are no part of the path
+	getTour := doc.Paths["/api/tour/{year}"]["get"]
+	assert.Equal(t, "getTour", getTour.OperationID)
+	assert.Equal(t, []string{"TourService"}, getTour.Tags)
+	assert.Equal(t, "Returns the tour of a year", getTour.Description)
+	assert.Equal(t, []Parameter{{Name: "year", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int32"}}}, getTour.Parameters)
+	assert.Nil(t, getTour.RequestBody)
+	assert.Equal(t, &Schema{AllOf: []*Schema{{Ref: "#/components/schemas/Tour"}}, Nullable: true}, getTour.Responses["200"].Content["application/json"].Schema)
+	assert.Equal(t, "#/components/schemas/Error", getTour.Responses["default"].Content["application/json"].Schema.Ref)
+
+	getTours := doc.Paths["/api/tour"]["get"]
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: "#/components/schemas/Tour"}}, getTours.Responses["200"].Content["application/json"].Schema)
+
+	createEtappe := doc.Paths["/api/tour/{year}/etappe"]["post"]
+	assert.Equal(t, "#/components/schemas/Etappe", createEtappe.RequestBody.Content["application/json"].Schema.Ref)
+	assert.True(t, createEtappe.RequestBody.Required)
+
+	deleteTour := doc.Paths["/api/tour/{year}"]["delete"]
+	assert.True(t, deleteTour.Deprecated)
+	assert.Nil(t, deleteTour.RequestBody)
+	assert.Equal(t, Response{Description: "No Content"}, deleteTour.Responses["204"])
+	assert.NotContains(t, deleteTour.Responses, "200")
+
+	tour := doc.Components.Schemas["Tour"]
+	assert.Equal(t, []string{"year"}, tour.Required)
+	assert.Equal(t, &Schema{Type: "integer", Format: "int32"}, tour.Properties["year"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: "#/components/schemas/Etappe"}}, tour.Properties["etappes"])
+	assert.Equal(t, &Schema{Type: "string", Nullable: true}, tour.Properties["winner"])
+	assert.NotContains(t, tour.Properties, "Secret")
+
+	// the fields of embedded structs are promoted
+	etappe := doc.Components.Schemas["Etappe"]
+	assert.Equal(t, []string{"Length", "Results", "createdBy", "day"}, etappe.Required)
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, etappe.Properties["day"])
+	assert.Equal(t, &Schema{Type: "number", Format: "double"}, etappe.Properties["Length"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "integer", Format: "int32"}}, etappe.Properties["Results"])
+	assert.Equal(t, &Schema{Type: "string"}, etappe.Properties["createdBy"])
+	assert.NotContains(t, doc.Components.Schemas, "Audit")
+
+	os.Remove("./testData/openapi.json")
+}
+
+func TestGenerateForOpenapiWithProblemDetailsAndStream(t *testing.T) {
+	structs := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @RestService( path = "/api")`, `// @ProblemDetails()`},
+			Name:        "EventService",
+			Operations: []*model.Operation{
+				{
+					PackageName: "testData",
+					DocLines:    []string{`// @RestOperation( method = "GET", path = "/event" )`, `// @StreamResponse()`},
+					Name:        "streamEvents",
+					OutputArgs:  []model.Field{{TypeName: "string", IsChannel: true}, {TypeName: "error"}},
+				},
+			},
+		},
+	}
+	doc, found := DefaultGenerator.buildDocument("testData", structs, Config{})
+	assert.True(t, found)
+	assert.Equal(t, Info{Title: "testData", Version: "1.0.0"}, doc.Info)
+	assert.Empty(t, doc.Servers)
+
+	streamEvents := doc.Paths["/api/event"]["get"]
+	assert.Equal(t, &Schema{Type: "string"}, streamEvents.Responses["200"].Content["application/x-ndjson"].Schema)
+	assert.Equal(t, "#/components/schemas/Problem", streamEvents.Responses["default"].Content["application/problem+json"].Schema.Ref)
+	assert.Equal(t, []string{"status", "title", "type"}, doc.Components.Schemas["Problem"].Required)
+	assert.NotContains(t, doc.Components.Schemas, "Error")
+}
+
+func TestGenerateForOpenapiWithSecurity(t *testing.T) {
+	oauth2 := `// @OAuth2( authorizationURL = "https://auth.example.com/authorize", tokenURL = "https://auth.example.com/token", scopes = "%s" )`
+	structs := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @RestService( path = "/api")`},
+			Name:        "UserService",
+			Operations: []*model.Operation{
+				{
+					PackageName: "testData",
+					DocLines:    []string{`// @RestOperation( method = "GET", path = "/user" )`, fmt.Sprintf(oauth2, "read:users")},
+					Name:        "getUsers",
+					OutputArgs:  []model.Field{{TypeName: "string", IsSlice: true}, {TypeName: "error"}},
+				},
+				{
+					PackageName: "testData",
+					DocLines:    []string{`// @RestOperation( method = "DELETE", path = "/user/{uid}" )`, `// @APIKey()`, fmt.Sprintf(oauth2, "write:users admin")},
+					Name:        "deleteUser",
+					InputArgs:   []model.Field{{Name: "uid", TypeName: "string"}},
+					OutputArgs:  []model.Field{{TypeName: "error"}},
+				},
+				{
+					PackageName: "testData",
+					DocLines:    []string{`// @RestOperation( method = "GET", path = "/user/{uid}" )`, `// @APIKey( location = "query" )`},
+					Name:        "getUser",
+					InputArgs:   []model.Field{{Name: "uid", TypeName: "string"}},
+					OutputArgs:  []model.Field{{TypeName: "string"}, {TypeName: "error"}},
+				},
+				{
+					PackageName: "testData",
+					DocLines:    []string{`// @RestOperation( method = "GET", path = "/status" )`},
+					Name:        "getStatus",
+					OutputArgs:  []model.Field{{TypeName: "string"}, {TypeName: "error"}},
+				},
+			},
+		},
+	}
+	doc, found := DefaultGenerator.buildDocument("testData", structs, Config{})
+	assert.True(t, found)
+
+	assert.Equal(t, map[string]*SecurityScheme{
+		"apiKey-header-X-API-Key": {Type: "apiKey", Name: "X-API-Key", In: "header"},
+		"apiKey-query-api_key":    {Type: "apiKey", Name: "api_key", In: "query"},
+		"oauth2": {Type: "oauth2", Flows: &OAuthFlows{AuthorizationCode: &OAuthFlow{
+			AuthorizationURL: "https://auth.example.com/authorize",
+			TokenURL:         "https://auth.example.com/token",
+			Scopes:           map[string]string{"read:users": "", "write:users": "", "admin": ""},
+		}}},
+	}, doc.Components.SecuritySchemes)
+
+	assert.Equal(t, []map[string][]string{{"oauth2": {"read:users"}}}, doc.Paths["/api/user"]["get"].Security)
+	// both the api-key and the bearer-token are required
+	assert.Equal(t, []map[string][]string{{"apiKey-header-X-API-Key": {}, "oauth2": {"write:users", "admin"}}}, doc.Paths["/api/user/{uid}"]["delete"].Security)
+	assert.Equal(t, []map[string][]string{{"apiKey-query-api_key": {}}}, doc.Paths["/api/user/{uid}"]["get"].Security)
+	assert.Nil(t, doc.Paths["/api/status"]["get"].Security)
+}
+
+func TestGenerateForOpenapiWithoutRestServices(t *testing.T) {
+	os.Remove("./testData/none.json")
+
+	err := Generate([]model.Struct{{PackageName: "testData", Name: "Tour"}}, Config{}, "./testData/none.json")
+	assert.Nil(t, err)
+
+	_, err = os.Stat("./testData/none.json")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,23 @@
+package proptest
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+func Generate(inputDir string, operations []model.Operation) error {
+	return DefaultGenerator.Generate(inputDir, operations)
+}
+
+func IsPropertyTest(o model.Operation) bool {
+	return DefaultGenerator.IsPropertyTest(o)
+}
+
+// GetRuns returns the number of random inputs to check: empty means the default of rapid
+func GetRuns(o model.Operation) string {
+	return DefaultGenerator.GetRuns(o)
+}
+
+// GetInvariants returns the boolean expressions in terms of input and result that must always hold
+func GetInvariants(o model.Operation) []string {
+	return DefaultGenerator.GetInvariants(o)
+}
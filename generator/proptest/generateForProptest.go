@@ -0,0 +1,194 @@
+package proptest
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/generator/proptest/proptestAnnotation"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+type Operations struct {
+	PackageName string
+	Operations  []model.Operation
+}
+
+// Generator generates property-tests for operations annotated with @PropertyTest in its registry
+type Generator struct {
+	registry *annotation.Registry
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+func (g *Generator) Generate(inputDir string, operations []model.Operation) error {
+	proptestAnnotation.RegisterIn(g.registry)
+
+	propertyOperations := []model.Operation{}
+	for _, o := range operations {
+		if g.IsPropertyTest(o) {
+			err := g.validatePropertyOperation(o)
+			if err != nil {
+				return err
+			}
+			propertyOperations = append(propertyOperations, o)
+		}
+	}
+
+	if len(propertyOperations) > 0 {
+		packageName, err := generationUtil.GetPackageNameOfOperations(operations)
+		if err != nil {
+			return err
+		}
+		targetDir, err := generationUtil.DetermineTargetPath(inputDir, packageName)
+		if err != nil {
+			return err
+		}
+		target := fmt.Sprintf("%s/propertyOperations_test.go", targetDir)
+
+		data := Operations{
+			PackageName: packageName,
+			Operations:  propertyOperations,
+		}
+		err = generationUtil.GenerateFileFromTemplate(data, "proptest", propertyTemplate, g.templateFuncs(), target)
+		if err != nil {
+			log.Fatalf("Error generating property tests for operations (%s)", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePropertyOperation makes sure the operation has the form func(input InputType) OutputType
+func (g *Generator) validatePropertyOperation(o model.Operation) error {
+	if len(o.InputArgs) != 1 || len(o.OutputArgs) != 1 {
+		return fmt.Errorf("Property-tested operation %s must have exactly one argument and one result", o.Name)
+	}
+	for _, arg := range []model.Field{o.InputArgs[0], o.OutputArgs[0]} {
+		if arg.PackageQualifier != "" || arg.IsChannel || arg.TypeName == "error" {
+			return fmt.Errorf("Property-tested operation %s uses unsupported type %s", o.Name, arg.TypeName)
+		}
+	}
+	if len(g.GetInvariants(o)) == 0 {
+		return fmt.Errorf("Property-tested operation %s has no @Property to verify", o.Name)
+	}
+	return nil
+}
+
+func (g *Generator) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"IsPropertyTest": g.IsPropertyTest,
+		"GetRuns":        g.GetRuns,
+		"GetInvariants":  g.GetInvariants,
+		"GetInputType":   GetInputType,
+		"GetInvocation":  GetInvocation,
+		"ToFirstUpper":   ToFirstUpper,
+	}
+}
+
+func (g *Generator) IsPropertyTest(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "PropertyTest")
+	return ok
+}
+
+// GetRuns returns the number of random inputs to check: empty means the default of rapid
+func (g *Generator) GetRuns(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "PropertyTest")
+	if ok {
+		return val.Attributes["runs"]
+	}
+	return ""
+}
+
+// GetInvariants returns the boolean expressions in terms of input and result that must always hold
+func (g *Generator) GetInvariants(o model.Operation) []string {
+	invariants := []string{}
+	for _, a := range g.registry.GetAll(o.DocLines, "Property") {
+		invariants = append(invariants, a.Attributes["invariant"])
+	}
+	return invariants
+}
+
+func GetInputType(o model.Operation) string {
+	arg := o.InputArgs[0]
+	typeName := arg.TypeName
+	if arg.IsPointer {
+		typeName = "*" + typeName
+	}
+	if arg.IsSlice {
+		typeName = "[]" + typeName
+	}
+	return typeName
+}
+
+func GetInvocation(o model.Operation) string {
+	if o.RelatedStruct != nil {
+		return fmt.Sprintf("(&%s{}).%s(input)", o.RelatedStruct.TypeName, o.Name)
+	}
+	return fmt.Sprintf("%s(input)", o.Name)
+}
+
+func ToFirstUpper(in string) string {
+	if len(in) == 0 {
+		return in
+	}
+	return strings.ToUpper(fmt.Sprintf("%c", in[0])) + in[1:]
+}
+
+var propertyTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"flag"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+{{range .Operations}}
+func TestProperty{{ToFirstUpper .Name}}(t *testing.T) {
+	{{if GetRuns . }}
+		defer useRapidChecks("{{GetRuns . }}")()
+	{{end}}
+	rapid.Check(t, func(t *rapid.T) {
+		input := rapid.Make[{{GetInputType . }}]().Draw(t, "input")
+		result := {{GetInvocation . }}
+		{{range GetInvariants . }}
+			if !({{.}}) {
+				t.Fatalf("Property %s does not hold for input %+v: result %+v", {{printf "%q" .}}, input, result)
+			}
+		{{end}}
+	})
+}
+{{end}}
+
+// useRapidChecks changes the number of checks of rapid, unless set on the command-line with -rapid.checks:
+// the returned function restores the original number
+func useRapidChecks(checks string) func() {
+	explicit := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "rapid.checks" {
+			explicit = true
+		}
+	})
+	f := flag.Lookup("rapid.checks")
+	if explicit || f == nil {
+		return func() {}
+	}
+	original := f.Value.String()
+	f.Value.Set(checks)
+	return func() {
+		f.Value.Set(original)
+	}
+}
+`
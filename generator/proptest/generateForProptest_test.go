@@ -0,0 +1,94 @@
+package proptest
+
+import (
+	"os"
+	"testing"
+
+	"io/ioutil"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateForProptest(t *testing.T) {
+	os.Remove("./testData/propertyOperations_test.go")
+
+	o := []model.Operation{
+		{
+			PackageName: "testData",
+			DocLines: []string{
+				`// @PropertyTest( runs = 100 )`,
+				`// @Property( invariant = "result.Value >= 0" )`,
+				`// @Property( invariant = "len(result.Items) == len(input)" )`,
+			},
+			Name: "summarize",
+			InputArgs: []model.Field{
+				{Name: "in", TypeName: "int", IsSlice: true},
+			},
+			OutputArgs: []model.Field{
+				{TypeName: "Summary"},
+			},
+		},
+		{
+			PackageName:   "testData",
+			DocLines:      []string{`// @PropertyTest()`, `// @Property( invariant = "result != nil" )`},
+			Name:          "normalize",
+			RelatedStruct: &model.Field{Name: "n", TypeName: "Normalizer", IsPointer: true},
+			InputArgs: []model.Field{
+				{Name: "p", TypeName: "Person"},
+			},
+			OutputArgs: []model.Field{
+				{TypeName: "Person", IsPointer: true},
+			},
+		},
+		{
+			PackageName: "testData",
+			Name:        "notTested",
+		},
+	}
+	err := Generate("testData", o)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/propertyOperations_test.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "func TestPropertySummarize(t *testing.T) {")
+	assert.Contains(t, string(data), `defer useRapidChecks("100")()`)
+	assert.Contains(t, string(data), `input := rapid.Make[[]int]().Draw(t, "input")`)
+	assert.Contains(t, string(data), "result := summarize(input)")
+	assert.Contains(t, string(data), "if !(result.Value >= 0) {")
+	assert.Contains(t, string(data), `"len(result.Items) == len(input)", input, result)`)
+
+	assert.Contains(t, string(data), "func TestPropertyNormalize(t *testing.T) {")
+	assert.Contains(t, string(data), "result := (&Normalizer{}).normalize(input)")
+	assert.NotContains(t, string(data), "notTested")
+
+	os.Remove("./testData/propertyOperations_test.go")
+}
+
+func TestGenerateForProptestWithoutProperty(t *testing.T) {
+	o := []model.Operation{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @PropertyTest()`},
+			Name:        "summarize",
+			InputArgs:   []model.Field{{Name: "in", TypeName: "int"}},
+			OutputArgs:  []model.Field{{TypeName: "int"}},
+		},
+	}
+	err := Generate("testData", o)
+	assert.Error(t, err)
+}
+
+func TestGenerateForProptestWithMultipleArguments(t *testing.T) {
+	o := []model.Operation{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @PropertyTest()`, `// @Property( invariant = "result >= 0" )`},
+			Name:        "add",
+			InputArgs:   []model.Field{{Name: "a", TypeName: "int"}, {Name: "b", TypeName: "int"}},
+			OutputArgs:  []model.Field{{TypeName: "int"}},
+		},
+	}
+	err := Generate("testData", o)
+	assert.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package proptestAnnotation
+
+import (
+	"strconv"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+)
+
+const (
+	typePropertyTest = "PropertyTest"
+	typeProperty     = "Property"
+	paramRuns        = "runs"
+	paramInvariant   = "invariant"
+)
+
+// Register makes the annotation-registry aware of these annotations
+func Register() {
+	RegisterIn(annotation.DefaultRegistry)
+}
+
+// RegisterIn makes the given registry aware of these annotations
+func RegisterIn(registry *annotation.Registry) {
+	registry.Register(typePropertyTest, []string{paramRuns}, validatePropertyTestAnnotation)
+	registry.Register(typeProperty, []string{paramInvariant}, validatePropertyAnnotation)
+}
+
+func validatePropertyTestAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typePropertyTest {
+		runs, hasRuns := annot.Attributes[paramRuns]
+		if !hasRuns {
+			return true
+		}
+		count, err := strconv.Atoi(runs)
+		return err == nil && count > 0
+	}
+	return false
+}
+
+func validatePropertyAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeProperty {
+		invariant, hasInvariant := annot.Attributes[paramInvariant]
+		return hasInvariant && invariant != ""
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package proptestAnnotation
+
+import (
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectPropertyTestAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	annot, ok := annotation.ResolveAnnotations([]string{`// @PropertyTest( runs = 100 )`})
+	assert.True(t, ok)
+	assert.Equal(t, "100", annot.Attributes["runs"])
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @PropertyTest()`})
+	assert.True(t, ok)
+}
+
+func TestInvalidPropertyTestAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @PropertyTest( runs = 0 )`})
+	assert.False(t, ok)
+}
+
+func TestCorrectPropertyAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	annot, ok := annotation.ResolveAnnotations([]string{`// @Property( invariant = "result.Value >= 0" )`})
+	assert.True(t, ok)
+	assert.Equal(t, "result.Value >= 0", annot.Attributes["invariant"])
+}
+
+func TestEmptyPropertyAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @Property( invariant = "" )`})
+	assert.False(t, ok)
+}
@@ -0,0 +1,11 @@
+package contract
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+// Generate emits a contract-test for every rest-service with a client, that drives all operations through the
+// generated client and http-handler
+func Generate(inputDir string, structs []model.Struct) error {
+	return DefaultGenerator.Generate(inputDir, structs)
+}
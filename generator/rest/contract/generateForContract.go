@@ -0,0 +1,159 @@
+package contract
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/generator/rest"
+	"github.com/MarcGrol/golangAnnotations/generator/rest/restAnnotation"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+type ContractData struct {
+	PackageName string
+	ImportPath  string
+	Service     model.Struct
+}
+
+// Generator generates contract-tests for rest-services with a client, resolving the rest-annotations in its registry
+type Generator struct {
+	registry *annotation.Registry
+	rest     *rest.Generator
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry, rest: rest.NewGenerator(registry)}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+// Generate emits a contract-test for every rest-service with a client, that drives all operations through the
+// generated client and http-handler
+func (g *Generator) Generate(inputDir string, structs []model.Struct) error {
+	restAnnotation.RegisterIn(g.registry)
+
+	packageName, err := generationUtil.GetPackageName(structs)
+	if err != nil {
+		return err
+	}
+
+	for _, service := range structs {
+		if g.rest.IsRestService(service) && g.rest.HasRestClient(service) {
+			targetDir, err := generationUtil.DetermineTargetPath(inputDir, packageName)
+			if err != nil {
+				return err
+			}
+			importPath, err := generationUtil.DetermineImportPath(targetDir)
+			if err != nil {
+				return err
+			}
+			target := fmt.Sprintf("%s/http%s_contract_test.go", targetDir, service.Name)
+
+			data := ContractData{
+				PackageName: packageName,
+				ImportPath:  importPath,
+				Service:     service,
+			}
+			err = generationUtil.GenerateFileFromTemplate(data, "contract", contractTemplate, g.templateFuncs(), target)
+			if err != nil {
+				log.Fatalf("Error generating contract-test for service %s: %s", service.Name, err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"IsRestOperation": g.rest.IsRestOperation,
+		"HasOutput":       rest.HasOutput,
+		"ToFirstUpper":    rest.ToFirstUpper,
+		"GetSampleArgs":   GetSampleArgs,
+	}
+}
+
+// GetSampleArgs returns the comma-separated sample-values with which the operation is invoked
+func GetSampleArgs(o model.Operation, packageName string) string {
+	args := []string{}
+	for _, arg := range o.InputArgs {
+		args = append(args, getSampleValue(arg, packageName))
+	}
+	return strings.Join(args, ", ")
+}
+
+// getSampleValue returns a non-empty literal for primitives, so path-parameters can be routed, and the zero-value
+// for all other types
+func getSampleValue(f model.Field, packageName string) string {
+	if f.IsPointer || f.IsSlice || f.IsMap {
+		return f.ZeroValueLiteral()
+	}
+	switch f.TypeName {
+	case "string":
+		return `"sample"`
+	case "bool":
+		return "true"
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"byte", "rune":
+		return "1"
+	case "float32", "float64":
+		return "1.5"
+	}
+	if f.PackageQualifier != "" {
+		return fmt.Sprintf("*new(%s.%s)", f.PackageQualifier, f.TypeName)
+	}
+	return fmt.Sprintf("*new(%s.%s)", packageName, f.TypeName)
+}
+
+var contractTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}_test
+
+import (
+	"testing"
+
+	"{{.ImportPath}}"
+	"{{.ImportPath}}/client"
+)
+
+{{ $packageName := .PackageName }}
+{{ $serviceName := .Service.Name }}
+
+// ContractTest{{$serviceName}} invokes every operation with sample-values via the generated client on a test-server:
+// it fails when the server and the client do not agree on the serialization-format
+func ContractTest{{$serviceName}}(t *testing.T) {
+	serverURL := {{$packageName}}.New{{$serviceName}}TestServer(t, &{{$packageName}}.{{$serviceName}}{})
+	c := client.New{{$serviceName}}Client(serverURL.String())
+{{range .Service.Operations}}
+{{if IsRestOperation . }}
+	t.Run("{{.Name}}", func(t *testing.T) {
+		{{if HasOutput . }}_, {{end}}err := c.{{ToFirstUpper .Name}}({{GetSampleArgs . $packageName}})
+		verify{{$serviceName}}Contract(t, err)
+	})
+{{end}}
+{{end}}
+}
+
+func TestContract{{$serviceName}}(t *testing.T) {
+	ContractTest{{$serviceName}}(t)
+}
+
+// verify{{$serviceName}}Contract accepts errors reported by the service, but not errors in transport or decoding
+func verify{{$serviceName}}Contract(t *testing.T, err error) {
+	if err == nil {
+		return
+	}
+	if clientErr, ok := err.(*client.ClientError); ok {
+		t.Logf("Service rejected sample-input: %s", clientErr)
+		return
+	}
+	t.Errorf("Server and client do not agree: %s", err)
+}
+`
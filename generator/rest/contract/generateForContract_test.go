@@ -0,0 +1,57 @@
+package contract
+
+import (
+	"os"
+	"testing"
+
+	"io/ioutil"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateForContract(t *testing.T) {
+	os.Remove("./testData/httpMyService_contract_test.go")
+
+	s := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @RestService( path = "/api", client = "true" )`},
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{`// @RestOperation( method = "PUT", path = "/person/{uid}" )`},
+					Name:          "updatePerson",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs: []model.Field{
+						{Name: "uid", TypeName: "string"},
+						{Name: "person", TypeName: "Person"},
+					},
+					OutputArgs: []model.Field{
+						{TypeName: "Person"},
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @RestService( path = "/other" )`},
+			Name:        "OtherService",
+		},
+	}
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService_contract_test.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "package testData_test")
+	assert.Contains(t, string(data), "func ContractTestMyService(t *testing.T) {")
+	assert.Contains(t, string(data), "serverURL := testData.NewMyServiceTestServer(t, &testData.MyService{})")
+	assert.Contains(t, string(data), `_, err := c.UpdatePerson("sample", *new(testData.Person))`)
+
+	_, err = os.Stat("./testData/httpOtherService_contract_test.go")
+	assert.True(t, os.IsNotExist(err))
+
+	os.Remove("./testData/httpMyService_contract_test.go")
+}
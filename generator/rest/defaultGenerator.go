@@ -0,0 +1,289 @@
+package rest
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+func Generate(inputDir string, structs []model.Struct) error {
+	return DefaultGenerator.Generate(inputDir, structs)
+}
+
+func HasBatchOperations(s model.Struct) bool {
+	return DefaultGenerator.HasBatchOperations(s)
+}
+
+func HasBatch(o model.Operation) bool {
+	return DefaultGenerator.HasBatch(o)
+}
+
+func GetBatchEndpoint(o model.Operation) string {
+	return DefaultGenerator.GetBatchEndpoint(o)
+}
+
+// GetBatchMethod returns the method with which the batch-endpoint is served: defaults to POST
+func GetBatchMethod(o model.Operation) string {
+	return DefaultGenerator.GetBatchMethod(o)
+}
+
+func GetBatchMaxItems(o model.Operation) string {
+	return DefaultGenerator.GetBatchMaxItems(o)
+}
+
+// GetBatchWorkers returns the number of items of a batch that are processed concurrently, unless the service-handler is
+// built with another number: defaults to 4
+func GetBatchWorkers(o model.Operation) string {
+	return DefaultGenerator.GetBatchWorkers(o)
+}
+
+func HasCORS(s model.Struct) bool {
+	return DefaultGenerator.HasCORS(s)
+}
+
+// GetCORSOrigins returns the allowed origins as a list of go-strings: all origins are allowed by default
+func GetCORSOrigins(s model.Struct) string {
+	return DefaultGenerator.GetCORSOrigins(s)
+}
+
+// GetCORSHeaders returns the request-headers that cross-origin requests are allowed to send
+func GetCORSHeaders(s model.Struct) string {
+	return DefaultGenerator.GetCORSHeaders(s)
+}
+
+// GetCORSMaxAge returns how many seconds browsers can cache the preflight-response: 0 means the browser decides
+func GetCORSMaxAge(s model.Struct) string {
+	return DefaultGenerator.GetCORSMaxAge(s)
+}
+
+func IsCORSCredentialsAllowed(s model.Struct) bool {
+	return DefaultGenerator.IsCORSCredentialsAllowed(s)
+}
+
+// GetCORSRoutes groups the rest-operations by path: paths that only differ in the names of their path-parameters, like
+// /users/{id} and /users/{uid}, are the same route
+func GetCORSRoutes(s model.Struct) []CORSRoute {
+	return DefaultGenerator.GetCORSRoutes(s)
+}
+
+func HasRestClient(s model.Struct) bool {
+	return DefaultGenerator.HasRestClient(s)
+}
+
+func UsesServiceTypes(s model.Struct) bool {
+	return DefaultGenerator.UsesServiceTypes(s)
+}
+
+func HasStreamResponseOperations(s model.Struct) bool {
+	return DefaultGenerator.HasStreamResponseOperations(s)
+}
+
+func IsRestService(s model.Struct) bool {
+	return DefaultGenerator.IsRestService(s)
+}
+
+func GetRestServicePath(o model.Struct) string {
+	return DefaultGenerator.GetRestServicePath(o)
+}
+
+func IsSubResource(s model.Struct) bool {
+	return DefaultGenerator.IsSubResource(s)
+}
+
+// GetSubResourcePath returns the path of a sub-resource relative to the path of its parent-service
+func GetSubResourcePath(s model.Struct) string {
+	return DefaultGenerator.GetSubResourcePath(s)
+}
+
+// GetRestServicePrefix returns the full path under which the operations of a service are served:
+// sub-resources are nested below their parent, when the parent is part of the same package
+func GetRestServicePrefix(s model.Struct, structs []model.Struct) string {
+	return DefaultGenerator.GetRestServicePrefix(s, structs)
+}
+
+func IsRestOperation(o model.Operation) bool {
+	return DefaultGenerator.IsRestOperation(o)
+}
+
+func GetRestOperationPath(o model.Operation) string {
+	return DefaultGenerator.GetRestOperationPath(o)
+}
+
+func GetRestOperationMethod(o model.Operation) string {
+	return DefaultGenerator.GetRestOperationMethod(o)
+}
+
+func HasBuildConstrainedOperations(s model.Struct) bool {
+	return DefaultGenerator.HasBuildConstrainedOperations(s)
+}
+
+func HasBuildConstraint(o model.Operation) bool {
+	return DefaultGenerator.HasBuildConstraint(o)
+}
+
+// GetBuildConstraint returns the build-constraint of the operation in the //go:build-syntax:
+// constraints in the legacy +build-syntax are converted
+func GetBuildConstraint(o model.Operation) string {
+	return DefaultGenerator.GetBuildConstraint(o)
+}
+
+func HasAPIKeyOperations(s model.Struct) bool {
+	return DefaultGenerator.HasAPIKeyOperations(s)
+}
+
+func HasAPIKey(o model.Operation) bool {
+	return DefaultGenerator.HasAPIKey(o)
+}
+
+func IsAPIKeyInQuery(o model.Operation) bool {
+	return DefaultGenerator.IsAPIKeyInQuery(o)
+}
+
+func GetAPIKeyName(o model.Operation) string {
+	return DefaultGenerator.GetAPIKeyName(o)
+}
+
+func HasOAuth2Operations(s model.Struct) bool {
+	return DefaultGenerator.HasOAuth2Operations(s)
+}
+
+func HasOAuth2(o model.Operation) bool {
+	return DefaultGenerator.HasOAuth2(o)
+}
+
+// GetOAuth2Scopes returns the space-separated scopes that a bearer-token must have been granted, as quoted go-strings
+func GetOAuth2Scopes(o model.Operation) string {
+	return DefaultGenerator.GetOAuth2Scopes(o)
+}
+
+func HasRequiredClaim(o model.Operation) bool {
+	return DefaultGenerator.HasRequiredClaim(o)
+}
+
+func GetRequiredClaimName(o model.Operation) string {
+	return DefaultGenerator.GetRequiredClaimName(o)
+}
+
+func GetRequiredClaimValue(o model.Operation) string {
+	return DefaultGenerator.GetRequiredClaimValue(o)
+}
+
+func HasRequestLoggingOperations(s model.Struct) bool {
+	return DefaultGenerator.HasRequestLoggingOperations(s)
+}
+
+func HasRequestLogging(o model.Operation) bool {
+	return DefaultGenerator.HasRequestLogging(o)
+}
+
+func GetRequestLoggingLevel(o model.Operation) string {
+	return DefaultGenerator.GetRequestLoggingLevel(o)
+}
+
+func IsRequestBodyLogged(o model.Operation) bool {
+	return DefaultGenerator.IsRequestBodyLogged(o)
+}
+
+func HasCacheableOperations(s model.Struct) bool {
+	return DefaultGenerator.HasCacheableOperations(s)
+}
+
+func IsCacheable(o model.Operation) bool {
+	return DefaultGenerator.IsCacheable(o)
+}
+
+func GetCacheMaxAge(o model.Operation) string {
+	return DefaultGenerator.GetCacheMaxAge(o)
+}
+
+func HasMaxBodySizeOperations(s model.Struct) bool {
+	return DefaultGenerator.HasMaxBodySizeOperations(s)
+}
+
+func HasMaxBodySize(o model.Operation) bool {
+	return DefaultGenerator.HasMaxBodySize(o)
+}
+
+func GetMaxBodySize(o model.Operation) string {
+	return DefaultGenerator.GetMaxBodySize(o)
+}
+
+func IsStreamResponse(o model.Operation) bool {
+	return DefaultGenerator.IsStreamResponse(o)
+}
+
+func GetStreamContentType(o model.Operation) string {
+	return DefaultGenerator.GetStreamContentType(o)
+}
+
+func HasDeprecatedOperations(s model.Struct) bool {
+	return DefaultGenerator.HasDeprecatedOperations(s)
+}
+
+func HasPanicSafeOperations(s model.Struct) bool {
+	return DefaultGenerator.HasPanicSafeOperations(s)
+}
+
+// IsPanicSafe tells if a panic in the handler of the operation is recovered and reported as an internal error: either
+// the operation or its service is annotated with @PanicSafe
+func IsPanicSafe(s model.Struct, o model.Operation) bool {
+	return DefaultGenerator.IsPanicSafe(s, o)
+}
+
+func IsDeprecated(o model.Operation) bool {
+	return DefaultGenerator.IsDeprecated(o)
+}
+
+func GetReplacedBy(o model.Operation) string {
+	return DefaultGenerator.GetReplacedBy(o)
+}
+
+func GetDeprecatedSince(o model.Operation) string {
+	return DefaultGenerator.GetDeprecatedSince(o)
+}
+
+// GetSensitiveFieldNames returns the quoted json-names of the fields of the request-body that are annotated with @Sensitive
+func GetSensitiveFieldNames(o model.Operation, structs []model.Struct) string {
+	return DefaultGenerator.GetSensitiveFieldNames(o, structs)
+}
+
+func IsSensitive(f model.Field) bool {
+	return DefaultGenerator.IsSensitive(f)
+}
+
+func HasInput(o model.Operation) bool {
+	return DefaultGenerator.HasInput(o)
+}
+
+// GetResponseField returns the field of the returned struct that is written as the response body: empty when the
+// returned struct itself is written
+func GetResponseField(o model.Operation) string {
+	return DefaultGenerator.GetResponseField(o)
+}
+
+func GetResponseFieldSelector(o model.Operation) string {
+	return DefaultGenerator.GetResponseFieldSelector(o)
+}
+
+func HasLinks(s model.Struct) bool {
+	return DefaultGenerator.HasLinks(s)
+}
+
+// ReturnsLinkedResource tells if the response of the operation is a struct with links, which are added to the response
+func ReturnsLinkedResource(o model.Operation, structs []model.Struct) bool {
+	return DefaultGenerator.ReturnsLinkedResource(o, structs)
+}
+
+func HasProblemDetailsOperations(s model.Struct) bool {
+	return DefaultGenerator.HasProblemDetailsOperations(s)
+}
+
+// UsesProblemDetails tells if the errors of the operation are reported as problem details: either the operation or its
+// service is annotated with @ProblemDetails
+func UsesProblemDetails(s model.Struct, o model.Operation) bool {
+	return DefaultGenerator.UsesProblemDetails(s, o)
+}
+
+// GetProblemType returns the uri that identifies the type of problem: the type of the operation wins over the type of
+// its service
+func GetProblemType(s model.Struct, o model.Operation) string {
+	return DefaultGenerator.GetProblemType(s, o)
+}
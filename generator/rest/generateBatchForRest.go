@@ -0,0 +1,242 @@
+package rest
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+const (
+	defaultBatchMethod  = "POST"
+	defaultBatchWorkers = "4"
+)
+
+func (g *Generator) generateBatch(targetDir string, packageName string) error {
+	target := fmt.Sprintf("%s/httpBatch.go", targetDir)
+	err := generationUtil.GenerateFileFromTemplate(struct{ PackageName string }{packageName}, "batch", BatchTemplate, g.templateFuncs(), target)
+	if err != nil {
+		log.Fatalf("Error generating batch helpers: %s", err)
+		return err
+	}
+	return nil
+}
+
+// validateBatchOperations makes sure every item of a batch can be passed to the operation as its request body: the
+// batch-endpoint itself has no path-parameters to fill in
+func (g *Generator) validateBatchOperations(s model.Struct) error {
+	for _, o := range s.Operations {
+		if !g.IsRestOperation(*o) || !g.HasBatch(*o) {
+			continue
+		}
+		if !g.HasInput(*o) || GetInputArgType(*o) == "" {
+			return fmt.Errorf("Operation %s.%s has a @Batch but no request body", s.Name, o.Name)
+		}
+		for _, arg := range o.InputArgs {
+			if IsPrimitive(arg) {
+				return fmt.Errorf("Operation %s.%s has a @Batch but takes path-parameter '%s'", s.Name, o.Name, arg.Name)
+			}
+		}
+		if strings.Contains(g.GetBatchEndpoint(*o), "{") {
+			return fmt.Errorf("Operation %s.%s has a @Batch with path-parameters in its endpoint", s.Name, o.Name)
+		}
+		if g.IsStreamResponse(*o) || g.IsDeprecated(*o) {
+			return fmt.Errorf("Operation %s.%s has a @Batch but is streamed or deprecated", s.Name, o.Name)
+		}
+	}
+	return nil
+}
+
+func (g *Generator) HasBatchOperations(s model.Struct) bool {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.HasBatch(*o) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) HasBatch(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "Batch")
+	return ok
+}
+
+func (g *Generator) GetBatchEndpoint(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "Batch")
+	if ok {
+		return val.Attributes["endpoint"]
+	}
+	return ""
+}
+
+// GetBatchMethod returns the method with which the batch-endpoint is served: defaults to POST
+func (g *Generator) GetBatchMethod(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "Batch")
+	if ok && val.Attributes["method"] != "" {
+		return strings.ToUpper(val.Attributes["method"])
+	}
+	return defaultBatchMethod
+}
+
+func (g *Generator) GetBatchMaxItems(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "Batch")
+	if ok {
+		return val.Attributes["maxitems"]
+	}
+	return ""
+}
+
+// GetBatchMaxBodySize returns the limit of the body of a batch: maxItems times the @MaxBodySize of the operation, or 0
+// for no limit when the operation has none
+func (g *Generator) GetBatchMaxBodySize(o model.Operation) string {
+	if !g.HasMaxBodySize(o) {
+		return "0"
+	}
+	maxItems, err := strconv.ParseInt(g.GetBatchMaxItems(o), 10, 64)
+	if err != nil {
+		return "0"
+	}
+	maxBytes, err := strconv.ParseInt(g.GetMaxBodySize(o), 10, 64)
+	if err != nil {
+		return "0"
+	}
+	return strconv.FormatInt(maxItems*maxBytes, 10)
+}
+
+// GetBatchWorkers returns the number of items of a batch that are processed concurrently, unless the service-handler is
+// built with another number: defaults to 4
+func (g *Generator) GetBatchWorkers(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "Batch")
+	if ok && val.Attributes["workers"] != "" {
+		return val.Attributes["workers"]
+	}
+	return defaultBatchWorkers
+}
+
+var BatchTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/MarcGrol/microgen/lib/myerrors"
+)
+
+// batchItemResponse is the outcome of a single item of a batch: the status and body the operation responded with
+type batchItemResponse struct {
+	Status int             ` + "`json:\"status\"`" + `
+	Body   json.RawMessage ` + "`json:\"body,omitempty\"`" + `
+}
+
+// batchItemRecorder captures the response of the operation to a single item of a batch
+type batchItemRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *batchItemRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *batchItemRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *batchItemRecorder) Write(data []byte) (int, error) {
+	return r.body.Write(data)
+}
+
+// batchHandler accepts a json-array of at most maxItems request bodies and passes each of them to the handler of the
+// operation, using a pool of workers: the number the service-handler was built with, or else the number of the @Batch.
+// The responses are returned in the order of the request bodies. A maxBytes of 0 leaves the size of the batch unlimited.
+func batchHandler(operation http.HandlerFunc, method string, maxItems int, maxBytes int64, workers int, annotatedWorkers int) http.HandlerFunc {
+	if workers < 1 {
+		workers = annotatedWorkers
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if maxBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		}
+		items := []json.RawMessage{}
+		err := json.NewDecoder(r.Body).Decode(&items)
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			json.NewEncoder(w).Encode(struct {
+				ErrorMessage string
+			}{
+				fmt.Sprintf("Batch exceeds %d bytes", maxBytesError.Limit),
+			})
+			return
+		}
+		if err != nil {
+			handleError(myerrors.NewInvalidInputError(fmt.Errorf("Invalid batch: %s", err)), w)
+			return
+		}
+		if len(items) > maxItems {
+			handleError(myerrors.NewInvalidInputError(fmt.Errorf("Batch of %d items exceeds the maximum of %d", len(items), maxItems)), w)
+			return
+		}
+
+		responses := make([]batchItemResponse, len(items))
+		indexes := make(chan int)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for idx := range indexes {
+					responses[idx] = serveBatchItem(operation, method, r, items[idx])
+				}
+			}()
+		}
+		for idx := range items {
+			indexes <- idx
+		}
+		close(indexes)
+		wg.Wait()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(responses)
+	}
+}
+
+// serveBatchItem passes a single item to the operation as if it were a request of its own, with the headers of the
+// batch-request: so every item is authenticated and validated like a regular request
+func serveBatchItem(operation http.HandlerFunc, method string, r *http.Request, item json.RawMessage) batchItemResponse {
+	itemRequest, err := http.NewRequest(method, r.URL.String(), bytes.NewReader(item))
+	if err != nil {
+		return batchItemResponse{Status: http.StatusInternalServerError}
+	}
+	itemRequest = itemRequest.WithContext(r.Context())
+	for name, values := range r.Header {
+		itemRequest.Header[name] = values
+	}
+	itemRequest.Header.Del("Content-Length")
+
+	recorder := &batchItemRecorder{header: http.Header{}, status: http.StatusOK}
+	operation(recorder, itemRequest)
+
+	body := bytes.TrimSpace(recorder.body.Bytes())
+	if len(body) > 0 && !json.Valid(body) {
+		body, _ = json.Marshal(string(body))
+	}
+	return batchItemResponse{Status: recorder.status, Body: body}
+}
+`
@@ -0,0 +1,207 @@
+package rest
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+const defaultCORSHeaders = "Content-Type, Authorization"
+
+// CORSRoute is a path of the service with the methods it is served with, which are announced in the preflight-response
+type CORSRoute struct {
+	Path    string
+	Methods string
+}
+
+func (g *Generator) generateCORS(targetDir string, packageName string) error {
+	target := fmt.Sprintf("%s/httpCORS.go", targetDir)
+	err := generationUtil.GenerateFileFromTemplate(struct{ PackageName string }{packageName}, "cors", CORSTemplate, g.templateFuncs(), target)
+	if err != nil {
+		log.Fatalf("Error generating cors helpers: %s", err)
+		return err
+	}
+	return nil
+}
+
+// validateCORS makes sure credentials are only allowed for explicit origins, as browsers do not accept them for any
+// origin
+func (g *Generator) validateCORS(s model.Struct) error {
+	if !g.HasCORS(s) {
+		return nil
+	}
+	if g.IsCORSCredentialsAllowed(s) {
+		for _, origin := range g.getCORSOrigins(s) {
+			if origin == "*" {
+				return fmt.Errorf("Service %s has a @CORS that allows credentials for any origin: list the allowed origins explicitly", s.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) HasCORS(s model.Struct) bool {
+	_, ok := g.registry.ResolveAnnotationByName(s.DocLines, "CORS")
+	return ok
+}
+
+// GetCORSOrigins returns the allowed origins as a list of go-strings: all origins are allowed by default
+func (g *Generator) GetCORSOrigins(s model.Struct) string {
+	quoted := []string{}
+	for _, origin := range g.getCORSOrigins(s) {
+		quoted = append(quoted, strconv.Quote(origin))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (g *Generator) getCORSOrigins(s model.Struct) []string {
+	origins := []string{}
+	if val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "CORS"); ok {
+		for _, origin := range strings.Split(val.Attributes["origins"], ",") {
+			if strings.TrimSpace(origin) != "" {
+				origins = append(origins, strings.TrimSpace(origin))
+			}
+		}
+	}
+	if len(origins) == 0 {
+		origins = append(origins, "*")
+	}
+	return origins
+}
+
+// GetCORSHeaders returns the request-headers that cross-origin requests are allowed to send
+func (g *Generator) GetCORSHeaders(s model.Struct) string {
+	val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "CORS")
+	if ok && val.Attributes["headers"] != "" {
+		return val.Attributes["headers"]
+	}
+	return defaultCORSHeaders
+}
+
+// GetCORSMaxAge returns how many seconds browsers can cache the preflight-response: 0 means the browser decides
+func (g *Generator) GetCORSMaxAge(s model.Struct) string {
+	val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "CORS")
+	if ok && val.Attributes["maxage"] != "" {
+		return val.Attributes["maxage"]
+	}
+	return "0"
+}
+
+func (g *Generator) IsCORSCredentialsAllowed(s model.Struct) bool {
+	val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "CORS")
+	return ok && val.Attributes["allowcredentials"] == "true"
+}
+
+// GetCORSRoutes groups the rest-operations, and the batch-endpoints they expose, by path: paths that only differ in the
+// names of their path-parameters, like /users/{id} and /users/{uid}, are the same route
+func (g *Generator) GetCORSRoutes(s model.Struct) []CORSRoute {
+	paths := []string{}
+	methodsOf := map[string][]string{}
+	addRoute := func(path string, method string) {
+		key := linkParamPattern.ReplaceAllString(path, "{}")
+		methods, found := methodsOf[key]
+		if !found {
+			paths = append(paths, path)
+		}
+		known := false
+		for _, m := range methods {
+			known = known || m == method
+		}
+		if !known {
+			methodsOf[key] = append(methods, method)
+		}
+	}
+	for _, o := range s.Operations {
+		if !g.IsRestOperation(*o) {
+			continue
+		}
+		addRoute(g.GetRestOperationPath(*o), g.GetRestOperationMethod(*o))
+		if g.HasBatch(*o) {
+			addRoute(g.GetBatchEndpoint(*o), g.GetBatchMethod(*o))
+		}
+	}
+	routes := []CORSRoute{}
+	for _, path := range paths {
+		methods := append(methodsOf[linkParamPattern.ReplaceAllString(path, "{}")], "OPTIONS")
+		routes = append(routes, CORSRoute{Path: path, Methods: strings.Join(methods, ", ")})
+	}
+	return routes
+}
+
+var CORSTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// corsPolicy describes which cross-origin requests are allowed, as configured with the @CORS-annotation
+type corsPolicy struct {
+	origins          []string
+	headers          string
+	maxAge           int
+	allowCredentials bool
+}
+
+// allowedOrigin returns the value of the Access-Control-Allow-Origin-header for the origin of the request
+func (p corsPolicy) allowedOrigin(r *http.Request) (string, bool) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return "", false
+	}
+	for _, allowed := range p.origins {
+		if allowed == "*" {
+			return "*", true
+		}
+		if allowed == origin {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+func (p corsPolicy) setOriginHeaders(w http.ResponseWriter, r *http.Request) bool {
+	origin, ok := p.allowedOrigin(r)
+	if !ok {
+		return false
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if origin != "*" {
+		w.Header().Set("Vary", "Origin")
+	}
+	if p.allowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+	return true
+}
+
+// middleware adds the cors-headers to the responses of the regular operations
+func (p corsPolicy) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.setOriginHeaders(w, r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// preflight answers the OPTIONS-request that browsers send before a cross-origin request, with the methods of the path
+func (p corsPolicy) preflight(methods string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", methods)
+		if p.setOriginHeaders(w, r) {
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", p.headers)
+			if p.maxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(p.maxAge))
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+`
@@ -0,0 +1,335 @@
+package rest
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+type ClientData struct {
+	PackageName string
+	ImportPath  string
+	Service     model.Struct
+}
+
+func (g *Generator) generateClients(targetDir string, packageName string, structs []model.Struct, templateFuncs template.FuncMap) error {
+	clientCount := 0
+	for _, service := range structs {
+		if g.IsRestService(service) && g.HasRestClient(service) {
+			clientCount++
+		}
+	}
+	if clientCount == 0 {
+		return nil
+	}
+
+	importPath, err := generationUtil.DetermineImportPath(targetDir)
+	if err != nil {
+		return err
+	}
+
+	for _, service := range structs {
+		if g.IsRestService(service) && g.HasRestClient(service) {
+			target := fmt.Sprintf("%s/client/http%sClient.go", targetDir, service.Name)
+
+			data := ClientData{
+				PackageName: packageName,
+				ImportPath:  importPath,
+				Service:     service,
+			}
+			err = generationUtil.GenerateFileFromTemplate(data, "client", ClientTemplate, templateFuncs, target)
+			if err != nil {
+				log.Fatalf("Error generating client for service %s: %s", service.Name, err)
+				return err
+			}
+		}
+	}
+
+	target := fmt.Sprintf("%s/client/httpClient.go", targetDir)
+	err = generationUtil.GenerateFileFromTemplate(struct{}{}, "clientHelpers", ClientHelpersTemplate, g.templateFuncs(), target)
+	if err != nil {
+		log.Fatalf("Error generating client helpers: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (g *Generator) HasRestClient(s model.Struct) bool {
+	val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "RestService")
+	if ok {
+		return val.Attributes["client"] == "true"
+	}
+	return false
+}
+
+func (g *Generator) UsesServiceTypes(s model.Struct) bool {
+	for _, o := range s.Operations {
+		if !g.IsRestOperation(*o) {
+			continue
+		}
+		for _, arg := range append(append([]model.Field{}, o.InputArgs...), o.OutputArgs...) {
+			if arg.PackageQualifier == "" && !isBuiltinType(arg.TypeName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func GetClientInputParamDecl(o model.Operation, packageName string) string {
+	args := []string{}
+	for _, arg := range o.InputArgs {
+		args = append(args, fmt.Sprintf("%s %s", arg.Name, qualifiedTypeName(arg, packageName)))
+	}
+	return strings.Join(args, ", ")
+}
+
+func GetClientOutputType(o model.Operation, packageName string) string {
+	for _, arg := range o.OutputArgs {
+		if arg.TypeName != "error" {
+			if arg.IsChannel {
+				// streamed items are collected
+				return "[]" + qualifiedTypeName(arg, packageName)
+			}
+			return qualifiedTypeName(arg, packageName)
+		}
+	}
+	return ""
+}
+
+func (g *Generator) HasStreamResponseOperations(s model.Struct) bool {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.IsStreamResponse(*o) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetClientItemType returns the type of the individual items of a streamed response
+func GetClientItemType(o model.Operation, packageName string) string {
+	for _, arg := range o.OutputArgs {
+		if arg.IsChannel {
+			return qualifiedTypeName(arg, packageName)
+		}
+	}
+	return ""
+}
+
+func qualifiedTypeName(f model.Field, packageName string) string {
+	typeName := f.TypeName
+	if f.PackageQualifier != "" {
+		typeName = fmt.Sprintf("%s.%s", f.PackageQualifier, typeName)
+	} else if !isBuiltinType(typeName) {
+		typeName = fmt.Sprintf("%s.%s", packageName, typeName)
+	}
+	if f.IsPointer {
+		typeName = "*" + typeName
+	}
+	if f.IsSlice {
+		typeName = "[]" + typeName
+	}
+	return typeName
+}
+
+func isBuiltinType(typeName string) bool {
+	switch typeName {
+	case "bool", "string", "error", "byte", "rune",
+		"int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return true
+	}
+	return false
+}
+
+var ClientTemplate string = `
+// Generated automatically: do not edit manually
+
+package client
+
+import (
+{{if HasStreamResponseOperations .Service }}
+	"encoding/json"
+{{end}}
+	"net/http"
+{{if UsesServiceTypes .Service }}
+	"{{.ImportPath}}"
+{{end}}
+)
+
+{{ $packageName := .PackageName }}
+{{ $clientName := printf "%sClient" .Service.Name }}
+{{ $servicePath := GetRestServicePrefix .Service }}
+
+type {{$clientName}} struct {
+	BaseURL     string
+	APIKey      string
+	AccessToken string
+	HTTPClient  *http.Client
+}
+
+func New{{$clientName}}(baseURL string) *{{$clientName}} {
+	return &{{$clientName}}{
+		BaseURL:    baseURL,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+{{range .Service.Operations}}
+
+{{if IsRestOperation . }}
+func (c *{{$clientName}}) {{ToFirstUpper .Name}}({{GetClientInputParamDecl . $packageName}}) {{if HasOutput . }}({{GetClientOutputType . $packageName}}, error){{else}}error{{end}} {
+	{{if HasOutput . }}
+		var result {{GetClientOutputType . $packageName}}
+	{{end}}
+	pathParams := map[string]interface{}{
+	{{range .InputArgs}}
+		{{if IsPrimitive . }}
+			"{{.Name}}": {{.Name}},
+		{{end}}
+	{{end}}
+	}
+	req, err := newRequest("{{GetRestOperationMethod . }}", c.BaseURL+"{{$servicePath}}{{GetRestOperationPath . }}", pathParams, {{if HasInput . }}{{GetInputArgName . }}{{else}}nil{{end}})
+	if err != nil {
+		return {{if HasOutput . }}result, {{end}}err
+	}
+	{{if HasAPIKey . }}
+		{{if IsAPIKeyInQuery . }}
+			setQueryParam(req, "{{GetAPIKeyName . }}", c.APIKey)
+		{{else}}
+			req.Header.Set("{{GetAPIKeyName . }}", c.APIKey)
+		{{end}}
+	{{end}}
+	{{if HasOAuth2 . }}
+		req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	{{end}}
+	{{if IsStreamResponse . }}
+		err = doStream(c.HTTPClient, req, func(dec *json.Decoder) error {
+			var item {{GetClientItemType . $packageName}}
+			err := dec.Decode(&item)
+			if err != nil {
+				return err
+			}
+			result = append(result, item)
+			return nil
+		})
+		return result, err
+	{{else if HasOutput . }}
+		err = do(c.HTTPClient, req, &result)
+		return result, err
+	{{else}}
+		return do(c.HTTPClient, req, nil)
+	{{end}}
+}
+{{end}}
+{{end}}
+`
+
+var ClientHelpersTemplate string = `
+// Generated automatically: do not edit manually
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ClientError is returned when the server responds with a non-successful http status-code
+type ClientError struct {
+	StatusCode   int
+	ErrorMessage string
+}
+
+func (e *ClientError) Error() string {
+	return fmt.Sprintf("Http status %d: %s", e.StatusCode, e.ErrorMessage)
+}
+
+func newRequest(method string, path string, pathParams map[string]interface{}, input interface{}) (*http.Request, error) {
+	for name, value := range pathParams {
+		path = strings.Replace(path, "{"+name+"}", url.PathEscape(fmt.Sprintf("%v", value)), -1)
+	}
+
+	var body io.Reader
+	if input != nil {
+		requestBody, err := json.Marshal(input)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(requestBody)
+	}
+
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+func setQueryParam(req *http.Request, name string, value string) {
+	query := req.URL.Query()
+	query.Set(name, value)
+	req.URL.RawQuery = query.Encode()
+}
+
+func do(httpClient *http.Client, req *http.Request, output interface{}) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newClientError(resp)
+	}
+
+	if output != nil {
+		return json.NewDecoder(resp.Body).Decode(output)
+	}
+	return nil
+}
+
+// doStream calls decodeItem for every newline-delimited json-object in the response
+func doStream(httpClient *http.Client, req *http.Request, decodeItem func(dec *json.Decoder) error) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newClientError(resp)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for dec.More() {
+		err = decodeItem(dec)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newClientError(resp *http.Response) error {
+	errorBody := struct {
+		ErrorMessage string
+	}{}
+	json.NewDecoder(resp.Body).Decode(&errorBody)
+	return &ClientError{StatusCode: resp.StatusCode, ErrorMessage: errorBody.ErrorMessage}
+}
+`
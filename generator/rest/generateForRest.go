@@ -2,9 +2,13 @@ package rest
 
 import (
 	"fmt"
-	"html/template"
+	"go/build/constraint"
 	"log"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/MarcGrol/golangAnnotations/annotation"
 	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
@@ -12,8 +16,21 @@ import (
 	"github.com/MarcGrol/golangAnnotations/model"
 )
 
-func Generate(inputDir string, structs []model.Struct) error {
-	restAnnotation.Register()
+// Generator generates the http-handlers and clients of services annotated with @RestService, resolving the
+// rest-annotations in its own registry
+type Generator struct {
+	registry *annotation.Registry
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+func (g *Generator) Generate(inputDir string, structs []model.Struct) error {
+	restAnnotation.RegisterIn(g.registry)
 
 	packageName, err := generationUtil.GetPackageName(structs)
 	if err != nil {
@@ -23,11 +40,98 @@ func Generate(inputDir string, structs []model.Struct) error {
 	if err != nil {
 		return err
 	}
+	handlerTemplateFuncs := g.templateFuncsForStructs(structs)
+	for _, s := range structs {
+		if g.IsSubResource(s) && !g.IsRestService(s) {
+			return fmt.Errorf("Struct %s is a @SubResource but is not a @RestService", s.Name)
+		}
+	}
+	err = g.validateLinks(structs)
+	if err != nil {
+		return err
+	}
+	// conflicts are reported before any file is written
 	for _, service := range structs {
-		if IsRestService(service) {
+		if g.IsRestService(service) {
+			err = g.validatePathConflicts(service, structs)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	apiKeyUsed := false
+	requestLoggingUsed := false
+	cachingUsed := false
+	deprecationUsed := false
+	oauth2Used := false
+	corsUsed := false
+	batchUsed := false
+	problemDetailsUsed := false
+	for _, service := range structs {
+		if g.IsRestService(service) {
+			err = g.validateCacheableOperations(service)
+			if err != nil {
+				return err
+			}
+			err = g.validateStreamResponseOperations(service)
+			if err != nil {
+				return err
+			}
+			err = g.validateOperationMethods(service)
+			if err != nil {
+				return err
+			}
+			err = g.validateMaxBodySizeOperations(service)
+			if err != nil {
+				return err
+			}
+			err = g.validateBuildConstraintOperations(service)
+			if err != nil {
+				return err
+			}
+			err = g.validateRequiredClaimOperations(service)
+			if err != nil {
+				return err
+			}
+			err = g.validateCORS(service)
+			if err != nil {
+				return err
+			}
+			err = g.validateBatchOperations(service)
+			if err != nil {
+				return err
+			}
+			err = g.validateResponseFields(service, structs)
+			if err != nil {
+				return err
+			}
+			if g.HasCacheableOperations(service) {
+				cachingUsed = true
+			}
+			if g.HasDeprecatedOperations(service) {
+				deprecationUsed = true
+			}
+			if g.HasAPIKeyOperations(service) {
+				apiKeyUsed = true
+			}
+			if g.HasOAuth2Operations(service) {
+				oauth2Used = true
+			}
+			if g.HasRequestLoggingOperations(service) {
+				requestLoggingUsed = true
+			}
+			if g.HasCORS(service) {
+				corsUsed = true
+			}
+			if g.HasBatchOperations(service) {
+				batchUsed = true
+			}
+			if g.HasProblemDetailsOperations(service) {
+				problemDetailsUsed = true
+			}
 			{
 				target := fmt.Sprintf("%s/http%s.go", targetDir, service.Name)
-				err = generationUtil.GenerateFileFromTemplate(service, "handlers", HandlersTemplate, customTemplateFuncs, target)
+				err = generationUtil.GenerateFileFromTemplate(service, "handlers", HandlersTemplate, handlerTemplateFuncs, target)
 				if err != nil {
 					log.Fatalf("Error generating handlers for service %s: %s", service.Name, err)
 					return err
@@ -35,77 +139,805 @@ func Generate(inputDir string, structs []model.Struct) error {
 			}
 			{
 				target := fmt.Sprintf("%s/http%sHelpers_test.go", targetDir, service.Name)
-				err = generationUtil.GenerateFileFromTemplate(service, "helpers", HelpersTemplate, customTemplateFuncs, target)
+				err = generationUtil.GenerateFileFromTemplate(service, "helpers", HelpersTemplate, handlerTemplateFuncs, target)
 				if err != nil {
 					log.Fatalf("Error generating helpers for service %s: %s", service.Name, err)
 					return err
 				}
 			}
+			err = g.generateBuildConstrainedRoutes(targetDir, service, handlerTemplateFuncs)
+			if err != nil {
+				return err
+			}
 
 		}
 	}
-	return nil
+	if apiKeyUsed {
+		target := fmt.Sprintf("%s/httpAPIKey.go", targetDir)
+		err = generationUtil.GenerateFileFromTemplate(struct{ PackageName string }{packageName}, "apiKey", APIKeyTemplate, g.templateFuncs(), target)
+		if err != nil {
+			log.Fatalf("Error generating api-key helpers: %s", err)
+			return err
+		}
+	}
+	if oauth2Used {
+		target := fmt.Sprintf("%s/httpOAuth2.go", targetDir)
+		err = generationUtil.GenerateFileFromTemplate(struct{ PackageName string }{packageName}, "oauth2", OAuth2Template, g.templateFuncs(), target)
+		if err != nil {
+			log.Fatalf("Error generating oauth2 helpers: %s", err)
+			return err
+		}
+	}
+	if requestLoggingUsed {
+		target := fmt.Sprintf("%s/httpRequestLogging.go", targetDir)
+		err = generationUtil.GenerateFileFromTemplate(struct{ PackageName string }{packageName}, "requestLogging", RequestLoggingTemplate, g.templateFuncs(), target)
+		if err != nil {
+			log.Fatalf("Error generating request-logging helpers: %s", err)
+			return err
+		}
+	}
+	if cachingUsed {
+		target := fmt.Sprintf("%s/httpCaching.go", targetDir)
+		err = generationUtil.GenerateFileFromTemplate(struct{ PackageName string }{packageName}, "caching", CachingTemplate, g.templateFuncs(), target)
+		if err != nil {
+			log.Fatalf("Error generating caching helpers: %s", err)
+			return err
+		}
+	}
+	if deprecationUsed {
+		target := fmt.Sprintf("%s/httpDeprecation.go", targetDir)
+		err = generationUtil.GenerateFileFromTemplate(struct{ PackageName string }{packageName}, "deprecation", DeprecationTemplate, g.templateFuncs(), target)
+		if err != nil {
+			log.Fatalf("Error generating deprecation helpers: %s", err)
+			return err
+		}
+	}
+	if corsUsed {
+		err = g.generateCORS(targetDir, packageName)
+		if err != nil {
+			return err
+		}
+	}
+	if batchUsed {
+		err = g.generateBatch(targetDir, packageName)
+		if err != nil {
+			return err
+		}
+	}
+	if problemDetailsUsed {
+		err = g.generateProblemDetails(targetDir, packageName)
+		if err != nil {
+			return err
+		}
+	}
+	err = g.generateLinks(targetDir, packageName, structs)
+	if err != nil {
+		return err
+	}
+	return g.generateClients(targetDir, packageName, structs, handlerTemplateFuncs)
+}
+
+// ConflictError reports two operations of a service that are served on the same method and path: the router would
+// only ever call the first of them
+type ConflictError struct {
+	Service string
+	Method  string
+	Path    string
+	First   string
+	Second  string
 }
 
-var customTemplateFuncs = template.FuncMap{
-	"IsRestService":          IsRestService,
-	"GetRestServicePath":     GetRestServicePath,
-	"IsRestOperation":        IsRestOperation,
-	"GetRestOperationPath":   GetRestOperationPath,
-	"GetRestOperationMethod": GetRestOperationMethod,
-	"HasInput":               HasInput,
-	"GetInputArgType":        GetInputArgType,
-	"GetInputArgName":        GetInputArgName,
-	"GetInputParamString":    GetInputParamString,
-	"GetOutputArgType":       GetOutputArgType,
-	"HasOutput":              HasOutput,
-	"IsPrimitive":            IsPrimitive,
-	"IsNumber":               IsNumber,
-	"ToFirstUpper":           ToFirstUpper,
-}
-
-func IsRestService(s model.Struct) bool {
-	annotation, ok := annotation.ResolveAnnotations(s.DocLines)
-	if !ok || annotation.Name != "RestService" {
+func (e ConflictError) Error() string {
+	return fmt.Sprintf("Operations %s and %s of service %s are both served on %s %s", e.First, e.Second, e.Service, e.Method, e.Path)
+}
+
+// routeParamPattern matches a path-parameter like {id} or {id:[0-9]+}: the name of a parameter does not distinguish
+// routes, its regular expression does
+var routeParamPattern = regexp.MustCompile(`\{[^}:]+(?::([^}]+))?\}`)
+
+type servedRoute struct {
+	operation       string
+	buildConstraint constraint.Expr
+}
+
+// maxDisjointTags limits the number of build-tags whose combinations are tried to prove two constraints disjoint
+const maxDisjointTags = 10
+
+// disjoint reports whether no combination of build-tags satisfies both constraints, like prod and !prod. Constraints
+// that mention more than maxDisjointTags tags are not considered disjoint.
+func disjoint(x constraint.Expr, y constraint.Expr) bool {
+	if x == nil || y == nil {
+		return false
+	}
+	tags := []string{}
+	collect := func(tag string) bool {
+		for _, t := range tags {
+			if t == tag {
+				return false
+			}
+		}
+		tags = append(tags, tag)
+		return false
+	}
+	x.Eval(collect)
+	y.Eval(collect)
+	if len(tags) > maxDisjointTags {
 		return false
 	}
+	for combination := 0; combination < 1<<uint(len(tags)); combination++ {
+		satisfied := func(tag string) bool {
+			for i, t := range tags {
+				if t == tag {
+					return combination&(1<<uint(i)) != 0
+				}
+			}
+			return false
+		}
+		if x.Eval(satisfied) && y.Eval(satisfied) {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePathConflicts makes sure no two operations of a service, or their batch-endpoints, are served on the same
+// method and path. Operations may only share a route when their build-constraints are disjoint, like prod and !prod:
+// they are then never compiled together.
+func (g *Generator) validatePathConflicts(s model.Struct, structs []model.Struct) error {
+	prefix := g.GetRestServicePrefix(s, structs)
+	served := map[string][]servedRoute{}
+	serve := func(method string, path string, route servedRoute) error {
+		method = strings.ToUpper(method)
+		key := method + " " + routeParamPattern.ReplaceAllString(prefix+path, "{$1}")
+		for _, other := range served[key] {
+			if !disjoint(other.buildConstraint, route.buildConstraint) {
+				return ConflictError{Service: s.Name, Method: method, Path: prefix + path, First: other.operation, Second: route.operation}
+			}
+		}
+		served[key] = append(served[key], route)
+		return nil
+	}
+	for _, o := range s.Operations {
+		if !g.IsRestOperation(*o) {
+			continue
+		}
+		buildConstraint, _ := g.parseBuildConstraint(*o)
+		route := servedRoute{operation: o.Name, buildConstraint: buildConstraint}
+		err := serve(g.GetRestOperationMethod(*o), g.GetRestOperationPath(*o), route)
+		if err != nil {
+			return err
+		}
+		if g.HasBatch(*o) {
+			batch := servedRoute{operation: "batch of " + o.Name, buildConstraint: route.buildConstraint}
+			err = serve(g.GetBatchMethod(*o), g.GetBatchEndpoint(*o), batch)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateOperationMethods makes sure every operation has a method and warns when its name suggests another method
+func (g *Generator) validateOperationMethods(s model.Struct) error {
+	for _, o := range s.Operations {
+		if !g.IsRestOperation(*o) {
+			continue
+		}
+		inferred := o.InferredHTTPMethod()
+		val, _ := g.registry.ResolveAnnotationByName(o.DocLines, "RestOperation")
+		method := val.Attributes["method"]
+		if method == "" && inferred == "" {
+			return fmt.Errorf("Operation %s.%s has no method and none can be inferred from its name", s.Name, o.Name)
+		}
+		if method != "" && inferred != "" && !strings.EqualFold(method, inferred) {
+			log.Printf("Warning: operation %s.%s uses method %s while its name suggests %s: consider renaming it", s.Name, o.Name, method, inferred)
+		}
+	}
+	return nil
+}
+
+// validateCacheableOperations makes sure only operations that are safe to cache are annotated with @Cacheable
+func (g *Generator) validateCacheableOperations(s model.Struct) error {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.IsCacheable(*o) {
+			if g.GetRestOperationMethod(*o) != "GET" {
+				return fmt.Errorf("Operation %s.%s is @Cacheable but has method %s: only GET is supported",
+					s.Name, o.Name, g.GetRestOperationMethod(*o))
+			}
+			if !HasOutput(*o) {
+				return fmt.Errorf("Operation %s.%s is @Cacheable but has no response body", s.Name, o.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMaxBodySizeOperations makes sure a limit is only put on request bodies and is a positive number:
+// an invalid @MaxBodySize would otherwise be silently ignored
+func (g *Generator) validateMaxBodySizeOperations(s model.Struct) error {
+	for _, o := range s.Operations {
+		if !g.IsRestOperation(*o) {
+			continue
+		}
+		if !g.HasMaxBodySize(*o) {
+			for _, validationError := range g.registry.Validate(o.DocLines) {
+				if validationError.AnnotationName == "MaxBodySize" {
+					return fmt.Errorf("Operation %s.%s has an invalid @MaxBodySize: bytes must be a positive integer", s.Name, o.Name)
+				}
+			}
+			continue
+		}
+		if !g.HasInput(*o) {
+			return fmt.Errorf("Operation %s.%s has a @MaxBodySize but no request body", s.Name, o.Name)
+		}
+	}
+	return nil
+}
+
+// validateRequiredClaimOperations makes sure a claim is only required from operations with an @APIKey: the claim is
+// checked on the api-key, so it would otherwise be silently ignored
+func (g *Generator) validateRequiredClaimOperations(s model.Struct) error {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.HasRequiredClaim(*o) && !g.HasAPIKey(*o) {
+			return fmt.Errorf("Operation %s.%s has a @RequireClaim but no @APIKey", s.Name, o.Name)
+		}
+	}
+	return nil
+}
+
+// validateBuildConstraintOperations makes sure the build-constraints of operations can be parsed
+func (g *Generator) validateBuildConstraintOperations(s model.Struct) error {
+	for _, o := range s.Operations {
+		if !g.IsRestOperation(*o) {
+			continue
+		}
+		_, err := g.parseBuildConstraint(*o)
+		if err != nil {
+			return fmt.Errorf("Operation %s.%s has an invalid build-constraint: %s", s.Name, o.Name, err)
+		}
+	}
+	return nil
+}
+
+type BuildConstrainedRoutes struct {
+	Constraint string
+	Service    model.Struct
+	Operations []*model.Operation
+}
+
+// generateBuildConstrainedRoutes emits the route-registration of operations with a build-constraint into a separate
+// file per constraint, which is only compiled when that constraint is satisfied
+func (g *Generator) generateBuildConstrainedRoutes(targetDir string, service model.Struct, templateFuncs template.FuncMap) error {
+	operationsPerConstraint := map[string][]*model.Operation{}
+	for _, o := range service.Operations {
+		if g.IsRestOperation(*o) && g.HasBuildConstraint(*o) {
+			c := g.GetBuildConstraint(*o)
+			operationsPerConstraint[c] = append(operationsPerConstraint[c], o)
+		}
+	}
+	constraints := []string{}
+	for c := range operationsPerConstraint {
+		constraints = append(constraints, c)
+	}
+	sort.Strings(constraints)
+
+	for _, c := range constraints {
+		target := fmt.Sprintf("%s/http%sRoutes%s.go", targetDir, service.Name, buildConstraintFileSuffix(c))
+		data := BuildConstrainedRoutes{
+			Constraint: c,
+			Service:    service,
+			Operations: operationsPerConstraint[c],
+		}
+		err := generationUtil.GenerateFileFromTemplate(data, "buildConstrainedRoutes", BuildConstrainedRoutesTemplate, templateFuncs, target)
+		if err != nil {
+			log.Fatalf("Error generating routes with build-constraint %s for service %s: %s", c, service.Name, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// buildConstraintFileSuffix derives a part of a file-name from a build-constraint, like ProdAndNotDebug for prod && !debug
+func buildConstraintFileSuffix(c string) string {
+	replacer := strings.NewReplacer("&&", " And ", "||", " Or ", "!", " Not ", "(", " ", ")", " ", ".", "_")
+	suffix := ""
+	for _, word := range strings.Fields(replacer.Replace(c)) {
+		suffix += ToFirstUpper(word)
+	}
+	return suffix
+}
+
+// validateStreamResponseOperations makes sure operations annotated with @StreamResponse return a channel to read from
+func (g *Generator) validateStreamResponseOperations(s model.Struct) error {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.IsStreamResponse(*o) {
+			if !returnsChannel(*o) {
+				return fmt.Errorf("Operation %s.%s is a @StreamResponse but does not return a channel", s.Name, o.Name)
+			}
+			if g.IsCacheable(*o) {
+				return fmt.Errorf("Operation %s.%s is a @StreamResponse and cannot be @Cacheable", s.Name, o.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"IsRestService":                 g.IsRestService,
+		"GetRestServicePath":            g.GetRestServicePath,
+		"IsSubResource":                 g.IsSubResource,
+		"GetSubResourcePath":            g.GetSubResourcePath,
+		"IsRestOperation":               g.IsRestOperation,
+		"GetRestOperationPath":          g.GetRestOperationPath,
+		"GetRestOperationMethod":        g.GetRestOperationMethod,
+		"HasMaxBodySizeOperations":      g.HasMaxBodySizeOperations,
+		"HasBuildConstrainedOperations": g.HasBuildConstrainedOperations,
+		"HasBuildConstraint":            g.HasBuildConstraint,
+		"HasMaxBodySize":                g.HasMaxBodySize,
+		"GetMaxBodySize":                g.GetMaxBodySize,
+		"HasAPIKey":                     g.HasAPIKey,
+		"HasOAuth2":                     g.HasOAuth2,
+		"GetOAuth2Scopes":               g.GetOAuth2Scopes,
+		"IsAPIKeyInQuery":               g.IsAPIKeyInQuery,
+		"GetAPIKeyName":                 g.GetAPIKeyName,
+		"HasRequiredClaim":              g.HasRequiredClaim,
+		"GetRequiredClaimName":          g.GetRequiredClaimName,
+		"GetRequiredClaimValue":         g.GetRequiredClaimValue,
+		"HasRequestLogging":             g.HasRequestLogging,
+		"GetRequestLoggingLevel":        g.GetRequestLoggingLevel,
+		"IsRequestBodyLogged":           g.IsRequestBodyLogged,
+		"IsCacheable":                   g.IsCacheable,
+		"GetCacheMaxAge":                g.GetCacheMaxAge,
+		"IsStreamResponse":              g.IsStreamResponse,
+		"IsDeprecated":                  g.IsDeprecated,
+		"GetReplacedBy":                 g.GetReplacedBy,
+		"GetDeprecatedSince":            g.GetDeprecatedSince,
+		"GetStreamContentType":          g.GetStreamContentType,
+		"HasInput":                      g.HasInput,
+		"GetInputArgType":               GetInputArgType,
+		"GetInputArgName":               GetInputArgName,
+		"GetInputParamString":           GetInputParamString,
+		"GetOutputArgType":              GetOutputArgType,
+		"GetResponseFieldSelector":      g.GetResponseFieldSelector,
+		"HasOutput":                     HasOutput,
+		"IsPrimitive":                   IsPrimitive,
+		"IsNumber":                      IsNumber,
+		"ToFirstUpper":                  ToFirstUpper,
+		"UsesServiceTypes":              g.UsesServiceTypes,
+		"GetClientInputParamDecl":       GetClientInputParamDecl,
+		"GetClientOutputType":           GetClientOutputType,
+		"GetClientItemType":             GetClientItemType,
+		"HasStreamResponseOperations":   g.HasStreamResponseOperations,
+		"HasCORS":                       g.HasCORS,
+		"GetCORSOrigins":                g.GetCORSOrigins,
+		"GetCORSHeaders":                g.GetCORSHeaders,
+		"GetCORSMaxAge":                 g.GetCORSMaxAge,
+		"IsCORSCredentialsAllowed":      g.IsCORSCredentialsAllowed,
+		"GetCORSRoutes":                 g.GetCORSRoutes,
+		"HasBatchOperations":            g.HasBatchOperations,
+		"HasBatch":                      g.HasBatch,
+		"GetBatchEndpoint":              g.GetBatchEndpoint,
+		"GetBatchMethod":                g.GetBatchMethod,
+		"GetBatchMaxItems":              g.GetBatchMaxItems,
+		"GetBatchMaxBodySize":           g.GetBatchMaxBodySize,
+		"GetBatchWorkers":               g.GetBatchWorkers,
+		"UsesProblemDetails":            g.UsesProblemDetails,
+		"GetProblemType":                g.GetProblemType,
+		"HasPanicSafeOperations":        g.HasPanicSafeOperations,
+		"IsPanicSafe":                   g.IsPanicSafe,
+	}
+}
+
+// templateFuncsForStructs extends the custom template-funcs with funcs that need to know about all structs of the package
+func (g *Generator) templateFuncsForStructs(structs []model.Struct) template.FuncMap {
+	funcs := template.FuncMap{}
+	for name, f := range g.templateFuncs() {
+		funcs[name] = f
+	}
+	funcs["GetSensitiveFieldNames"] = func(o model.Operation) string {
+		return g.GetSensitiveFieldNames(o, structs)
+	}
+	funcs["GetRestServicePrefix"] = func(s model.Struct) string {
+		return g.GetRestServicePrefix(s, structs)
+	}
+	// the response of an operation with a responseField is the field of the returned struct
+	funcs["ReturnsLinkedResource"] = func(o model.Operation) bool {
+		return g.ReturnsLinkedResource(g.unwrapResponse(o, structs), structs)
+	}
+	funcs["GetOutputArgType"] = func(o model.Operation) string {
+		return GetOutputArgType(g.unwrapResponse(o, structs))
+	}
+	funcs["GetClientOutputType"] = func(o model.Operation, packageName string) string {
+		return GetClientOutputType(g.unwrapResponse(o, structs), packageName)
+	}
+	funcs["UsesServiceTypes"] = func(s model.Struct) bool {
+		unwrapped := s
+		unwrapped.Operations = []*model.Operation{}
+		for _, o := range s.Operations {
+			u := g.unwrapResponse(*o, structs)
+			unwrapped.Operations = append(unwrapped.Operations, &u)
+		}
+		return g.UsesServiceTypes(unwrapped)
+	}
+	return funcs
+}
+
+func (g *Generator) IsRestService(s model.Struct) bool {
+	_, ok := g.registry.ResolveAnnotationByName(s.DocLines, "RestService")
 	return ok
 }
 
-func GetRestServicePath(o model.Struct) string {
-	val, ok := annotation.ResolveAnnotations(o.DocLines)
+func (g *Generator) GetRestServicePath(o model.Struct) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "RestService")
 	if ok {
 		return val.Attributes["path"]
 	}
 	return ""
 }
 
-func IsRestOperation(o model.Operation) bool {
-	annotation, ok := annotation.ResolveAnnotations(o.DocLines)
-	if !ok || annotation.Name != "RestOperation" {
-		return false
+func (g *Generator) IsSubResource(s model.Struct) bool {
+	_, ok := g.registry.ResolveAnnotationByName(s.DocLines, "SubResource")
+	return ok
+}
+
+// GetSubResourcePath returns the path of a sub-resource relative to the path of its parent-service
+func (g *Generator) GetSubResourcePath(s model.Struct) string {
+	val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "SubResource")
+	if ok {
+		return val.Attributes["parentpath"] + g.GetRestServicePath(s)
+	}
+	return g.GetRestServicePath(s)
+}
+
+// GetRestServicePrefix returns the full path under which the operations of a service are served:
+// sub-resources are nested below their parent, when the parent is part of the same package
+func (g *Generator) GetRestServicePrefix(s model.Struct, structs []model.Struct) string {
+	return g.getRestServicePrefix(s, structs, map[string]bool{})
+}
+
+func (g *Generator) getRestServicePrefix(s model.Struct, structs []model.Struct, visited map[string]bool) string {
+	val, ok := g.registry.ResolveAnnotationByName(s.DocLines, "SubResource")
+	if !ok {
+		return g.GetRestServicePath(s)
+	}
+	visited[s.Name] = true
+	for _, parent := range structs {
+		if parent.Name == val.Attributes["parent"] && !visited[parent.Name] && g.IsRestService(parent) {
+			return g.getRestServicePrefix(parent, structs, visited) + g.GetSubResourcePath(s)
+		}
 	}
+	return g.GetSubResourcePath(s)
+}
+
+func (g *Generator) IsRestOperation(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "RestOperation")
 	return ok
 }
 
-func GetRestOperationPath(o model.Operation) string {
-	val, ok := annotation.ResolveAnnotations(o.DocLines)
+func (g *Generator) GetRestOperationPath(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "RestOperation")
 	if ok {
 		return val.Attributes["path"]
 	}
 	return ""
 }
 
-func GetRestOperationMethod(o model.Operation) string {
-	val, ok := annotation.ResolveAnnotations(o.DocLines)
+func (g *Generator) GetRestOperationMethod(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "RestOperation")
 	if ok {
-		return val.Attributes["method"]
+		method := val.Attributes["method"]
+		if method == "" {
+			return o.InferredHTTPMethod()
+		}
+		return method
 	}
 	return ""
 }
 
-func HasInput(o model.Operation) bool {
-	if GetRestOperationMethod(o) == "POST" || GetRestOperationMethod(o) == "PUT" {
+func (g *Generator) HasBuildConstrainedOperations(s model.Struct) bool {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.HasBuildConstraint(*o) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) HasBuildConstraint(o model.Operation) bool {
+	return g.GetBuildConstraint(o) != ""
+}
+
+// GetBuildConstraint returns the build-constraint of the operation in the //go:build-syntax:
+// constraints in the legacy +build-syntax are converted
+func (g *Generator) GetBuildConstraint(o model.Operation) string {
+	expr, err := g.parseBuildConstraint(o)
+	if err != nil || expr == nil {
+		return ""
+	}
+	return expr.String()
+}
+
+func (g *Generator) parseBuildConstraint(o model.Operation) (constraint.Expr, error) {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "RestOperation")
+	if !ok {
+		return nil, nil
+	}
+	raw := strings.TrimSpace(val.Attributes["buildconstraint"])
+	if raw == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(raw, "+build") {
+		return constraint.Parse("// " + raw)
+	}
+	return constraint.Parse("//go:build " + raw)
+}
+
+func (g *Generator) HasAPIKeyOperations(s model.Struct) bool {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.HasAPIKey(*o) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) HasAPIKey(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "APIKey")
+	return ok
+}
+
+func (g *Generator) IsAPIKeyInQuery(o model.Operation) bool {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "APIKey")
+	if ok {
+		return val.Attributes["location"] == "query"
+	}
+	return false
+}
+
+func (g *Generator) GetAPIKeyName(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "APIKey")
+	if !ok {
+		return ""
+	}
+	if g.IsAPIKeyInQuery(o) {
+		if name := val.Attributes["paramname"]; name != "" {
+			return name
+		}
+		return "api_key"
+	}
+	if name := val.Attributes["header"]; name != "" {
+		return name
+	}
+	return "X-API-Key"
+}
+
+func (g *Generator) HasOAuth2Operations(s model.Struct) bool {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.HasOAuth2(*o) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) HasOAuth2(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "OAuth2")
+	return ok
+}
+
+// GetOAuth2Scopes returns the space-separated scopes that a bearer-token must have been granted, as quoted go-strings
+func (g *Generator) GetOAuth2Scopes(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "OAuth2")
+	if !ok {
+		return ""
+	}
+	scopes := []string{}
+	for _, scope := range strings.Fields(val.Attributes["scopes"]) {
+		scopes = append(scopes, strconv.Quote(scope))
+	}
+	return strings.Join(scopes, ", ")
+}
+
+func (g *Generator) HasRequiredClaim(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "RequireClaim")
+	return ok
+}
+
+func (g *Generator) GetRequiredClaimName(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "RequireClaim")
+	if ok {
+		return val.Attributes["name"]
+	}
+	return ""
+}
+
+func (g *Generator) GetRequiredClaimValue(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "RequireClaim")
+	if ok {
+		return val.Attributes["value"]
+	}
+	return ""
+}
+
+func (g *Generator) HasRequestLoggingOperations(s model.Struct) bool {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.HasRequestLogging(*o) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) HasRequestLogging(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "RequestLogging")
+	return ok
+}
+
+func (g *Generator) GetRequestLoggingLevel(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "RequestLogging")
+	if ok && val.Attributes["level"] != "" {
+		return val.Attributes["level"]
+	}
+	return "info"
+}
+
+func (g *Generator) IsRequestBodyLogged(o model.Operation) bool {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "RequestLogging")
+	if ok {
+		return val.Attributes["includebody"] == "true"
+	}
+	return false
+}
+
+func (g *Generator) HasCacheableOperations(s model.Struct) bool {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.IsCacheable(*o) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) IsCacheable(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "Cacheable")
+	return ok
+}
+
+func (g *Generator) GetCacheMaxAge(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "Cacheable")
+	if ok {
+		return val.Attributes["maxage"]
+	}
+	return ""
+}
+
+func (g *Generator) HasMaxBodySizeOperations(s model.Struct) bool {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.HasMaxBodySize(*o) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) HasMaxBodySize(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "MaxBodySize")
+	return ok
+}
+
+func (g *Generator) GetMaxBodySize(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "MaxBodySize")
+	if ok {
+		return val.Attributes["bytes"]
+	}
+	return ""
+}
+
+func (g *Generator) IsStreamResponse(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "StreamResponse")
+	return ok
+}
+
+func (g *Generator) GetStreamContentType(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "StreamResponse")
+	if ok && val.Attributes["contenttype"] != "" {
+		return val.Attributes["contenttype"]
+	}
+	return "application/x-ndjson"
+}
+
+func (g *Generator) HasDeprecatedOperations(s model.Struct) bool {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.IsDeprecated(*o) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) HasPanicSafeOperations(s model.Struct) bool {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.IsPanicSafe(s, *o) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPanicSafe tells if a panic in the handler of the operation is recovered and reported as an internal error: either
+// the operation or its service is annotated with @PanicSafe
+func (g *Generator) IsPanicSafe(s model.Struct, o model.Operation) bool {
+	if _, ok := g.registry.ResolveAnnotationByName(o.DocLines, "PanicSafe"); ok {
+		return true
+	}
+	_, ok := g.registry.ResolveAnnotationByName(s.DocLines, "PanicSafe")
+	return ok
+}
+
+func (g *Generator) IsDeprecated(o model.Operation) bool {
+	_, ok := g.registry.ResolveAnnotationByName(o.DocLines, "Deprecated")
+	return ok
+}
+
+func (g *Generator) GetReplacedBy(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "Deprecated")
+	if ok {
+		return val.Attributes["replacedby"]
+	}
+	return ""
+}
+
+func (g *Generator) GetDeprecatedSince(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "Deprecated")
+	if ok {
+		return val.Attributes["since"]
+	}
+	return ""
+}
+
+func returnsChannel(o model.Operation) bool {
+	for _, arg := range o.OutputArgs {
+		if arg.IsChannel {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSensitiveFieldNames returns the quoted json-names of the fields of the request-body that are annotated with @Sensitive
+func (g *Generator) GetSensitiveFieldNames(o model.Operation, structs []model.Struct) string {
+	inputType := GetInputArgType(o)
+	names := []string{}
+	for _, s := range structs {
+		if s.Name != inputType {
+			continue
+		}
+		for _, f := range s.Fields {
+			if g.IsSensitive(f) {
+				names = append(names, fmt.Sprintf("%q", getJSONFieldName(f)))
+			}
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func (g *Generator) IsSensitive(f model.Field) bool {
+	_, ok := g.registry.ResolveAnnotationByName(append(append([]string{}, f.DocLines...), f.CommentLines...), "Sensitive")
+	return ok
+}
+
+func getJSONFieldName(f model.Field) string {
+	name := strings.Split(f.TagGet("json"), ",")[0]
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+func (g *Generator) HasInput(o model.Operation) bool {
+	if g.GetRestOperationMethod(o) == "POST" || g.GetRestOperationMethod(o) == "PUT" {
 		return true
 	}
 	return false
@@ -126,33 +958,120 @@ func GetInputArgName(o model.Operation) string {
 			return arg.Name
 		}
 	}
-	return ""
+	return ""
+}
+
+func GetInputParamString(o model.Operation) string {
+	args := []string{}
+	for _, arg := range o.InputArgs {
+		args = append(args, arg.Name)
+	}
+	return strings.Join(args, ",")
+}
+
+func HasOutput(o model.Operation) bool {
+	for _, arg := range o.OutputArgs {
+		if arg.TypeName != "error" {
+			return true
+		}
+	}
+	return false
+}
+
+func GetOutputArgType(o model.Operation) string {
+	for _, arg := range o.OutputArgs {
+		if arg.TypeName != "error" {
+			return arg.TypeName
+		}
+	}
+	return ""
+}
+
+// GetResponseField returns the field of the returned struct that is written as the response body: empty when the
+// returned struct itself is written
+func (g *Generator) GetResponseField(o model.Operation) string {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "RestOperation")
+	if ok {
+		return val.Attributes["responsefield"]
+	}
+	return ""
+}
+
+func (g *Generator) GetResponseFieldSelector(o model.Operation) string {
+	if g.GetResponseField(o) == "" {
+		return ""
+	}
+	return "." + g.GetResponseField(o)
 }
 
-func GetInputParamString(o model.Operation) string {
-	args := []string{}
-	for _, arg := range o.InputArgs {
-		args = append(args, arg.Name)
+// validateResponseFields makes sure the responseField of an operation is a field of the struct that it returns
+func (g *Generator) validateResponseFields(s model.Struct, structs []model.Struct) error {
+	for _, o := range s.Operations {
+		if !g.IsRestOperation(*o) || g.GetResponseField(*o) == "" {
+			continue
+		}
+		if !HasOutput(*o) || g.IsStreamResponse(*o) {
+			return fmt.Errorf("Operation %s.%s has a responseField but no response body", s.Name, o.Name)
+		}
+		_, err := g.getResponseField(*o, structs)
+		if err != nil {
+			return fmt.Errorf("Operation %s.%s has an invalid responseField: %s", s.Name, o.Name, err)
+		}
 	}
-	return strings.Join(args, ",")
+	return nil
 }
 
-func HasOutput(o model.Operation) bool {
+func (g *Generator) getResponseField(o model.Operation, structs []model.Struct) (model.Field, error) {
+	name := g.GetResponseField(o)
 	for _, arg := range o.OutputArgs {
-		if arg.TypeName != "error" {
-			return true
+		if arg.TypeName == "error" {
+			continue
+		}
+		if arg.IsSlice || arg.IsMap || arg.PackageQualifier != "" {
+			return model.Field{}, fmt.Errorf("%s is not a struct of package %s", arg.TypeName, o.PackageName)
+		}
+		for _, returned := range structs {
+			if returned.Name != arg.TypeName {
+				continue
+			}
+			for _, f := range returned.Fields {
+				if f.Name == name && !f.IsEmbedded {
+					if f.IsMap || f.IsChannel {
+						return model.Field{}, fmt.Errorf("field %s.%s has an unsupported type", returned.Name, name)
+					}
+					return f, nil
+				}
+			}
+			return model.Field{}, fmt.Errorf("struct %s has no field %s", returned.Name, name)
 		}
+		return model.Field{}, fmt.Errorf("%s is not a struct of package %s", arg.TypeName, o.PackageName)
 	}
-	return false
+	return model.Field{}, fmt.Errorf("the operation returns no struct")
 }
 
-func GetOutputArgType(o model.Operation) string {
+// unwrapResponse returns a copy of the operation that returns the type of its responseField instead of the struct
+// around it
+func (g *Generator) unwrapResponse(o model.Operation, structs []model.Struct) model.Operation {
+	if g.GetResponseField(o) == "" {
+		return o
+	}
+	field, err := g.getResponseField(o, structs)
+	if err != nil {
+		return o
+	}
+	field.Name = ""
+	field.DocLines = nil
+	field.Tag = ""
+	outputArgs := []model.Field{}
 	for _, arg := range o.OutputArgs {
-		if arg.TypeName != "error" {
-			return arg.TypeName
+		if arg.TypeName == "error" {
+			outputArgs = append(outputArgs, arg)
+		} else {
+			outputArgs = append(outputArgs, field)
 		}
 	}
-	return ""
+	o.OutputArgs = outputArgs
+	return o
 }
 
 func IsPrimitive(f model.Field) bool {
@@ -170,16 +1089,34 @@ func ToFirstUpper(in string) string {
 	return strings.ToUpper(fmt.Sprintf("%c", in[0])) + in[1:]
 }
 
-var HandlersTemplate string = `
+// routeTemplate registers the handler of a single operation on the subRouter of the service: the items of a batch pass
+// through the same handler, so they are logged and limited like a single request
+var routeTemplate string = `{{define "handler"}}{{if and (HasRequestLogging . ) (HasMaxBodySize . ) }}withMaxBodySize({{GetMaxBodySize . }}, withRequestLogging("{{GetRequestLoggingLevel . }}", {{IsRequestBodyLogged . }}, []string{ {{GetSensitiveFieldNames . }} }, {{.Name}}(ts))){{else if HasRequestLogging . }}withRequestLogging("{{GetRequestLoggingLevel . }}", {{IsRequestBodyLogged . }}, []string{ {{GetSensitiveFieldNames . }} }, {{.Name}}(ts)){{else}}{{.Name}}(ts){{end}}{{end}}
+{{define "route"}}
+	{{if IsDeprecated . }}
+		// {{.Name}} is deprecated{{if GetDeprecatedSince . }} since {{GetDeprecatedSince . }}{{end}}: use {{GetReplacedBy . }} instead
+		subRouter.HandleFunc(  "{{GetRestOperationPath . }}", redirectPermanently("{{GetReplacedBy . }}")).Methods("{{GetRestOperationMethod . }}")
+	{{else}}{{if and (HasRequestLogging . ) (HasMaxBodySize . ) }}
+		// the body is limited before it is read for logging{{end}}
+		subRouter.HandleFunc(  "{{GetRestOperationPath . }}", {{template "handler" . }}).Methods("{{GetRestOperationMethod . }}")
+	{{end}}
+	{{if HasBatch . }}
+		subRouter.HandleFunc(  "{{GetBatchEndpoint . }}", batchHandler({{template "handler" . }}, "{{GetRestOperationMethod . }}", {{GetBatchMaxItems . }}, {{GetBatchMaxBodySize . }}, batchWorkers, {{GetBatchWorkers . }})).Methods("{{GetBatchMethod . }}")
+	{{end}}
+{{end}}`
+
+var HandlersTemplate string = routeTemplate + `
 // Generated automatically: do not edit manually
 
 package {{.PackageName}}
 
 import (
-	"encoding/json"
+	"encoding/json"{{if HasMaxBodySizeOperations . }}
+	"errors"{{end}}
 	"fmt"
 	"log"
-	"net/http"
+	"net/http"{{if HasPanicSafeOperations . }}
+	"runtime/debug"{{end}}
 	"strconv"
 
 	"github.com/MarcGrol/microgen/lib/myerrors"
@@ -190,22 +1127,130 @@ import (
 
 func (ts *{{.Name}}) HttpHandler() http.Handler {
 	router := mux.NewRouter().StrictSlash(true)
-	subRouter := router.PathPrefix("{{GetRestServicePath . }}").Subrouter()
+	subRouter := router.PathPrefix("{{GetRestServicePrefix . }}").Subrouter()
+	ts.registerRoutes(subRouter{{if HasBatchOperations . }}, 0{{end}})
+	return router
+}
+
+{{if HasBatchOperations . }}
+// HttpHandlerWithBatchWorkers serves the service like HttpHandler, but processes the items of every batch with the
+// given number of workers instead of the number of its @Batch
+func (ts *{{.Name}}) HttpHandlerWithBatchWorkers(batchWorkers int) http.Handler {
+	router := mux.NewRouter().StrictSlash(true)
+	subRouter := router.PathPrefix("{{GetRestServicePrefix . }}").Subrouter()
+	ts.registerRoutes(subRouter, batchWorkers)
+	return router
+}
+{{end}}
 
+{{if IsSubResource . }}
+// MountOn serves the operations of this sub-resource below the router of its parent-service
+func (ts *{{.Name}}) MountOn(parentRouter *mux.Router) {
+	subRouter := parentRouter.PathPrefix("{{GetSubResourcePath . }}").Subrouter()
+	ts.registerRoutes(subRouter{{if HasBatchOperations . }}, 0{{end}})
+}
+{{end}}
+
+{{if HasCORS . }}
+// cors{{.Name}} is the cross-origin policy of the service
+var cors{{.Name}} = corsPolicy{
+	origins:          []string{ {{GetCORSOrigins . }} },
+	headers:          {{printf "%q" (GetCORSHeaders . )}},
+	maxAge:           {{GetCORSMaxAge . }},
+	allowCredentials: {{IsCORSCredentialsAllowed . }},
+}
+{{end}}
+
+func (ts *{{.Name}}) registerRoutes(subRouter *mux.Router{{if HasBatchOperations . }}, batchWorkers int{{end}}) {
+	{{if HasCORS . }}
+		subRouter.Use(cors{{.Name}}.middleware)
+		{{range GetCORSRoutes . }}
+			subRouter.HandleFunc(  "{{.Path}}", cors{{$structName}}.preflight("{{.Methods}}")).Methods("OPTIONS")
+		{{end}}
+	{{end}}
 	{{range .Operations}}
 		{{if IsRestOperation . }}
-			subRouter.HandleFunc(  "{{GetRestOperationPath . }}", {{.Name}}(ts)).Methods("{{GetRestOperationMethod . }}")
+			{{if not (HasBuildConstraint . ) }}
+				{{template "route" . }}
+			{{end}}
 		{{end}}
 	{{end}}
-	return router
+	{{if HasBuildConstrainedOperations . }}
+		for _, registerRoutes := range conditional{{.Name}}Routes {
+			registerRoutes(ts, subRouter{{if HasBatchOperations . }}, batchWorkers{{end}})
+		}
+	{{end}}
 }
 
+{{if HasBuildConstrainedOperations . }}
+// conditional{{.Name}}Routes is filled by files that are only compiled when their build-constraint is satisfied
+var conditional{{.Name}}Routes []func(ts *{{.Name}}, subRouter *mux.Router{{if HasBatchOperations . }}, batchWorkers int{{end}})
+{{end}}
+
 {{range $idxOper, $oper := .Operations}}
 
 {{if IsRestOperation $oper}}
 func {{$oper.Name}}( service *{{$structName}} ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
+		{{if UsesProblemDetails $ $oper}}
+			// errors are reported as problem details (RFC 7807)
+			handleError := problemHandler({{printf "%q" (GetProblemType $ $oper)}})
+		{{end}}
+		{{if IsPanicSafe $ $oper}}
+			// a panic is reported as an internal error: its stack trace is logged, but never exposed to the client
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					log.Printf("Panic in {{$oper.Name}}: %v\n%s", recovered, debug.Stack())
+					handleError(myerrors.NewInternalError(fmt.Errorf("Internal error")), w)
+				}
+			}()
+		{{end}}
+
+		{{if HasAPIKey . }}
+			// authenticate using api-key
+			{{if IsAPIKeyInQuery . }}
+				apiKey := r.URL.Query().Get("{{GetAPIKeyName . }}")
+			{{else}}
+				apiKey := r.Header.Get("{{GetAPIKeyName . }}")
+			{{end}}
+			if apiKey == "" {
+				handleError(myerrors.NewNotAuthorizedError(fmt.Errorf("Missing api-key '{{GetAPIKeyName . }}'")), w)
+				return
+			}
+			claims, err := APIKeyValidator(service).ValidateKey(r.Context(), apiKey)
+			if err != nil {
+				handleError(myerrors.NewNotAuthorizedError(fmt.Errorf("Invalid api-key:%s", err)), w)
+				return
+			}
+			{{if HasRequiredClaim . }}
+				if !hasAPIKeyClaim(claims, "{{GetRequiredClaimName . }}", "{{GetRequiredClaimValue . }}") {
+					handleError(myerrors.NewNotAuthorizedError(fmt.Errorf("Missing claim '{{GetRequiredClaimName . }}'")), w)
+					return
+				}
+			{{end}}
+			r = r.WithContext(ContextWithAPIKeyClaims(r.Context(), claims))
+		{{end}}
+
+		{{if HasOAuth2 . }}
+			// authenticate using oauth2 bearer-token
+			bearerToken, found := getBearerToken(r)
+			if !found {
+				handleError(myerrors.NewNotAuthorizedError(fmt.Errorf("Missing bearer-token")), w)
+				return
+			}
+			grantedScopes, err := OAuth2Validator(service).ValidateToken(r.Context(), bearerToken)
+			if err != nil {
+				handleError(myerrors.NewNotAuthorizedError(fmt.Errorf("Invalid bearer-token:%s", err)), w)
+				return
+			}
+			missingScopes := getMissingOAuth2Scopes(grantedScopes, []string{ {{GetOAuth2Scopes . }} })
+			if len(missingScopes) > 0 {
+				handleError(myerrors.NewNotAuthorizedError(fmt.Errorf("Missing scopes %v", missingScopes)), w)
+				return
+			}
+			r = r.WithContext(ContextWithOAuth2Scopes(r.Context(), grantedScopes))
+		{{end}}
 
 		pathParams := mux.Vars(r)
 		log.Printf("pathParams:%+v", pathParams)
@@ -237,9 +1282,21 @@ func {{$oper.Name}}( service *{{$structName}} ) http.HandlerFunc {
 		{{if HasInput . }}
 			// read abd parse request body
 			var {{GetInputArgName . }} {{GetInputArgType . }}
-			err = json.NewDecoder(r.Body).Decode( &{{GetInputArgName . }} )
+			{{if HasMaxBodySize . }}r.Body = http.MaxBytesReader(w, r.Body, {{GetMaxBodySize . }})
+			{{end}}err = json.NewDecoder(r.Body).Decode( &{{GetInputArgName . }} )
 			if err != nil {
-				handleError(myerrors.NewInvalidInputError(fmt.Errorf("Error decoding request payload:%s", err)), w)
+				{{if HasMaxBodySize . }}var maxBytesError *http.MaxBytesError
+				if errors.As(err, &maxBytesError) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusRequestEntityTooLarge)
+					json.NewEncoder(w).Encode(struct {
+						ErrorMessage string
+					}{
+						fmt.Sprintf("Request payload exceeds %d bytes", maxBytesError.Limit),
+					})
+					return
+				}
+				{{end}}handleError(myerrors.NewInvalidInputError(fmt.Errorf("Error decoding request payload:%s", err)), w)
 				return
 			}
 		{{end}}
@@ -256,10 +1313,37 @@ func {{$oper.Name}}( service *{{$structName}} ) http.HandlerFunc {
 		}
 
 		// write response body
-		{{if HasOutput . }}
+		{{if IsStreamResponse . }}
+			// write every item as soon as it is available: without a content-length the response is chunked
+			w.Header().Set("Content-Type", "{{GetStreamContentType . }}")
+			w.WriteHeader(http.StatusOK)
+			flusher, _ := w.(http.Flusher)
+			encoder := json.NewEncoder(w)
+			for {
+				select {
+				case <-r.Context().Done():
+					// client disconnected
+					return
+				case item, ok := <-result:
+					if !ok {
+						return
+					}
+					err = encoder.Encode(item)
+					if err != nil {
+						log.Printf("Error encoding streamed response item %+v", err)
+						return
+					}
+					if flusher != nil {
+						flusher.Flush()
+					}
+				}
+			}
+		{{else if IsCacheable . }}
+			writeCacheableResponse(w, r, {{GetCacheMaxAge . }}, {{if ReturnsLinkedResource . }}withLinks(result{{GetResponseFieldSelector . }}){{else}}result{{GetResponseFieldSelector . }}{{end}})
+		{{else if HasOutput . }}
 			w.WriteHeader(http.StatusOK)
 			w.Header().Set("Content-Type", "application/json")
-			err = json.NewEncoder(w).Encode(result)
+			err = json.NewEncoder(w).Encode({{if ReturnsLinkedResource . }}withLinks(result{{GetResponseFieldSelector . }}){{else}}result{{GetResponseFieldSelector . }}{{end}})
 			if err != nil {
 				log.Printf("Error encoding response payload %+v", err)
 			}
@@ -278,13 +1362,14 @@ func handleError(err error, w http.ResponseWriter) {
 	}{
 		err.Error(),
 	}
-	blob, err := json.Marshal(errorBody)
-	if err != nil {
-		log.Printf("Error marshalling error response payload %+v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	blob, marshalErr := json.Marshal(errorBody)
+	if marshalErr != nil {
+		log.Printf("Error marshalling error response payload %+v", marshalErr)
+		http.Error(w, marshalErr.Error(), http.StatusInternalServerError)
+		return
 	}
-	w.WriteHeader(determineHttpCode(err))
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(determineHttpCode(err))
 	w.Write(blob)
 }
 
@@ -304,6 +1389,26 @@ func determineHttpCode(err error) int {
 
 `
 
+var BuildConstrainedRoutesTemplate string = routeTemplate + `
+//go:build {{.Constraint}}
+
+// Generated automatically: do not edit manually
+
+package {{.Service.PackageName}}
+
+import (
+	"github.com/gorilla/mux"
+)
+
+func init() {
+	conditional{{.Service.Name}}Routes = append(conditional{{.Service.Name}}Routes, func(ts *{{.Service.Name}}, subRouter *mux.Router{{if HasBatchOperations .Service }}, batchWorkers int{{end}}) {
+	{{range .Operations}}
+		{{template "route" . }}
+	{{end}}
+	})
+}
+`
+
 var HelpersTemplate string = `
 // Generated automatically: do not edit manually
 
@@ -321,7 +1426,7 @@ import (
 {{range .Operations}}
 
 {{if IsRestOperation . }}
-func {{.Name}}TestHelper(url string {{if HasInput . }}, input {{GetInputArgType . }} {{end}} )  (int {{if HasOutput . }},*{{GetOutputArgType . }}{{end}},error) {
+func {{.Name}}TestHelper(url string {{if HasInput . }}, input {{GetInputArgType . }} {{end}} )  (int {{if IsStreamResponse . }},[]{{GetOutputArgType . }}{{else if HasOutput . }},*{{GetOutputArgType . }}{{end}},error) {
 
 	recorder := httptest.NewRecorder()
 
@@ -345,7 +1450,19 @@ func {{.Name}}TestHelper(url string {{if HasInput . }}, input {{GetInputArgType
 	webservice := {{$structName}}{}
 	webservice.HttpHandler().ServeHTTP(recorder, req)
 
-	{{if HasOutput . }}
+	{{if IsStreamResponse . }}
+		resp := []{{GetOutputArgType . }}{}
+		dec := json.NewDecoder(recorder.Body)
+		for dec.More() {
+			var item {{GetOutputArgType . }}
+			err = dec.Decode(&item)
+			if err != nil {
+				return recorder.Code, nil, err
+			}
+			resp = append(resp, item)
+		}
+		return recorder.Code, resp, nil
+	{{else if HasOutput . }}
 		var resp {{GetOutputArgType . }}
 		dec := json.NewDecoder(recorder.Body)
 		err = dec.Decode(&resp)
@@ -360,3 +1477,297 @@ func {{.Name}}TestHelper(url string {{if HasInput . }}, input {{GetInputArgType
 {{end}}
 {{end}}
 `
+
+var APIKeyTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"context"
+	"strings"
+)
+
+// APIKeyValidator must be implemented by every service that has operations annotated with @APIKey
+type APIKeyValidator interface {
+	ValidateKey(ctx context.Context, key string) (claims map[string]string, err error)
+}
+
+type apiKeyClaimsKeyType int
+
+const apiKeyClaimsKey apiKeyClaimsKeyType = 0
+
+func ContextWithAPIKeyClaims(ctx context.Context, claims map[string]string) context.Context {
+	return context.WithValue(ctx, apiKeyClaimsKey, claims)
+}
+
+func GetAPIKeyClaims(ctx context.Context) (map[string]string, bool) {
+	claims, ok := ctx.Value(apiKeyClaimsKey).(map[string]string)
+	return claims, ok
+}
+
+func hasAPIKeyClaim(claims map[string]string, name string, value string) bool {
+	for _, v := range strings.Fields(claims[name]) {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+`
+
+var OAuth2Template string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// OAuth2Validator must be implemented by every service that has operations annotated with @OAuth2
+type OAuth2Validator interface {
+	ValidateToken(ctx context.Context, token string) (scopes []string, err error)
+}
+
+type oauth2ScopesKeyType int
+
+const oauth2ScopesKey oauth2ScopesKeyType = 0
+
+func ContextWithOAuth2Scopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, oauth2ScopesKey, scopes)
+}
+
+func GetOAuth2Scopes(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(oauth2ScopesKey).([]string)
+	return scopes, ok
+}
+
+func getBearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(prefix):])
+	return token, token != ""
+}
+
+func getMissingOAuth2Scopes(granted []string, required []string) []string {
+	missing := []string{}
+	for _, r := range required {
+		found := false
+		for _, g := range granted {
+			if g == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+`
+
+var RequestLoggingTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type statusRecordingResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecordingResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush keeps streaming responses working when they are logged
+func (w *statusRecordingResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func withRequestLogging(level string, includeBody bool, sensitiveFields []string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		var body []byte
+		if includeBody && r.Body != nil {
+			var err error
+			body, err = ioutil.ReadAll(r.Body)
+			r.Body = replayBody(body, err)
+		}
+
+		recorder := &statusRecordingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next(recorder, r)
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if includeBody {
+			attrs = append(attrs, slog.String("body", redactBody(body, sensitiveFields)))
+		}
+		slog.LogAttrs(r.Context(), toLogLevel(level), "request", attrs...)
+	}
+}
+
+// withMaxBodySize limits the request body of an operation with a @MaxBodySize before the body is read for logging
+func withMaxBodySize(maxBytes int64, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		next(w, r)
+	}
+}
+
+// replayBody lets the handler read the body that has been read for logging, followed by the error that ended the
+// reading, like the *http.MaxBytesError of a body that is too large
+func replayBody(body []byte, err error) io.ReadCloser {
+	if err == nil {
+		return ioutil.NopCloser(bytes.NewReader(body))
+	}
+	return ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), failingReader{err: err}))
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func redactBody(body []byte, sensitiveFields []string) string {
+	if len(sensitiveFields) == 0 {
+		return string(body)
+	}
+	var fields map[string]interface{}
+	err := json.Unmarshal(body, &fields)
+	if err != nil {
+		return "[UNPARSEABLE]"
+	}
+	for _, name := range sensitiveFields {
+		if _, found := fields[name]; found {
+			fields[name] = "[REDACTED]"
+		}
+	}
+	redacted, err := json.Marshal(fields)
+	if err != nil {
+		return "[UNPARSEABLE]"
+	}
+	return string(redacted)
+}
+
+func toLogLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+`
+
+var CachingTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// writeCacheableResponse buffers the response so that an etag can be calculated over it before anything is written
+func writeCacheableResponse(w http.ResponseWriter, r *http.Request, maxAge int, result interface{}) {
+	body, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("Error encoding response payload %+v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	etag := calculateETag(body)
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", maxAge))
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func calculateETag(body []byte) string {
+	hash := sha256.Sum256(body)
+	return fmt.Sprintf("\"%s\"", hex.EncodeToString(hash[:8]))
+}
+
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+`
+
+var DeprecationTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// redirectPermanently redirects deprecated operations to their replacement, filling in the path-params of the original request
+func redirectPermanently(replacedBy string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		location := replacedBy
+		for name, value := range mux.Vars(r) {
+			location = strings.Replace(location, "{"+name+"}", url.PathEscape(value), -1)
+		}
+		if r.URL.RawQuery != "" {
+			location = location + "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, location, http.StatusMovedPermanently)
+	}
+}
+`
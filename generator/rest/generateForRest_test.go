@@ -2,10 +2,13 @@ package rest
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"io/ioutil"
 
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/rest/restAnnotation"
 	"github.com/MarcGrol/golangAnnotations/model"
 	"github.com/stretchr/testify/assert"
 )
@@ -63,3 +66,1346 @@ func TestGenerateForWeb(t *testing.T) {
 	os.Remove("./testData/httpMyServiceHelpers_test.go")
 
 }
+
+func TestGenerateForWebWithAPIKey(t *testing.T) {
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpAPIKey.go")
+
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations:  []*model.Operation{},
+		},
+	}
+
+	s[0].Operations = append(s[0].Operations,
+		&model.Operation{
+			DocLines: []string{
+				"// @APIKey( location = \"query\", paramName = \"key\")",
+				"// @RequireClaim( name = \"scope\", value = \"read:users\")",
+				"// @RestOperation(path = \"/person\", method = \"GET\")",
+			},
+			Name:          "doit",
+			RelatedStruct: &model.Field{TypeName: "MyService"},
+			OutputArgs: []model.Field{
+				{TypeName: "error"},
+			},
+		})
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/person", doit(ts)).Methods("GET")`)
+	assert.Contains(t, string(data), `apiKey := r.URL.Query().Get("key")`)
+	assert.Contains(t, string(data), "claims, err := APIKeyValidator(service).ValidateKey(r.Context(), apiKey)")
+	assert.Contains(t, string(data), `if !hasAPIKeyClaim(claims, "scope", "read:users") {`)
+
+	data, err = ioutil.ReadFile("./testData/httpAPIKey.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "type APIKeyValidator interface {")
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpAPIKey.go")
+}
+
+func TestGenerateClientForWeb(t *testing.T) {
+	os.RemoveAll("./testData/client")
+
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\", client = \"true\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations:  []*model.Operation{},
+		},
+	}
+
+	s[0].Operations = append(s[0].Operations,
+		&model.Operation{
+			DocLines:      []string{"// @RestOperation(path = \"/person/{uid}\", method = \"PUT\")"},
+			Name:          "updatePerson",
+			RelatedStruct: &model.Field{TypeName: "MyService"},
+			InputArgs: []model.Field{
+				{Name: "uid", TypeName: "string"},
+				{Name: "person", TypeName: "Person"},
+			},
+			OutputArgs: []model.Field{
+				{TypeName: "Person", IsSlice: true},
+				{TypeName: "error"},
+			},
+		})
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/client/httpMyServiceClient.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "type MyServiceClient struct {")
+	assert.Contains(t, string(data), "func (c *MyServiceClient) UpdatePerson(uid string, person testData.Person) ([]testData.Person, error) {")
+	assert.Contains(t, string(data), `req, err := newRequest("PUT", c.BaseURL+"/api/person/{uid}", pathParams, person)`)
+
+	_, err = os.Stat("./testData/client/httpClient.go")
+	assert.NoError(t, err)
+
+	os.RemoveAll("./testData/client")
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+}
+
+func TestGenerateForWebWithRequestLogging(t *testing.T) {
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpRequestLogging.go")
+
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations:  []*model.Operation{},
+		},
+		{
+			PackageName: "testData",
+			Name:        "Credentials",
+			Fields: []model.Field{
+				{Name: "Username", TypeName: "string"},
+				{Name: "Password", TypeName: "string", Tag: "`json:\"pwd,omitempty\"`", DocLines: []string{"// @Sensitive()"}},
+			},
+		},
+	}
+
+	s[0].Operations = append(s[0].Operations,
+		&model.Operation{
+			DocLines: []string{
+				"// @RequestLogging( level = \"warn\", includeBody = true)",
+				"// @RestOperation(path = \"/login\", method = \"POST\")",
+			},
+			Name:          "login",
+			RelatedStruct: &model.Field{TypeName: "MyService"},
+			InputArgs: []model.Field{
+				{Name: "credentials", TypeName: "Credentials"},
+			},
+			OutputArgs: []model.Field{
+				{TypeName: "error"},
+			},
+		},
+		&model.Operation{
+			DocLines: []string{
+				"// @RequestLogging( includeBody = true)",
+				"// @MaxBodySize( bytes = 1024 )",
+				"// @RestOperation(path = \"/signup\", method = \"POST\")",
+			},
+			Name:          "signup",
+			RelatedStruct: &model.Field{TypeName: "MyService"},
+			InputArgs: []model.Field{
+				{Name: "credentials", TypeName: "Credentials"},
+			},
+			OutputArgs: []model.Field{
+				{TypeName: "error"},
+			},
+		})
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/login", withRequestLogging("warn", true, []string{ "pwd" }, login(ts))).Methods("POST")`)
+
+	// the body is limited before the logging reads it
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/signup", withMaxBodySize(1024, withRequestLogging("info", true, []string{ "pwd" }, signup(ts)))).Methods("POST")`)
+
+	data, err = ioutil.ReadFile("./testData/httpRequestLogging.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "func withRequestLogging(level string, includeBody bool, sensitiveFields []string, next http.HandlerFunc) http.HandlerFunc {")
+	assert.Contains(t, string(data), "r.Body = replayBody(body, err)")
+	assert.Contains(t, string(data), "func withMaxBodySize(maxBytes int64, next http.HandlerFunc) http.HandlerFunc {")
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpRequestLogging.go")
+}
+
+func TestGenerateForWebWithCacheable(t *testing.T) {
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpCaching.go")
+
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations:  []*model.Operation{},
+		},
+	}
+
+	s[0].Operations = append(s[0].Operations,
+		&model.Operation{
+			DocLines: []string{
+				"// @Cacheable( maxAge = 60 )",
+				"// @RestOperation(path = \"/person/{uid}\", method = \"GET\")",
+			},
+			Name:          "getPerson",
+			RelatedStruct: &model.Field{TypeName: "MyService"},
+			InputArgs: []model.Field{
+				{Name: "uid", TypeName: "string"},
+			},
+			OutputArgs: []model.Field{
+				{TypeName: "Person"},
+				{TypeName: "error"},
+			},
+		})
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "writeCacheableResponse(w, r, 60, result)")
+
+	data, err = ioutil.ReadFile("./testData/httpCaching.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "func writeCacheableResponse(w http.ResponseWriter, r *http.Request, maxAge int, result interface{}) {")
+	assert.Contains(t, string(data), "w.WriteHeader(http.StatusNotModified)")
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpCaching.go")
+}
+
+func TestGenerateForWebWithCacheableNonGet(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// @Cacheable( maxAge = 60 )",
+						"// @RestOperation(path = \"/person\", method = \"POST\")",
+					},
+					Name:          "createPerson",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs: []model.Field{
+						{Name: "person", TypeName: "Person"},
+					},
+					OutputArgs: []model.Field{
+						{TypeName: "Person"},
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Error(t, err)
+}
+
+func TestGenerateForWebWithStreamResponse(t *testing.T) {
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations:  []*model.Operation{},
+		},
+	}
+
+	s[0].Operations = append(s[0].Operations,
+		&model.Operation{
+			DocLines: []string{
+				"// @StreamResponse()",
+				"// @RestOperation(path = \"/person\", method = \"GET\")",
+			},
+			Name:          "streamPersons",
+			RelatedStruct: &model.Field{TypeName: "MyService"},
+			OutputArgs: []model.Field{
+				{TypeName: "Person", IsChannel: true},
+				{TypeName: "error"},
+			},
+		})
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `w.Header().Set("Content-Type", "application/x-ndjson")`)
+	assert.Contains(t, string(data), "case <-r.Context().Done():")
+	assert.Contains(t, string(data), "flusher.Flush()")
+
+	data, err = ioutil.ReadFile("./testData/httpMyServiceHelpers_test.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "func streamPersonsTestHelper(url string  )  (int ,[]Person,error) {")
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+}
+
+func TestGenerateForWebWithStreamResponseWithoutChannel(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// @StreamResponse()",
+						"// @RestOperation(path = \"/person\", method = \"GET\")",
+					},
+					Name:          "getPersons",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					OutputArgs: []model.Field{
+						{TypeName: "Person", IsSlice: true},
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Error(t, err)
+}
+
+func TestGenerateForWebWithDeprecated(t *testing.T) {
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpDeprecation.go")
+
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations:  []*model.Operation{},
+		},
+	}
+
+	s[0].Operations = append(s[0].Operations,
+		&model.Operation{
+			DocLines: []string{
+				"// @Deprecated( replacedBy = \"/api/v2/users/{id}\", since = \"v2.0\" )",
+				"// @RestOperation(path = \"/users/{id}\", method = \"GET\")",
+			},
+			Name:          "getUser",
+			RelatedStruct: &model.Field{TypeName: "MyService"},
+			InputArgs: []model.Field{
+				{Name: "id", TypeName: "string"},
+			},
+			OutputArgs: []model.Field{
+				{TypeName: "User"},
+				{TypeName: "error"},
+			},
+		})
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "// getUser is deprecated since v2.0: use /api/v2/users/{id} instead")
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/users/{id}", redirectPermanently("/api/v2/users/{id}")).Methods("GET")`)
+
+	data, err = ioutil.ReadFile("./testData/httpDeprecation.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "http.Redirect(w, r, location, http.StatusMovedPermanently)")
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpDeprecation.go")
+}
+
+func TestGenerateForWebWithSubResource(t *testing.T) {
+	os.Remove("./testData/httpOrderService.go")
+	os.Remove("./testData/httpOrderServiceHelpers_test.go")
+	os.Remove("./testData/httpItemService.go")
+	os.Remove("./testData/httpItemServiceHelpers_test.go")
+
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "OrderService",
+		},
+		{
+			DocLines: []string{
+				"// @SubResource( parent = \"OrderService\", parentPath = \"/orders/{orderId}\" )",
+				"// @RestService( path = \"/items\")",
+			},
+			PackageName: "testData",
+			Name:        "ItemService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/{id}\", method = \"GET\")"},
+					Name:          "getItem",
+					RelatedStruct: &model.Field{TypeName: "ItemService"},
+					InputArgs: []model.Field{
+						{Name: "orderId", TypeName: "string"},
+						{Name: "id", TypeName: "string"},
+					},
+					OutputArgs: []model.Field{
+						{TypeName: "Item"},
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpItemService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `subRouter := router.PathPrefix("/api/orders/{orderId}/items").Subrouter()`)
+	assert.Contains(t, string(data), "func (ts *ItemService) MountOn(parentRouter *mux.Router) {")
+	assert.Contains(t, string(data), `subRouter := parentRouter.PathPrefix("/orders/{orderId}/items").Subrouter()`)
+	assert.Contains(t, string(data), `orderId, exists := pathParams["orderId"]`)
+
+	data, err = ioutil.ReadFile("./testData/httpOrderService.go")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "MountOn")
+
+	os.Remove("./testData/httpOrderService.go")
+	os.Remove("./testData/httpOrderServiceHelpers_test.go")
+	os.Remove("./testData/httpItemService.go")
+	os.Remove("./testData/httpItemServiceHelpers_test.go")
+}
+
+func TestGenerateForWebWithInferredMethod(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/person/{uid}\")"},
+					Name:          "deletePerson",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs: []model.Field{
+						{Name: "uid", TypeName: "string"},
+					},
+					OutputArgs: []model.Field{
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/person/{uid}", deletePerson(ts)).Methods("DELETE")`)
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+}
+
+func TestGenerateForWebWithoutMethod(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/person\")"},
+					Name:          "persons",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					OutputArgs: []model.Field{
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Error(t, err)
+}
+
+func TestGenerateForWebWithMaxBodySize(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// @MaxBodySize( bytes = 1048576 )",
+						"// @RestOperation(path = \"/person\", method = \"POST\")",
+					},
+					Name:          "createPerson",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs: []model.Field{
+						{Name: "person", TypeName: "Person"},
+					},
+					OutputArgs: []model.Field{
+						{TypeName: "Person"},
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"errors"`)
+	assert.Contains(t, string(data), "r.Body = http.MaxBytesReader(w, r.Body, 1048576)")
+	assert.Contains(t, string(data), "if errors.As(err, &maxBytesError) {")
+	assert.Contains(t, string(data), "w.WriteHeader(http.StatusRequestEntityTooLarge)")
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+}
+
+func TestGenerateForWebWithInvalidMaxBodySize(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// @MaxBodySize( bytes = 0 )",
+						"// @RestOperation(path = \"/person\", method = \"POST\")",
+					},
+					Name:          "createPerson",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs: []model.Field{
+						{Name: "person", TypeName: "Person"},
+					},
+					OutputArgs: []model.Field{
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Error(t, err)
+}
+
+func TestGenerateForWebWithMaxBodySizeInDescription(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// getPerson needs no @MaxBodySize because it has no body",
+						"// @RestOperation(path = \"/person\", method = \"GET\")",
+					},
+					Name:          "getPerson",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					OutputArgs: []model.Field{
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+}
+
+func TestGenerateWithOwnRegistry(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/person\", method = \"GET\")"},
+					Name:          "getPerson",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					OutputArgs: []model.Field{
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	registry := annotation.NewRegistry()
+	g := NewGenerator(registry)
+	// the annotations are resolved in the registry of the generator only
+	assert.False(t, g.IsRestService(s[0]))
+
+	err := g.Generate("testData", s)
+	assert.Nil(t, err)
+	assert.True(t, registry.IsRegistered("RestService"))
+	assert.True(t, g.IsRestService(s[0]))
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/person", getPerson(ts)).Methods("GET")`)
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+}
+
+func TestGenerateForWebWithRequiredClaimWithoutAPIKey(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// @RequireClaim( name = \"scope\", value = \"read:users\")",
+						"// @RestOperation(path = \"/person\", method = \"GET\")",
+					},
+					Name:          "getPerson",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					OutputArgs: []model.Field{
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.EqualError(t, err, "Operation MyService.getPerson has a @RequireClaim but no @APIKey")
+}
+
+func TestGenerateForWebWithOAuth2(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\", client = \"true\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// @OAuth2( authorizationURL = \"https://auth.example.com/oauth/authorize\", tokenURL = \"https://auth.example.com/oauth/token\", scopes = \"read:users write:users\" )",
+						"// @RestOperation(path = \"/person\", method = \"GET\")",
+					},
+					Name:          "doit",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					OutputArgs: []model.Field{
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "bearerToken, found := getBearerToken(r)")
+	assert.Contains(t, string(data), "grantedScopes, err := OAuth2Validator(service).ValidateToken(r.Context(), bearerToken)")
+	assert.Contains(t, string(data), `missingScopes := getMissingOAuth2Scopes(grantedScopes, []string{ "read:users", "write:users" })`)
+
+	data, err = ioutil.ReadFile("./testData/httpOAuth2.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "type OAuth2Validator interface {")
+
+	data, err = ioutil.ReadFile("./testData/client/httpMyServiceClient.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `req.Header.Set("Authorization", "Bearer "+c.AccessToken)`)
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpOAuth2.go")
+	os.RemoveAll("./testData/client")
+}
+
+func TestGenerateForWebWithLinks(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/order/{uid}\", method = \"GET\")"},
+					Name:          "getOrder",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs: []model.Field{
+						{Name: "uid", TypeName: "string"},
+					},
+					OutputArgs: []model.Field{
+						{TypeName: "Order"},
+						{TypeName: "error"},
+					},
+				},
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/order\", method = \"GET\")"},
+					Name:          "getOrders",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					OutputArgs: []model.Field{
+						{TypeName: "Order", IsSlice: true},
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+		{
+			DocLines: []string{
+				"// @Link( rel = \"self\", href = \"/api/order/{id}\" )",
+				"// @Link( rel = \"customer\", href = \"/api/customer/{customerUID}\" )",
+				"// @Link( rel = \"orders\", href = \"/api/order\" )",
+				"// @LinkParam( field = \"OrderID\" )",
+			},
+			PackageName: "testData",
+			Name:        "Order",
+			Fields: []model.Field{
+				{Name: "OrderID", TypeName: "string"},
+				{Name: "CustomerUID", TypeName: "string"},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "err = json.NewEncoder(w).Encode(withLinks(result))")
+	// links are only added to individual resources
+	assert.Contains(t, string(data), "err = json.NewEncoder(w).Encode(result)")
+
+	data, err = ioutil.ReadFile("./testData/httpLinks.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "func (resource Order) Links() map[string]string {")
+	assert.Contains(t, string(data), `"self": "/api/order/" + url.PathEscape(fmt.Sprint(resource.OrderID)),`)
+	assert.Contains(t, string(data), `"customer": "/api/customer/" + url.PathEscape(fmt.Sprint(resource.CustomerUID)),`)
+	assert.Contains(t, string(data), `"orders": "/api/order",`)
+	assert.Contains(t, string(data), "func (l linkedResource) MarshalJSON() ([]byte, error) {")
+	// a nil resource is encoded as null, without calling Links on it
+	assert.Contains(t, string(data), "if string(blob) == \"null\" {\n\t\t// a nil resource has no links\n\t\treturn blob, nil\n\t}")
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpLinks.go")
+}
+
+func TestGenerateForWebWithUnknownLinkParam(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @Link( rel = \"self\", href = \"/api/order/{id}\" )"},
+			PackageName: "testData",
+			Name:        "Order",
+			Fields: []model.Field{
+				{Name: "OrderID", TypeName: "string"},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no field for path-parameter {id}")
+}
+
+func TestGenerateForWebWithBuildConstraint(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/person\", method = \"GET\")"},
+					Name:          "getPerson",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					OutputArgs: []model.Field{
+						{TypeName: "error"},
+					},
+				},
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/debug\", method = \"GET\", buildConstraint = \"+build !prod\")"},
+					Name:          "getDebug",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					OutputArgs: []model.Field{
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/person", getPerson(ts)).Methods("GET")`)
+	assert.NotContains(t, string(data), `subRouter.HandleFunc(  "/debug"`)
+	assert.Contains(t, string(data), "for _, registerRoutes := range conditionalMyServiceRoutes {")
+	assert.Contains(t, string(data), "var conditionalMyServiceRoutes []func(ts *MyService, subRouter *mux.Router)")
+
+	data, err = ioutil.ReadFile("./testData/httpMyServiceRoutesNotProd.go")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(strings.TrimSpace(string(data)), "//go:build !prod"))
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/debug", getDebug(ts)).Methods("GET")`)
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpMyServiceRoutesNotProd.go")
+}
+
+func TestGenerateForWebWithInvalidBuildConstraint(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/debug\", method = \"GET\", buildConstraint = \"prod &&\")"},
+					Name:          "getDebug",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					OutputArgs: []model.Field{
+						{TypeName: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Operation MyService.getDebug has an invalid build-constraint")
+}
+
+func TestBuildConstraintFileSuffix(t *testing.T) {
+	assert.Equal(t, "Prod", buildConstraintFileSuffix("prod"))
+	assert.Equal(t, "ProdAndNotDebug", buildConstraintFileSuffix("prod && !debug"))
+	assert.Equal(t, "LinuxOrGo1_21", buildConstraintFileSuffix("(linux || go1.21)"))
+}
+
+func TestGenerateForWebWithCORS(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines: []string{
+				"// @CORS( origins = \"https://example.com, https://example.org\", maxAge = 600 )",
+				"// @RestService( path = \"/api\")",
+			},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/users/{id}\", method = \"GET\")"},
+					Name:          "getUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "id", TypeName: "string"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/users/{uid}\", method = \"PUT\")"},
+					Name:          "updateUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "uid", TypeName: "string"}, {Name: "user", TypeName: "User"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/users\", method = \"POST\")"},
+					Name:          "createUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "user", TypeName: "User"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `origins:          []string{ "https://example.com", "https://example.org" },`)
+	assert.Contains(t, string(data), `headers:          "Content-Type, Authorization",`)
+	assert.Contains(t, string(data), `maxAge:           600,`)
+	assert.Contains(t, string(data), `subRouter.Use(corsMyService.middleware)`)
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/users/{id}", corsMyService.preflight("GET, PUT, OPTIONS")).Methods("OPTIONS")`)
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/users", corsMyService.preflight("POST, OPTIONS")).Methods("OPTIONS")`)
+	assert.NotContains(t, string(data), `"/users/{uid}", corsMyService.preflight`)
+
+	data, err = ioutil.ReadFile("./testData/httpCORS.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `w.Header().Set("Access-Control-Allow-Methods", methods)`)
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpCORS.go")
+}
+
+func TestGenerateForWebWithCORSCredentialsForAnyOrigin(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines: []string{
+				"// @CORS( allowCredentials = \"true\" )",
+				"// @RestService( path = \"/api\")",
+			},
+			PackageName: "testData",
+			Name:        "MyService",
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Service MyService has a @CORS that allows credentials for any origin")
+}
+
+func TestGenerateForWebWithBatch(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// @Batch( endpoint = \"/users/batch\", maxItems = 100, workers = 8 )",
+						"// @RestOperation(path = \"/users\", method = \"POST\")",
+					},
+					Name:          "createUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "user", TypeName: "User"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/users/batch", batchHandler(createUser(ts), "POST", 100, 0, batchWorkers, 8)).Methods("POST")`)
+	// the number of workers can be set when the handler is built
+	assert.Contains(t, string(data), "func (ts *MyService) HttpHandlerWithBatchWorkers(batchWorkers int) http.Handler {")
+	assert.Contains(t, string(data), "ts.registerRoutes(subRouter, 0)")
+	assert.Contains(t, string(data), "func (ts *MyService) registerRoutes(subRouter *mux.Router, batchWorkers int) {")
+
+	data, err = ioutil.ReadFile("./testData/httpBatch.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `func batchHandler(operation http.HandlerFunc, method string, maxItems int, maxBytes int64, workers int, annotatedWorkers int) http.HandlerFunc {`)
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpBatch.go")
+}
+
+func TestGenerateForWebWithCORSAndBatch(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines: []string{
+				"// @CORS( origins = \"https://example.com\" )",
+				"// @RestService( path = \"/api\")",
+			},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// @Batch( endpoint = \"/users/batch\", maxItems = 100 )",
+						"// @RestOperation(path = \"/users\", method = \"POST\")",
+					},
+					Name:          "createUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "user", TypeName: "User"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/users", corsMyService.preflight("POST, OPTIONS")).Methods("OPTIONS")`)
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/users/batch", corsMyService.preflight("POST, OPTIONS")).Methods("OPTIONS")`)
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpCORS.go")
+	os.Remove("./testData/httpBatch.go")
+}
+
+func TestGenerateForWebWithBatchWithRequestLoggingAndMaxBodySize(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// @Batch( endpoint = \"/users/batch\", maxItems = 100 )",
+						"// @RequestLogging( level = \"debug\" )",
+						"// @MaxBodySize( bytes = 1024 )",
+						"// @RestOperation(path = \"/users\", method = \"POST\")",
+					},
+					Name:          "createUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "user", TypeName: "User"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	// every item is logged and limited like a single request, the batch as a whole is limited to maxItems items
+	assert.Contains(t, string(data), `subRouter.HandleFunc(  "/users/batch", batchHandler(withMaxBodySize(1024, withRequestLogging("debug", false, []string{  }, createUser(ts))), "POST", 100, 102400, batchWorkers, 4)).Methods("POST")`)
+
+	data, err = ioutil.ReadFile("./testData/httpBatch.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "r.Body = http.MaxBytesReader(w, r.Body, maxBytes)")
+	assert.Contains(t, string(data), "w.WriteHeader(http.StatusRequestEntityTooLarge)")
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpBatch.go")
+	os.Remove("./testData/httpRequestLogging.go")
+}
+
+func TestGenerateForWebWithBatchWithPathParameter(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// @Batch( endpoint = \"/users/batch\", maxItems = 100 )",
+						"// @RestOperation(path = \"/users/{uid}\", method = \"PUT\")",
+					},
+					Name:          "updateUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "uid", TypeName: "string"}, {Name: "user", TypeName: "User"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Operation MyService.updateUser has a @Batch but takes path-parameter 'uid'")
+}
+
+func TestGenerateForWebWithResponseField(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/users/{id}\", method = \"GET\", responseField = \"Data\")"},
+					Name:          "getUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "id", TypeName: "string"}},
+					OutputArgs:    []model.Field{{TypeName: "UserEnvelope"}, {TypeName: "error"}},
+				},
+			},
+		},
+		{
+			PackageName: "testData",
+			Name:        "UserEnvelope",
+			Fields: []model.Field{
+				{Name: "Data", TypeName: "User"},
+				{Name: "TraceID", TypeName: "string"},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "err = json.NewEncoder(w).Encode(result.Data)")
+
+	data, err = ioutil.ReadFile("./testData/httpMyServiceHelpers_test.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "func getUserTestHelper(url string  )  (int ,*User,error) {")
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+}
+
+func TestGenerateForWebWithUnknownResponseField(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/users/{id}\", method = \"GET\", responseField = \"Payload\")"},
+					Name:          "getUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "id", TypeName: "string"}},
+					OutputArgs:    []model.Field{{TypeName: "UserEnvelope"}, {TypeName: "error"}},
+				},
+			},
+		},
+		{
+			PackageName: "testData",
+			Name:        "UserEnvelope",
+			Fields:      []model.Field{{Name: "Data", TypeName: "User"}},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Operation MyService.getUser has an invalid responseField: struct UserEnvelope has no field Payload")
+}
+
+func TestGenerateForWebWithProblemDetails(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines: []string{
+				"// @ProblemDetails( type = \"https://example.com/errors\" )",
+				"// @RestService( path = \"/api\")",
+			},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/users/{id}\", method = \"GET\")"},
+					Name:          "getUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "id", TypeName: "string"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+				{
+					DocLines: []string{
+						"// @ProblemDetails( type = \"https://example.com/errors/users\" )",
+						"// @RestOperation(path = \"/users\", method = \"POST\")",
+					},
+					Name:          "createUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "user", TypeName: "User"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `handleError := problemHandler("https://example.com/errors")`)
+	assert.Contains(t, string(data), `handleError := problemHandler("https://example.com/errors/users")`)
+
+	data, err = ioutil.ReadFile("./testData/httpProblem.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "func ProblemWriter(w http.ResponseWriter, prob Problem) {")
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpProblem.go")
+}
+
+func TestGenerateForWebWithPanicSafe(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// @PanicSafe()",
+						"// @ProblemDetails()",
+						"// @RestOperation(path = \"/users/{id}\", method = \"GET\")",
+					},
+					Name:          "getUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "id", TypeName: "string"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/users\", method = \"POST\")"},
+					Name:          "createUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "user", TypeName: "User"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyService.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"runtime/debug"`)
+	assert.Contains(t, string(data), `log.Printf("Panic in getUser: %v\n%s", recovered, debug.Stack())`)
+	assert.NotContains(t, string(data), `Panic in createUser`)
+	// the panic is reported as a problem, because the problem-handler replaces handleError
+	assert.Contains(t, string(data), `handleError(myerrors.NewInternalError(fmt.Errorf("Internal error")), w)`)
+
+	os.Remove("./testData/httpMyService.go")
+	os.Remove("./testData/httpMyServiceHelpers_test.go")
+	os.Remove("./testData/httpProblem.go")
+}
+
+func TestIsPanicSafe(t *testing.T) {
+	restAnnotation.Register()
+
+	service := model.Struct{DocLines: []string{"// @PanicSafe()", "// @RestService( path = \"/api\")"}}
+	operation := model.Operation{DocLines: []string{"// @RestOperation(path = \"/users\", method = \"GET\")"}}
+	assert.True(t, IsPanicSafe(service, operation))
+	assert.False(t, IsPanicSafe(model.Struct{}, operation))
+
+	operation.DocLines = append(operation.DocLines, "// @PanicSafe()")
+	assert.True(t, IsPanicSafe(model.Struct{}, operation))
+}
+
+func TestGenerateForWebWithPathConflict(t *testing.T) {
+	os.Remove("./testData/httpMyService.go")
+
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/users/{id}\", method = \"GET\")"},
+					Name:          "getUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "id", TypeName: "string"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/users/{uid}\", method = \"get\")"},
+					Name:          "getUserByUid",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "uid", TypeName: "string"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.Equal(t, ConflictError{Service: "MyService", Method: "GET", Path: "/api/users/{uid}", First: "getUser", Second: "getUserByUid"}, err)
+	assert.EqualError(t, err, "Operations getUser and getUserByUid of service MyService are both served on GET /api/users/{uid}")
+
+	// nothing is written
+	_, err = os.Stat("./testData/httpMyService.go")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGenerateForWebWithBatchPathConflict(t *testing.T) {
+	s := []model.Struct{
+		{
+			DocLines:    []string{"// @RestService( path = \"/api\")"},
+			PackageName: "testData",
+			Name:        "MyService",
+			Operations: []*model.Operation{
+				{
+					DocLines: []string{
+						"// @Batch( endpoint = \"/users/batch\", maxItems = 100 )",
+						"// @RestOperation(path = \"/users\", method = \"POST\")",
+					},
+					Name:          "createUser",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "user", TypeName: "User"}},
+					OutputArgs:    []model.Field{{TypeName: "User"}, {TypeName: "error"}},
+				},
+				{
+					DocLines:      []string{"// @RestOperation(path = \"/users/batch\", method = \"POST\")"},
+					Name:          "importUsers",
+					RelatedStruct: &model.Field{TypeName: "MyService"},
+					InputArgs:     []model.Field{{Name: "users", TypeName: "User", IsSlice: true}},
+					OutputArgs:    []model.Field{{TypeName: "error"}},
+				},
+			},
+		},
+	}
+
+	err := Generate("testData", s)
+	assert.EqualError(t, err, "Operations batch of createUser and importUsers of service MyService are both served on POST /api/users/batch")
+}
+
+func TestValidatePathConflicts(t *testing.T) {
+	restAnnotation.Register()
+
+	operation := func(name string, path string, docLines ...string) *model.Operation {
+		return &model.Operation{
+			DocLines: append(docLines, "// @RestOperation(path = \""+path+"\", method = \"GET\")"),
+			Name:     name,
+		}
+	}
+	service := model.Struct{
+		DocLines: []string{"// @RestService( path = \"/api\")"},
+		Name:     "MyService",
+		Operations: []*model.Operation{
+			// the regular expressions of the path-parameters distinguish the routes
+			operation("getUser", "/users/{id:[0-9]+}"),
+			operation("getUserByName", "/users/{name:[a-z]+}"),
+			// operations with disjoint build-constraints are never compiled together
+			operation("getDebug", "/debug", "// @RestOperation(path = \"/debug\", method = \"GET\", buildConstraint = \"!prod\")"),
+			operation("getProdDebug", "/debug", "// @RestOperation(path = \"/debug\", method = \"GET\", buildConstraint = \"prod\")"),
+		},
+	}
+	assert.NoError(t, DefaultGenerator.validatePathConflicts(service, []model.Struct{service}))
+
+	// different build-constraints can be satisfied together: go build -tags dev,prod
+	service.Operations[3] = operation("getDevDebug", "/debug", "// @RestOperation(path = \"/debug\", method = \"GET\", buildConstraint = \"dev\")")
+	assert.EqualError(t, DefaultGenerator.validatePathConflicts(service, []model.Struct{service}), "Operations getDebug and getDevDebug of service MyService are both served on GET /api/debug")
+	service.Operations[3] = operation("getDevDebug", "/debug", "// @RestOperation(path = \"/debug\", method = \"GET\", buildConstraint = \"dev && prod\")")
+	assert.NoError(t, DefaultGenerator.validatePathConflicts(service, []model.Struct{service}))
+	service.Operations[3] = operation("getProdDebug", "/debug", "// @RestOperation(path = \"/debug\", method = \"GET\", buildConstraint = \"prod\")")
+
+	// an operation without build-constraint is always compiled
+	service.Operations = append(service.Operations, operation("getAnyDebug", "/debug"))
+	assert.EqualError(t, DefaultGenerator.validatePathConflicts(service, []model.Struct{service}), "Operations getDebug and getAnyDebug of service MyService are both served on GET /api/debug")
+}
@@ -0,0 +1,217 @@
+package rest
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+type LinksData struct {
+	PackageName string
+	HasParams   bool
+	Resources   []LinkedResource
+}
+
+type LinkedResource struct {
+	Name  string
+	Links []Link
+}
+
+// Link is a hypermedia-link of a resource: HrefExpression is the go-expression that fills in the path-parameters
+type Link struct {
+	Rel            string
+	HrefExpression string
+}
+
+var linkParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+func (g *Generator) generateLinks(targetDir string, packageName string, structs []model.Struct) error {
+	data := LinksData{PackageName: packageName}
+	for _, s := range structs {
+		if !g.HasLinks(s) {
+			continue
+		}
+		links, err := g.getLinks(s)
+		if err != nil {
+			return err
+		}
+		for _, l := range g.registry.GetAll(s.DocLines, "Link") {
+			if linkParamPattern.MatchString(l.Attributes["href"]) {
+				data.HasParams = true
+			}
+		}
+		data.Resources = append(data.Resources, LinkedResource{Name: s.Name, Links: links})
+	}
+	if len(data.Resources) == 0 {
+		return nil
+	}
+
+	target := fmt.Sprintf("%s/httpLinks.go", targetDir)
+	err := generationUtil.GenerateFileFromTemplate(data, "links", LinksTemplate, g.templateFuncs(), target)
+	if err != nil {
+		log.Fatalf("Error generating links: %s", err)
+		return err
+	}
+	return nil
+}
+
+// validateLinks makes sure every path-parameter of a @Link refers to an existing field
+func (g *Generator) validateLinks(structs []model.Struct) error {
+	for _, s := range structs {
+		if !g.HasLinks(s) {
+			continue
+		}
+		_, err := g.getLinks(s)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Generator) HasLinks(s model.Struct) bool {
+	_, ok := g.registry.ResolveAnnotationByName(s.DocLines, "Link")
+	return ok
+}
+
+// ReturnsLinkedResource tells if the response of the operation is a struct with links, which are added to the response
+func (g *Generator) ReturnsLinkedResource(o model.Operation, structs []model.Struct) bool {
+	for _, arg := range o.OutputArgs {
+		if arg.TypeName == "error" || arg.IsSlice || arg.IsChannel || arg.PackageQualifier != "" {
+			continue
+		}
+		for _, s := range structs {
+			if s.Name == arg.TypeName && g.HasLinks(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *Generator) getLinks(s model.Struct) ([]Link, error) {
+	links := []Link{}
+	for _, l := range g.registry.GetAll(s.DocLines, "Link") {
+		expression, err := g.getHrefExpression(s, l.Attributes["href"])
+		if err != nil {
+			return links, fmt.Errorf("Struct %s has an invalid @Link %s: %s", s.Name, l.Attributes["rel"], err)
+		}
+		links = append(links, Link{Rel: l.Attributes["rel"], HrefExpression: expression})
+	}
+	return links, nil
+}
+
+// getHrefExpression converts an href like /orders/{id} into "/orders/" + url.PathEscape(fmt.Sprint(resource.ID))
+func (g *Generator) getHrefExpression(s model.Struct, href string) (string, error) {
+	parts := []string{}
+	pos := 0
+	for _, match := range linkParamPattern.FindAllStringSubmatchIndex(href, -1) {
+		if literal := href[pos:match[0]]; literal != "" {
+			parts = append(parts, strconv.Quote(literal))
+		}
+		paramName := href[match[2]:match[3]]
+		fieldName, found := g.getLinkParamField(s, paramName)
+		if !found {
+			return "", fmt.Errorf("no field for path-parameter {%s}", paramName)
+		}
+		parts = append(parts, fmt.Sprintf("url.PathEscape(fmt.Sprint(resource.%s))", fieldName))
+		pos = match[1]
+	}
+	if pos < len(href) || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(href[pos:]))
+	}
+	return strings.Join(parts, " + "), nil
+}
+
+// getLinkParamField returns the field that is mapped on the path-parameter with a @LinkParam, or otherwise the field
+// with the same name, ignoring case: {id} maps to the field ID
+func (g *Generator) getLinkParamField(s model.Struct, paramName string) (string, bool) {
+	for _, p := range g.registry.GetAll(s.DocLines, "LinkParam") {
+		name := p.Attributes["name"]
+		if name == "" {
+			name = "id"
+		}
+		if name == paramName {
+			paramName = p.Attributes["field"]
+			break
+		}
+	}
+	for _, f := range s.Fields {
+		if strings.EqualFold(f.Name, paramName) {
+			return f.Name, true
+		}
+	}
+	return "", false
+}
+
+var LinksTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"encoding/json"
+{{if .HasParams}}
+	"fmt"
+	"net/url"
+{{end}}
+)
+
+{{range .Resources}}
+// Links returns the hypermedia-links of the {{.Name}}, with the path-parameters filled in
+func (resource {{.Name}}) Links() map[string]string {
+	return map[string]string{
+	{{range .Links}}
+		"{{.Rel}}": {{.HrefExpression}},
+	{{end}}
+	}
+}
+{{end}}
+
+type linker interface {
+	Links() map[string]string
+}
+
+// linkedResource adds the links of a resource to its json-representation, as the "_links"-property of HAL
+type linkedResource struct {
+	resource linker
+}
+
+func withLinks(resource linker) linkedResource {
+	return linkedResource{resource: resource}
+}
+
+func (l linkedResource) MarshalJSON() ([]byte, error) {
+	blob, err := json.Marshal(l.resource)
+	if err != nil {
+		return nil, err
+	}
+	if string(blob) == "null" {
+		// a nil resource has no links
+		return blob, nil
+	}
+	properties := map[string]json.RawMessage{}
+	err = json.Unmarshal(blob, &properties)
+	if err != nil {
+		return nil, err
+	}
+
+	type halLink struct {
+		Href string ` + "`json:\"href\"`" + `
+	}
+	links := map[string]halLink{}
+	for rel, href := range l.resource.Links() {
+		links[rel] = halLink{Href: href}
+	}
+	properties["_links"], err = json.Marshal(links)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(properties)
+}
+`
@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+// defaultProblemType is the type of a problem that has no further semantics than its http status-code
+const defaultProblemType = "about:blank"
+
+func (g *Generator) generateProblemDetails(targetDir string, packageName string) error {
+	target := fmt.Sprintf("%s/httpProblem.go", targetDir)
+	err := generationUtil.GenerateFileFromTemplate(struct{ PackageName string }{packageName}, "problem", ProblemTemplate, g.templateFuncs(), target)
+	if err != nil {
+		log.Fatalf("Error generating problem-details helpers: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (g *Generator) HasProblemDetailsOperations(s model.Struct) bool {
+	for _, o := range s.Operations {
+		if g.IsRestOperation(*o) && g.UsesProblemDetails(s, *o) {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesProblemDetails tells if the errors of the operation are reported as problem details: either the operation or its
+// service is annotated with @ProblemDetails
+func (g *Generator) UsesProblemDetails(s model.Struct, o model.Operation) bool {
+	_, ok := g.getProblemDetails(s, o)
+	return ok
+}
+
+// GetProblemType returns the uri that identifies the type of problem: the type of the operation wins over the type of
+// its service
+func (g *Generator) GetProblemType(s model.Struct, o model.Operation) string {
+	val, ok := g.getProblemDetails(s, o)
+	if ok && val.Attributes["type"] != "" {
+		return val.Attributes["type"]
+	}
+	return defaultProblemType
+}
+
+func (g *Generator) getProblemDetails(s model.Struct, o model.Operation) (annotation.Annotation, bool) {
+	val, ok := g.registry.ResolveAnnotationByName(o.DocLines, "ProblemDetails")
+	if ok {
+		return val, true
+	}
+	return g.registry.ResolveAnnotationByName(s.DocLines, "ProblemDetails")
+}
+
+var ProblemTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// Problem describes an error as problem details (RFC 7807)
+type Problem struct {
+	Type     string ` + "`json:\"type\"`" + `
+	Title    string ` + "`json:\"title\"`" + `
+	Status   int    ` + "`json:\"status\"`" + `
+	Detail   string ` + "`json:\"detail,omitempty\"`" + `
+	Instance string ` + "`json:\"instance,omitempty\"`" + `
+}
+
+// ProblemWriter writes the problem as the response with its status-code
+func ProblemWriter(w http.ResponseWriter, prob Problem) {
+	blob, err := json.Marshal(prob)
+	if err != nil {
+		log.Printf("Error marshalling problem details %+v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(prob.Status)
+	w.Write(blob)
+}
+
+// problemHandler reports errors as problems of the given type: it replaces handleError in the handlers of operations
+// annotated with @ProblemDetails
+func problemHandler(problemType string) func(err error, w http.ResponseWriter) {
+	return func(err error, w http.ResponseWriter) {
+		status := determineHttpCode(err)
+		ProblemWriter(w, Problem{
+			Type:     problemType,
+			Title:    http.StatusText(status),
+			Status:   status,
+			Detail:   err.Error(),
+			Instance: "/requests/" + newProblemInstanceID(),
+		})
+	}
+}
+
+// newProblemInstanceID returns a random uuid (version 4) that identifies the occurrence of a problem
+func newProblemInstanceID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		log.Printf("Error generating problem instance id %+v", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+`
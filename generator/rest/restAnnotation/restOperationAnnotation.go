@@ -1,33 +1,258 @@
 package restAnnotation
 
-import "github.com/MarcGrol/golangAnnotations/annotation"
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+)
 
 const (
 	typeRestOperation = "RestOperation"
 	typeRestService   = "RestService"
+	typeAPIKey        = "APIKey"
+	typeRequireClaim  = "RequireClaim"
+	typeRequestLog    = "RequestLogging"
+	typeSensitive     = "Sensitive"
+	typeCacheable     = "Cacheable"
+	typeStream        = "StreamResponse"
+	typeDeprecated    = "Deprecated"
+	typeSubResource   = "SubResource"
+	typeMaxBodySize   = "MaxBodySize"
+	typeOAuth2        = "OAuth2"
+	typeLink          = "Link"
+	typeLinkParam     = "LinkParam"
+	typeCORS          = "CORS"
+	typeBatch         = "Batch"
+	typeProblem       = "ProblemDetails"
+	typePanicSafe     = "PanicSafe"
 	paramPath         = "path"
 	paramMethod       = "method"
+	paramHeader       = "header"
+	paramParamName    = "paramname"
+	paramLocation     = "location"
+	paramName         = "name"
+	paramValue        = "value"
+	paramLevel        = "level"
+	paramIncludeBody  = "includebody"
+	paramMaxAge       = "maxage"
+	paramContentType  = "contenttype"
+	paramReplacedBy   = "replacedby"
+	paramSince        = "since"
+	paramParent       = "parent"
+	paramParentPath   = "parentpath"
+	paramBytes        = "bytes"
+	paramAuthURL      = "authorizationurl"
+	paramTokenURL     = "tokenurl"
+	paramScopes       = "scopes"
+	paramBuildConstr  = "buildconstraint"
+	paramRel          = "rel"
+	paramHref         = "href"
+	paramField        = "field"
+	paramOrigins      = "origins"
+	paramHeaders      = "headers"
+	paramCredentials  = "allowcredentials"
+	paramEndpoint     = "endpoint"
+	paramMaxItems     = "maxitems"
+	paramWorkers      = "workers"
+	paramRespField    = "responsefield"
+	paramType         = "type"
 )
 
 // Register makes the annotation-registry aware of these annotation
 func Register() {
-	annotation.RegisterAnnotation(typeRestOperation, []string{paramMethod, paramPath}, validateRestOperationAnnotation)
-	annotation.RegisterAnnotation(typeRestService, []string{paramPath}, validateRestServiceAnnotation)
+	RegisterIn(annotation.DefaultRegistry)
+}
+
+// RegisterIn makes the given registry aware of these annotations
+func RegisterIn(registry *annotation.Registry) {
+	registry.RegisterWithParams(typeRestOperation, []annotation.ParamDef{
+		{Name: paramMethod}, // optional: it can be inferred from the name of the operation
+		{Name: paramPath, Required: true},
+		{Name: paramBuildConstr},
+		{Name: paramRespField},
+	}, validateRestOperationAnnotation)
+	registry.RegisterExplaining(typeRestService, []string{paramPath}, validateRestServiceAnnotation)
+	registry.Register(typeAPIKey, []string{paramHeader, paramParamName, paramLocation}, validateAPIKeyAnnotation)
+	registry.Register(typeRequireClaim, []string{paramName, paramValue}, validateRequireClaimAnnotation)
+	registry.Register(typeRequestLog, []string{paramLevel, paramIncludeBody}, validateRequestLoggingAnnotation)
+	registry.Register(typeSensitive, []string{}, validateSensitiveAnnotation)
+	registry.Register(typeCacheable, []string{paramMaxAge}, validateCacheableAnnotation)
+	registry.Register(typeStream, []string{paramContentType}, validateStreamResponseAnnotation)
+	registry.Register(typeDeprecated, []string{paramReplacedBy, paramSince}, validateDeprecatedAnnotation)
+	registry.Register(typeSubResource, []string{paramParent, paramParentPath}, validateSubResourceAnnotation)
+	registry.Register(typeMaxBodySize, []string{paramBytes}, validateMaxBodySizeAnnotation)
+	registry.Register(typeOAuth2, []string{paramAuthURL, paramTokenURL, paramScopes}, validateOAuth2Annotation)
+	registry.Register(typeLink, []string{paramRel, paramHref}, validateLinkAnnotation)
+	registry.Register(typeLinkParam, []string{paramName, paramField}, validateLinkParamAnnotation)
+	registry.Register(typeCORS, []string{paramOrigins, paramHeaders, paramMaxAge, paramCredentials}, validateCORSAnnotation)
+	registry.Register(typeBatch, []string{paramEndpoint, paramMethod, paramMaxItems, paramWorkers}, validateBatchAnnotation)
+	registry.Register(typeProblem, []string{paramType}, validateProblemDetailsAnnotation)
+	registry.Register(typePanicSafe, []string{}, validatePanicSafeAnnotation)
+}
+
+func validateRestOperationAnnotation(annot annotation.Annotation) (bool, error) {
+	if annot.Name != typeRestOperation {
+		return false, fmt.Errorf("Expected annotation @%s, got @%s", typeRestOperation, annot.Name)
+	}
+	// the required path is enforced by its parameter-definition
+	return true, nil
+}
+
+func validateRestServiceAnnotation(annot annotation.Annotation) (bool, error) {
+	if annot.Name != typeRestService {
+		return false, fmt.Errorf("Expected annotation @%s, got @%s", typeRestService, annot.Name)
+	}
+	if _, ok := annot.Attributes[paramPath]; !ok {
+		return false, fmt.Errorf("@%s requires a '%s' attribute: use path = \"\" to serve at the root", typeRestService, paramPath)
+	}
+	return true, nil
+}
+
+func validateAPIKeyAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeAPIKey {
+		location := annot.Attributes[paramLocation]
+		return location == "" || location == "header" || location == "query"
+	}
+	return false
 }
 
-func validateRestOperationAnnotation(annot annotation.Annotation) bool {
-	if annot.Name == typeRestOperation {
-		path, hasPath := annot.Attributes[paramPath]
-		method, hasMethod := annot.Attributes[paramMethod]
-		return ((hasPath && path != "") && hasMethod && method != "")
+func validateRequireClaimAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeRequireClaim {
+		name, hasName := annot.Attributes[paramName]
+		value, hasValue := annot.Attributes[paramValue]
+		return (hasName && name != "") && (hasValue && value != "")
 	}
 	return false
 }
 
-func validateRestServiceAnnotation(annot annotation.Annotation) bool {
-	if annot.Name == typeRestService {
-		_, ok := annot.Attributes[paramPath]
-		return ok
+func validateRequestLoggingAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeRequestLog {
+		switch annot.Attributes[paramLevel] {
+		case "", "debug", "info", "warn", "error":
+		default:
+			return false
+		}
+		includeBody := annot.Attributes[paramIncludeBody]
+		return includeBody == "" || includeBody == "true" || includeBody == "false"
 	}
 	return false
 }
+
+func validateSensitiveAnnotation(annot annotation.Annotation) bool {
+	return annot.Name == typeSensitive
+}
+
+func validateCacheableAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeCacheable {
+		maxAge, hasMaxAge := annot.Attributes[paramMaxAge]
+		if !hasMaxAge {
+			return false
+		}
+		seconds, err := strconv.Atoi(maxAge)
+		return err == nil && seconds >= 0
+	}
+	return false
+}
+
+func validateStreamResponseAnnotation(annot annotation.Annotation) bool {
+	return annot.Name == typeStream
+}
+
+func validateDeprecatedAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeDeprecated {
+		replacedBy, hasReplacedBy := annot.Attributes[paramReplacedBy]
+		return hasReplacedBy && replacedBy != ""
+	}
+	return false
+}
+
+func validateSubResourceAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeSubResource {
+		parent, hasParent := annot.Attributes[paramParent]
+		parentPath, hasParentPath := annot.Attributes[paramParentPath]
+		return (hasParent && parent != "") && (hasParentPath && parentPath != "")
+	}
+	return false
+}
+
+func validateMaxBodySizeAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeMaxBodySize {
+		limit, err := strconv.ParseInt(annot.Attributes[paramBytes], 10, 64)
+		return err == nil && limit > 0
+	}
+	return false
+}
+
+func validateOAuth2Annotation(annot annotation.Annotation) bool {
+	if annot.Name == typeOAuth2 {
+		authURL, hasAuthURL := annot.Attributes[paramAuthURL]
+		tokenURL, hasTokenURL := annot.Attributes[paramTokenURL]
+		return (hasAuthURL && authURL != "") && (hasTokenURL && tokenURL != "")
+	}
+	return false
+}
+
+func validateLinkAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeLink {
+		rel, hasRel := annot.Attributes[paramRel]
+		href, hasHref := annot.Attributes[paramHref]
+		return (hasRel && rel != "") && (hasHref && href != "")
+	}
+	return false
+}
+
+func validateLinkParamAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeLinkParam {
+		// the name is optional: it defaults to the {id}-parameter
+		field, hasField := annot.Attributes[paramField]
+		return hasField && field != ""
+	}
+	return false
+}
+
+func validateCORSAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeCORS {
+		// all attributes are optional: by default all origins are allowed
+		if maxAge, hasMaxAge := annot.Attributes[paramMaxAge]; hasMaxAge {
+			seconds, err := strconv.Atoi(maxAge)
+			if err != nil || seconds < 0 {
+				return false
+			}
+		}
+		allowCredentials := annot.Attributes[paramCredentials]
+		return allowCredentials == "" || allowCredentials == "true" || allowCredentials == "false"
+	}
+	return false
+}
+
+func validateBatchAnnotation(annot annotation.Annotation) bool {
+	if annot.Name == typeBatch {
+		// the method and the number of workers are optional
+		endpoint, hasEndpoint := annot.Attributes[paramEndpoint]
+		if !hasEndpoint || endpoint == "" {
+			return false
+		}
+		maxItems, err := strconv.Atoi(annot.Attributes[paramMaxItems])
+		if err != nil || maxItems <= 0 {
+			return false
+		}
+		if workers, hasWorkers := annot.Attributes[paramWorkers]; hasWorkers {
+			count, err := strconv.Atoi(workers)
+			if err != nil || count <= 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func validateProblemDetailsAnnotation(annot annotation.Annotation) bool {
+	// the type is optional: it defaults to about:blank
+	return annot.Name == typeProblem
+}
+
+func validatePanicSafeAnnotation(annot annotation.Annotation) bool {
+	return annot.Name == typePanicSafe
+}
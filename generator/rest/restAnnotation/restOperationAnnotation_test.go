@@ -17,6 +17,15 @@ func TestCorrectRestOperationAnnotation(t *testing.T) {
 	assert.Equal(t, "/person/:uid", a.Attributes["path"])
 }
 
+func TestRestOperationAnnotationWithQueryInPath(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotation(`// @RestOperation( method = "GET", path = "/v1/search?q={q}&limit={limit}" )`)
+	assert.True(t, ok)
+	assert.Equal(t, "/v1/search?q={q}&limit={limit}", a.Attributes["path"])
+}
+
 func TestIncompleteRestOperationAnnotation(t *testing.T) {
 	annotation.ClearRegisteredAnnotations()
 	Register()
@@ -33,12 +42,24 @@ func TestPartialIncompleteRestOperationAnnotation(t *testing.T) {
 	assert.False(t, ok)
 }
 
-func TestPartialIncompleteRestOperationAnnotation2(t *testing.T) {
+func TestRestOperationAnnotationWithoutMethod(t *testing.T) {
 	annotation.ClearRegisteredAnnotations()
 	Register()
 
-	_, ok := annotation.ResolveAnnotations([]string{`// @RestOperation( Path = "/foo")`})
-	assert.False(t, ok)
+	// the method is inferred from the name of the operation
+	a, ok := annotation.ResolveAnnotations([]string{`// @RestOperation( Path = "/foo")`})
+	assert.True(t, ok)
+	assert.Equal(t, "/foo", a.Attributes["path"])
+	assert.Equal(t, "", a.Attributes["method"])
+}
+
+func TestIncompleteRestOperationAnnotationIsExplained(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	errs := annotation.ValidationErrors([]string{`// @RestOperation( Method = "GET")`})
+	assert.Equal(t, 1, len(errs))
+	assert.EqualError(t, errs[0], "@RestOperation requires a non-empty 'path' attribute")
 }
 
 func TestCorrectRestServiceAnnotation(t *testing.T) {
@@ -58,6 +79,15 @@ func TestIncompleteRestServiceAnnotation(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestIncompleteRestServiceAnnotationIsExplained(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	errs := annotation.ValidationErrors([]string{`// @RestService()`})
+	assert.Equal(t, 1, len(errs))
+	assert.EqualError(t, errs[0], `@RestService requires a 'path' attribute: use path = "" to serve at the root`)
+}
+
 func TestEmptyRestServiceAnnotation(t *testing.T) {
 	annotation.ClearRegisteredAnnotations()
 	Register()
@@ -65,3 +95,293 @@ func TestEmptyRestServiceAnnotation(t *testing.T) {
 	_, ok := annotation.ResolveAnnotations([]string{`// @RestService( Path = "")`})
 	assert.True(t, ok)
 }
+
+func TestCorrectAPIKeyAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotation(`// @APIKey( header = "X-API-Key", paramName = "api_key", location = "query" )`)
+	assert.True(t, ok)
+	assert.Equal(t, "X-API-Key", a.Attributes["header"])
+	assert.Equal(t, "api_key", a.Attributes["paramname"])
+	assert.Equal(t, "query", a.Attributes["location"])
+}
+
+func TestInvalidLocationAPIKeyAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotation(`// @APIKey( location = "cookie" )`)
+	assert.False(t, ok)
+}
+
+func TestCorrectRequireClaimAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotation(`// @RequireClaim( name = "scope", value = "read:users" )`)
+	assert.True(t, ok)
+	assert.Equal(t, "scope", a.Attributes["name"])
+	assert.Equal(t, "read:users", a.Attributes["value"])
+}
+
+func TestIncompleteRequireClaimAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotation(`// @RequireClaim( name = "scope" )`)
+	assert.False(t, ok)
+}
+
+func TestCorrectRequestLoggingAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotation(`// @RequestLogging( level = "warn", includeBody = "true" )`)
+	assert.True(t, ok)
+	assert.Equal(t, "warn", a.Attributes["level"])
+	assert.Equal(t, "true", a.Attributes["includebody"])
+}
+
+func TestInvalidLevelRequestLoggingAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotation(`// @RequestLogging( level = "verbose" )`)
+	assert.False(t, ok)
+}
+
+func TestCorrectSensitiveAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotation(`// @Sensitive()`)
+	assert.True(t, ok)
+	assert.Equal(t, "Sensitive", a.Name)
+}
+
+func TestCorrectCacheableAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotation(`// @Cacheable( maxAge = 60 )`)
+	assert.True(t, ok)
+	assert.Equal(t, "60", a.Attributes["maxage"])
+}
+
+func TestInvalidMaxAgeCacheableAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotation(`// @Cacheable( maxAge = "one minute" )`)
+	assert.False(t, ok)
+
+	_, ok = annotation.ResolveAnnotation(`// @Cacheable()`)
+	assert.False(t, ok)
+}
+
+func TestCorrectStreamResponseAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotation(`// @StreamResponse( contentType = "application/x-ndjson" )`)
+	assert.True(t, ok)
+	assert.Equal(t, "application/x-ndjson", a.Attributes["contenttype"])
+
+	_, ok = annotation.ResolveAnnotation(`// @StreamResponse()`)
+	assert.True(t, ok)
+}
+
+func TestCorrectDeprecatedAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotation(`// @Deprecated( replacedBy = "/v2/users/{id}", since = "v2.0" )`)
+	assert.True(t, ok)
+	assert.Equal(t, "/v2/users/{id}", a.Attributes["replacedby"])
+	assert.Equal(t, "v2.0", a.Attributes["since"])
+}
+
+func TestIncompleteDeprecatedAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotation(`// @Deprecated( since = "v2.0" )`)
+	assert.False(t, ok)
+}
+
+func TestCorrectSubResourceAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotation(`// @SubResource( parent = "Order", parentPath = "/orders/{orderId}" )`)
+	assert.True(t, ok)
+	assert.Equal(t, "Order", a.Attributes["parent"])
+	assert.Equal(t, "/orders/{orderId}", a.Attributes["parentpath"])
+}
+
+func TestIncompleteSubResourceAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotation(`// @SubResource( parent = "Order" )`)
+	assert.False(t, ok)
+}
+
+func TestCorrectMaxBodySizeAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotations([]string{`// @MaxBodySize( bytes = 1048576 )`})
+	assert.True(t, ok)
+	assert.Equal(t, "1048576", a.Attributes["bytes"])
+}
+
+func TestInvalidMaxBodySizeAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @MaxBodySize( bytes = 0 )`})
+	assert.False(t, ok)
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @MaxBodySize( bytes = "1MB" )`})
+	assert.False(t, ok)
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @MaxBodySize()`})
+	assert.False(t, ok)
+}
+
+func TestCorrectOAuth2Annotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotations([]string{`// @OAuth2( authorizationURL = "https://auth.example.com/oauth/authorize", tokenURL = "https://auth.example.com/oauth/token", scopes = "read:users write:users" )`})
+	assert.True(t, ok)
+	assert.Equal(t, "https://auth.example.com/oauth/authorize", a.Attributes["authorizationurl"])
+	assert.Equal(t, "https://auth.example.com/oauth/token", a.Attributes["tokenurl"])
+	assert.Equal(t, "read:users write:users", a.Attributes["scopes"])
+}
+
+func TestIncompleteOAuth2Annotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @OAuth2( tokenURL = "https://auth.example.com/oauth/token" )`})
+	assert.False(t, ok)
+}
+
+func TestCorrectLinkAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotations([]string{`// @Link( rel = "self", href = "/orders/{id}" )`})
+	assert.True(t, ok)
+	assert.Equal(t, "self", a.Attributes["rel"])
+	assert.Equal(t, "/orders/{id}", a.Attributes["href"])
+}
+
+func TestIncompleteLinkAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @Link( rel = "self" )`})
+	assert.False(t, ok)
+}
+
+func TestCorrectLinkParamAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotations([]string{`// @LinkParam( field = "OrderID" )`})
+	assert.True(t, ok)
+	assert.Equal(t, "OrderID", a.Attributes["field"])
+	assert.Equal(t, "", a.Attributes["name"])
+}
+
+func TestCorrectCORSAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotations([]string{`// @CORS( origins = "https://example.com", maxAge = 600, allowCredentials = "true" )`})
+	assert.True(t, ok)
+	assert.Equal(t, "https://example.com", a.Attributes["origins"])
+	assert.Equal(t, "600", a.Attributes["maxage"])
+	assert.Equal(t, "true", a.Attributes["allowcredentials"])
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @CORS()`})
+	assert.True(t, ok)
+}
+
+func TestInvalidCORSAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @CORS( maxAge = "long" )`})
+	assert.False(t, ok)
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @CORS( allowCredentials = "yes" )`})
+	assert.False(t, ok)
+}
+
+func TestCorrectBatchAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotations([]string{`// @Batch( endpoint = "/users/batch", method = "POST", maxItems = 100, workers = 8 )`})
+	assert.True(t, ok)
+	assert.Equal(t, "/users/batch", a.Attributes["endpoint"])
+	assert.Equal(t, "POST", a.Attributes["method"])
+	assert.Equal(t, "100", a.Attributes["maxitems"])
+	assert.Equal(t, "8", a.Attributes["workers"])
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @Batch( endpoint = "/users/batch", maxItems = 100 )`})
+	assert.True(t, ok)
+}
+
+func TestInvalidBatchAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	_, ok := annotation.ResolveAnnotations([]string{`// @Batch( maxItems = 100 )`})
+	assert.False(t, ok)
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @Batch( endpoint = "/users/batch" )`})
+	assert.False(t, ok)
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @Batch( endpoint = "/users/batch", maxItems = 0 )`})
+	assert.False(t, ok)
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @Batch( endpoint = "/users/batch", maxItems = 100, workers = "many" )`})
+	assert.False(t, ok)
+}
+
+func TestCorrectProblemDetailsAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotations([]string{`// @ProblemDetails( type = "https://example.com/errors/not-found" )`})
+	assert.True(t, ok)
+	assert.Equal(t, "https://example.com/errors/not-found", a.Attributes["type"])
+
+	_, ok = annotation.ResolveAnnotations([]string{`// @ProblemDetails()`})
+	assert.True(t, ok)
+}
+
+func TestCorrectPanicSafeAnnotation(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	Register()
+
+	a, ok := annotation.ResolveAnnotations([]string{`// @PanicSafe()`})
+	assert.True(t, ok)
+	assert.Equal(t, "PanicSafe", a.Name)
+}
+
+func TestRegisterInOwnRegistry(t *testing.T) {
+	registry := annotation.NewRegistry()
+	RegisterIn(registry)
+
+	a, ok := registry.ResolveAnnotations([]string{`// @RestService( Path = "/api")`})
+	assert.True(t, ok)
+	assert.Equal(t, "/api", a.Attributes["path"])
+	assert.Equal(t, 1, len(registry.Validate([]string{`// @RestService()`})))
+}
@@ -0,0 +1,10 @@
+package testserver
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// The functions below resolve the annotations in the annotation.DefaultRegistry
+
+// Generate emits a test-server fixture for every rest-service, to run integration-tests against the generated handlers
+func Generate(inputDir string, structs []model.Struct) error {
+	return DefaultGenerator.Generate(inputDir, structs)
+}
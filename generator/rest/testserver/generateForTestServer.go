@@ -0,0 +1,79 @@
+package testserver
+
+import (
+	"fmt"
+	"log"
+	"text/template"
+
+	"github.com/MarcGrol/golangAnnotations/annotation"
+	"github.com/MarcGrol/golangAnnotations/generator/generationUtil"
+	"github.com/MarcGrol/golangAnnotations/generator/rest"
+	"github.com/MarcGrol/golangAnnotations/generator/rest/restAnnotation"
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+// Generator generates test-servers for rest-services, resolving the rest-annotations in its registry
+type Generator struct {
+	registry *annotation.Registry
+	rest     *rest.Generator
+}
+
+func NewGenerator(registry *annotation.Registry) *Generator {
+	return &Generator{registry: registry, rest: rest.NewGenerator(registry)}
+}
+
+// DefaultGenerator resolves the annotations in the annotation.DefaultRegistry
+var DefaultGenerator = NewGenerator(annotation.DefaultRegistry)
+
+// Generate emits a test-server fixture for every rest-service, to run integration-tests against the generated handlers
+func (g *Generator) Generate(inputDir string, structs []model.Struct) error {
+	restAnnotation.RegisterIn(g.registry)
+
+	packageName, err := generationUtil.GetPackageName(structs)
+	if err != nil {
+		return err
+	}
+
+	for _, service := range structs {
+		if g.rest.IsRestService(service) {
+			targetDir, err := generationUtil.DetermineTargetPath(inputDir, packageName)
+			if err != nil {
+				return err
+			}
+			target := fmt.Sprintf("%s/http%sTestServer_test.go", targetDir, service.Name)
+
+			err = generationUtil.GenerateFileFromTemplate(service, "testServer", testServerTemplate, customTemplateFuncs, target)
+			if err != nil {
+				log.Fatalf("Error generating test-server for service %s: %s", service.Name, err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var customTemplateFuncs = template.FuncMap{}
+
+var testServerTemplate string = `
+// Generated automatically: do not edit manually
+
+package {{.PackageName}}
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// New{{.Name}}TestServer serves the generated http-handler of the service on a local port until the test has finished
+func New{{.Name}}TestServer(t *testing.T, svc *{{.Name}}) *url.URL {
+	server := httptest.NewServer(svc.HttpHandler())
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Error parsing url of test-server: %s", err)
+	}
+	return serverURL
+}
+`
@@ -0,0 +1,39 @@
+package testserver
+
+import (
+	"os"
+	"testing"
+
+	"io/ioutil"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateForTestServer(t *testing.T) {
+	os.Remove("./testData/httpMyServiceTestServer_test.go")
+
+	s := []model.Struct{
+		{
+			PackageName: "testData",
+			DocLines:    []string{`// @RestService( path = "/api" )`},
+			Name:        "MyService",
+		},
+		{
+			PackageName: "testData",
+			Name:        "Person",
+		},
+	}
+	err := Generate("testData", s)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile("./testData/httpMyServiceTestServer_test.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "func NewMyServiceTestServer(t *testing.T, svc *MyService) *url.URL {")
+	assert.Contains(t, string(data), "t.Cleanup(server.Close)")
+
+	_, err = os.Stat("./testData/httpPersonTestServer_test.go")
+	assert.True(t, os.IsNotExist(err))
+
+	os.Remove("./testData/httpMyServiceTestServer_test.go")
+}
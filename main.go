@@ -6,8 +6,31 @@ import (
 	"log"
 	"os"
 
+	"github.com/MarcGrol/golangAnnotations/generator/changelog"
+	"github.com/MarcGrol/golangAnnotations/generator/dbdoc"
+	"github.com/MarcGrol/golangAnnotations/generator/dbdoc/dbdocAnnotation"
 	"github.com/MarcGrol/golangAnnotations/generator/event"
+	"github.com/MarcGrol/golangAnnotations/generator/event/eventAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/eventstore"
+	"github.com/MarcGrol/golangAnnotations/generator/eventstore/eventstoreAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/fuzz"
+	"github.com/MarcGrol/golangAnnotations/generator/fuzz/fuzzAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/gateway"
+	"github.com/MarcGrol/golangAnnotations/generator/gateway/gatewayAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/gob"
+	"github.com/MarcGrol/golangAnnotations/generator/gob/gobAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/grpc"
+	"github.com/MarcGrol/golangAnnotations/generator/grpc/grpcAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/lambda"
+	"github.com/MarcGrol/golangAnnotations/generator/lambda/lambdaAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/openapi"
+	"github.com/MarcGrol/golangAnnotations/generator/proptest"
+	"github.com/MarcGrol/golangAnnotations/generator/proptest/proptestAnnotation"
 	"github.com/MarcGrol/golangAnnotations/generator/rest"
+	"github.com/MarcGrol/golangAnnotations/generator/rest/contract"
+	"github.com/MarcGrol/golangAnnotations/generator/rest/restAnnotation"
+	"github.com/MarcGrol/golangAnnotations/generator/rest/testserver"
+	"github.com/MarcGrol/golangAnnotations/model"
 	"github.com/MarcGrol/golangAnnotations/parser"
 )
 
@@ -16,33 +39,149 @@ const (
 )
 
 var (
-	inputDir *string
+	inputDir            *string
+	complexityThreshold *int
+	updateSnapshot      *bool
+	openapiOutput       *string
+	grpcTypeMap         *string
+	openapiConfig       openapi.Config
 )
 
 func main() {
 	processArgs()
 
+	// all annotations must be known up front to recognize the unknown ones while parsing
+	registerAnnotations()
+
 	harvest, err := parser.ParseSourceDir(*inputDir, ".*.go")
 	if err != nil {
 		log.Printf("Error finding structs in %s:%s", *inputDir, err)
 		os.Exit(1)
 	}
 
+	for _, unknown := range harvest.UnknownAnnotations {
+		log.Printf("Warning: %s", unknown.Error())
+	}
+
+	for _, invalid := range harvest.InvalidAnnotations {
+		log.Printf("Warning: %s", invalid.Error())
+	}
+
+	for _, warning := range harvest.AnnotationWarnings {
+		log.Printf("Warning: %s", warning.String())
+	}
+
+	for _, iface := range harvest.Interfaces {
+		if iface.Complexity() > *complexityThreshold {
+			log.Printf("Warning: Interface %s is complex and may be hard to mock.", iface.Name)
+		}
+	}
+
 	err = event.Generate(*inputDir, harvest.Structs)
 	if err != nil {
 		log.Printf("Error generating event code:%s", err)
 		os.Exit(1)
 	}
 
+	err = eventstore.Generate(*inputDir, harvest.Structs)
+	if err != nil {
+		log.Printf("Error generating event-store code:%s", err)
+		os.Exit(1)
+	}
+
 	err = rest.Generate(*inputDir, harvest.Structs)
 	if err != nil {
 		log.Printf("Error generating rest code:%s", err)
 		os.Exit(1)
 	}
 
+	err = testserver.Generate(*inputDir, harvest.Structs)
+	if err != nil {
+		log.Printf("Error generating test-server code:%s", err)
+		os.Exit(1)
+	}
+
+	err = contract.Generate(*inputDir, harvest.Structs)
+	if err != nil {
+		log.Printf("Error generating contract-test code:%s", err)
+		os.Exit(1)
+	}
+
+	err = changelog.Generate(*inputDir, harvest.Structs, *updateSnapshot)
+	if err != nil {
+		log.Printf("Error generating changelog:%s", err)
+		os.Exit(1)
+	}
+
+	err = gob.Generate(*inputDir, harvest.Structs)
+	if err != nil {
+		log.Printf("Error generating gob code:%s", err)
+		os.Exit(1)
+	}
+
+	err = dbdoc.Generate(*inputDir, harvest.Structs)
+	if err != nil {
+		log.Printf("Error generating database documentation:%s", err)
+		os.Exit(1)
+	}
+
+	err = lambda.Generate(*inputDir, harvest.Structs)
+	if err != nil {
+		log.Printf("Error generating lambda code:%s", err)
+		os.Exit(1)
+	}
+
+	err = gateway.Generate(*inputDir, harvest.Structs)
+	if err != nil {
+		log.Printf("Error generating gateway configuration:%s", err)
+		os.Exit(1)
+	}
+
+	err = grpc.Generate(*inputDir, harvest.Interfaces, harvest.Structs, *grpcTypeMap)
+	if err != nil {
+		log.Printf("Error generating grpc code:%s", err)
+		os.Exit(1)
+	}
+
+	if *openapiOutput != "" {
+		err = openapi.Generate(harvest.Structs, openapiConfig, *openapiOutput)
+		if err != nil {
+			log.Printf("Error generating openapi specification:%s", err)
+			os.Exit(1)
+		}
+	}
+
+	// fuzz- and property-tests are generated for methods and free functions alike
+	operations := append(append([]model.Operation{}, harvest.Operations...), harvest.FreeFunctions...)
+
+	err = fuzz.Generate(*inputDir, operations)
+	if err != nil {
+		log.Printf("Error generating fuzz code:%s", err)
+		os.Exit(1)
+	}
+
+	err = proptest.Generate(*inputDir, operations)
+	if err != nil {
+		log.Printf("Error generating property-test code:%s", err)
+		os.Exit(1)
+	}
+
 	os.Exit(0)
 }
 
+func registerAnnotations() {
+	eventAnnotation.Register()
+	eventstoreAnnotation.Register()
+	restAnnotation.Register()
+	gobAnnotation.Register()
+	dbdocAnnotation.Register()
+	lambdaAnnotation.Register()
+	gatewayAnnotation.Register()
+	fuzzAnnotation.Register()
+	proptestAnnotation.Register()
+	grpcAnnotation.Register()
+}
+
 func printUsage() {
 	fmt.Fprintf(os.Stderr, "\nUsage:\n")
 	fmt.Fprintf(os.Stderr, " %s [flags]\n", os.Args[0])
@@ -58,6 +197,13 @@ func printVersion() {
 
 func processArgs() {
 	inputDir = flag.String("input-dir", "", "Directory to be examined")
+	complexityThreshold = flag.Int("interface-complexity-threshold", 50, "Number of distinct argument-types above which an interface is reported as complex")
+	updateSnapshot = flag.Bool("update-snapshot", false, "Record the current rest-operations as the snapshot that the changelog is based on")
+	openapiOutput = flag.String("openapi-output", "", "File to write the openapi specification of the rest-services to: none is written when empty")
+	flag.StringVar(&openapiConfig.Title, "openapi-title", "", "Title of the openapi specification: defaults to the name of the package")
+	flag.StringVar(&openapiConfig.Version, "openapi-version", "", "Version of the api in the openapi specification: defaults to 1.0.0")
+	flag.StringVar(&openapiConfig.ServerURL, "openapi-server-url", "", "Url of the server in the openapi specification")
+	grpcTypeMap = flag.String("grpc-type-map", "", "Json-file that maps go-types onto proto-types, like {\"int\": \"sint64\"}: added to the defaults")
 	help := flag.Bool("help", false, "Usage information")
 	version := flag.Bool("version", false, "Version information")
 
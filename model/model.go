@@ -1,38 +1,230 @@
 package model
 
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
 type Operation struct {
 	PackageName   string
+	SourceFile    string // absolute path of the file in which it is declared
 	DocLines      []string
+	Description   string // the doc-lines without annotations and comment-markers
 	RelatedStruct *Field // optional
 	Name          string
+	TypeParams    []TypeParam // only for generic functions
 	InputArgs     []Field
 	OutputArgs    []Field
 	CommentLines  []string
 }
 
 type Struct struct {
-	PackageName  string
-	DocLines     []string
-	Name         string
-	Fields       []Field
-	Operations   []*Operation
-	CommentLines []string
+	PackageName   string
+	SourceFile    string // absolute path of the file in which it is declared
+	DocLines      []string
+	Description   string // the doc-lines without annotations and comment-markers
+	Name          string
+	TypeParams    []TypeParam // only for generic structs
+	Fields        []Field
+	PrivateFields []Field // unexported fields: skipped by encoding/json and encoding/gob
+	Operations    []*Operation
+	CommentLines  []string
 }
 
 type Interface struct {
 	PackageName  string
+	SourceFile   string // absolute path of the file in which it is declared
 	DocLines     []string
 	Name         string
 	Methods      []Operation
+	Embedded     []EmbeddedInterface // the methods of embedded interfaces are not part of Methods
 	CommentLines []string
 }
 
+// EmbeddedInterface is an interface that is embedded in another interface, like io.Reader in io.ReadWriter
+type EmbeddedInterface struct {
+	Name        string
+	PackageName string // the package-qualifier: empty for interfaces of the same package
+}
+
+// Import is an imported package of a source-file
+type Import struct {
+	Path       string
+	Alias      string // empty for a regular import, "." for a dot-import and "_" for a blank import
+	SourceFile string // absolute path of the file that imports the package
+}
+
+// TypeParam is a type-parameter of a generic struct or function, like T in Result[T any]
+type TypeParam struct {
+	Name       string
+	Constraint string // like any, comparable or constraints.Ordered
+}
+
 type Field struct {
-	DocLines     []string
-	Name         string
-	TypeName     string
-	IsSlice      bool
-	IsPointer    bool
-	Tag          string
-	CommentLines []string
+	DocLines          []string
+	Name              string
+	TypeName          string
+	PackageQualifier  string // optional: set for types of other packages like time.Time
+	IsSlice           bool
+	IsChannel         bool
+	IsVariadic        bool // only for the last input-argument: TypeName is the type of the individual arguments
+	IsEmbedded        bool // only for struct-fields: the field is named after its type, like Go does
+	IsNamedParam      bool // only for input-arguments: false when the name was generated for an unnamed parameter
+	IsSelfReferential bool // only for struct-fields: the field refers to its enclosing struct, so recursion must stop here
+	IsPointer         bool
+	IsPointerReceiver bool   // only for the receiver of a method: the method is declared on the pointer-type
+	IsMap             bool   // the key- and value-types of a map are described by the Map-fields, TypeName is empty
+	MapKeyTypeName    string // only for maps
+	MapKeyPackage     string // only for maps: set for key-types of other packages
+	MapValueTypeName  string // only for maps
+	MapValuePackage   string // only for maps: set for value-types of other packages
+	MapValueIsPointer bool   // only for maps
+	Tag               string
+	CommentLines      []string
+	DeclOrder         int // position of the field in its struct- or parameter-declaration
+}
+
+// Complexity returns the number of distinct argument-types used by the methods of the interface
+func (i Interface) Complexity() int {
+	types := make(map[string]bool)
+	for _, m := range i.Methods {
+		for _, arg := range m.InputArgs {
+			types[arg.TypeKey()] = true
+		}
+		for _, arg := range m.OutputArgs {
+			types[arg.TypeKey()] = true
+		}
+	}
+	return len(types)
+}
+
+// methodPrefixes maps the conventional prefixes of operation-names to the http-method they suggest
+var methodPrefixes = []struct {
+	prefix string
+	method string
+}{
+	{"get", "GET"},
+	{"create", "POST"},
+	{"add", "POST"},
+	{"update", "PUT"},
+	{"delete", "DELETE"},
+	{"remove", "DELETE"},
+}
+
+// InferredHTTPMethod returns the http-method suggested by the name of the operation, like GET for getPerson:
+// an empty string is returned when the name does not follow any convention
+func (oper Operation) InferredHTTPMethod() string {
+	for _, p := range methodPrefixes {
+		if hasWordPrefix(oper.Name, p.prefix) {
+			return p.method
+		}
+	}
+	return ""
+}
+
+// hasWordPrefix tells if name starts with the given word, regardless of the case of its first letter
+func hasWordPrefix(name string, prefix string) bool {
+	if len(name) < len(prefix) || !strings.EqualFold(name[:1], prefix[:1]) || name[1:len(prefix)] != prefix[1:] {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	// the word must end where the prefix ends: "address" does not start with "add"
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return unicode.IsUpper(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// SortFieldsByDeclOrder restores the declaration order of fields that have been collected from several places,
+// so generated code does not depend on the order in which they were collected
+func SortFieldsByDeclOrder(fields []Field) {
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].DeclOrder != fields[j].DeclOrder {
+			return fields[i].DeclOrder < fields[j].DeclOrder
+		}
+		return fields[i].Name < fields[j].Name
+	})
+}
+
+// ZeroValueLiteral returns the go-literal of the zero-value of the type of the field, like "" for a string, nil for a
+// pointer or a slice and *new(Person) for named types: the field does not tell whether a named type is a struct, an
+// interface or a type like time.Duration, and *new() yields the zero-value of each of them
+func (f Field) ZeroValueLiteral() string {
+	if f.IsPointer || f.IsSlice || f.IsMap || f.IsChannel || f.IsVariadic {
+		return "nil"
+	}
+	switch f.TypeName {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"byte", "rune", "float32", "float64", "complex64", "complex128":
+		return "0"
+	case "error", "interface{}", "any":
+		return "nil"
+	}
+	return "*new(" + qualified(f.PackageQualifier, f.TypeName) + ")"
+}
+
+// ParsedTag returns the tag of the field without the surrounding quotes, so its key-value pairs can be read
+func (f Field) ParsedTag() reflect.StructTag {
+	return ParseTag(f.Tag)
+}
+
+// TagGet returns the value of the key in the tag of the field, like "name,omitempty" for the key json
+func (f Field) TagGet(key string) string {
+	return f.ParsedTag().Get(key)
+}
+
+// TagLookup returns the value of the key in the tag of the field and tells if the key is present at all
+func (f Field) TagLookup(key string) (string, bool) {
+	return f.ParsedTag().Lookup(key)
+}
+
+// ParseTag converts the raw literal of a tag, as found in the source-code, into a reflect.StructTag
+func ParseTag(raw string) reflect.StructTag {
+	unquoted, err := strconv.Unquote(raw)
+	if err != nil {
+		return reflect.StructTag(raw)
+	}
+	return reflect.StructTag(unquoted)
+}
+
+// TypeKey returns the type of the field as it is written in go, like []*model.Person or map[string]int: two fields
+// have the same type when their keys are equal
+func (f Field) TypeKey() string {
+	key := qualified(f.PackageQualifier, f.TypeName)
+	if f.IsMap {
+		value := qualified(f.MapValuePackage, f.MapValueTypeName)
+		if f.MapValueIsPointer {
+			value = "*" + value
+		}
+		key = "map[" + qualified(f.MapKeyPackage, f.MapKeyTypeName) + "]" + value
+	}
+	if f.IsPointer {
+		key = "*" + key
+	}
+	if f.IsSlice {
+		key = "[]" + key
+	}
+	if f.IsChannel {
+		key = "chan " + key
+	}
+	if f.IsVariadic {
+		key = "..." + key
+	}
+	return key
+}
+
+func qualified(packageName string, typeName string) string {
+	if packageName == "" {
+		return typeName
+	}
+	return packageName + "." + typeName
 }
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterfaceComplexity(t *testing.T) {
+	i := Interface{
+		Name: "Doer",
+		Methods: []Operation{
+			{
+				Name:       "doit",
+				InputArgs:  []Field{{Name: "req", TypeName: "Req"}, {Name: "uid", TypeName: "string"}},
+				OutputArgs: []Field{{TypeName: "Resp"}, {TypeName: "error"}},
+			},
+			{
+				Name:       "doitAll",
+				InputArgs:  []Field{{Name: "reqs", TypeName: "Req", IsSlice: true}},
+				OutputArgs: []Field{{TypeName: "Resp", IsPointer: true}, {TypeName: "error"}},
+			},
+			{
+				Name: "dontDoit",
+			},
+		},
+	}
+	assert.Equal(t, 6, i.Complexity())
+}
+
+func TestInterfaceComplexityWithMaps(t *testing.T) {
+	i := Interface{
+		Name: "Indexer",
+		Methods: []Operation{
+			{
+				Name: "index",
+				InputArgs: []Field{
+					{Name: "byName", IsMap: true, MapKeyTypeName: "string", MapValueTypeName: "Person"},
+					{Name: "byAge", IsMap: true, MapKeyTypeName: "int", MapValueTypeName: "Person"},
+					{Name: "refs", IsMap: true, MapKeyTypeName: "string", MapValueTypeName: "Person", MapValueIsPointer: true},
+				},
+			},
+		},
+	}
+	assert.Equal(t, 3, i.Complexity())
+}
+
+func TestInterfaceComplexityWithQualifiedAndVariadicTypes(t *testing.T) {
+	i := Interface{
+		Name: "Scheduler",
+		Methods: []Operation{
+			{
+				Name: "schedule",
+				InputArgs: []Field{
+					{Name: "at", TypeName: "Time", PackageQualifier: "time"},
+					{Name: "local", TypeName: "Time"},
+					{Name: "name", TypeName: "string"},
+					{Name: "tags", TypeName: "string", IsVariadic: true},
+				},
+			},
+		},
+	}
+	assert.Equal(t, 4, i.Complexity())
+}
+
+func TestEmptyInterfaceComplexity(t *testing.T) {
+	assert.Equal(t, 0, Interface{Name: "Empty"}.Complexity())
+}
+
+func TestInferredHTTPMethod(t *testing.T) {
+	assert.Equal(t, "GET", Operation{Name: "getPerson"}.InferredHTTPMethod())
+	assert.Equal(t, "GET", Operation{Name: "GetPerson"}.InferredHTTPMethod())
+	assert.Equal(t, "POST", Operation{Name: "createPerson"}.InferredHTTPMethod())
+	assert.Equal(t, "POST", Operation{Name: "AddPerson"}.InferredHTTPMethod())
+	assert.Equal(t, "PUT", Operation{Name: "updatePerson"}.InferredHTTPMethod())
+	assert.Equal(t, "DELETE", Operation{Name: "deletePerson"}.InferredHTTPMethod())
+	assert.Equal(t, "DELETE", Operation{Name: "Remove"}.InferredHTTPMethod())
+}
+
+func TestNoInferredHTTPMethod(t *testing.T) {
+	assert.Equal(t, "", Operation{Name: "address"}.InferredHTTPMethod())
+	assert.Equal(t, "", Operation{Name: "getaway"}.InferredHTTPMethod())
+	assert.Equal(t, "", Operation{Name: "streamPersons"}.InferredHTTPMethod())
+	assert.Equal(t, "", Operation{Name: "GETPerson"}.InferredHTTPMethod())
+}
+
+func TestSortFieldsByDeclOrder(t *testing.T) {
+	fields := []Field{
+		{Name: "c", DeclOrder: 2},
+		{Name: "b", DeclOrder: 0},
+		{Name: "a", DeclOrder: 1},
+		{Name: "B", DeclOrder: 0},
+	}
+	SortFieldsByDeclOrder(fields)
+	assert.Equal(t, "B", fields[0].Name)
+	assert.Equal(t, "b", fields[1].Name)
+	assert.Equal(t, "a", fields[2].Name)
+	assert.Equal(t, "c", fields[3].Name)
+}
+
+func TestZeroValueLiteral(t *testing.T) {
+	assert.Equal(t, `""`, Field{TypeName: "string"}.ZeroValueLiteral())
+	assert.Equal(t, "0", Field{TypeName: "int"}.ZeroValueLiteral())
+	assert.Equal(t, "0", Field{TypeName: "float64"}.ZeroValueLiteral())
+	assert.Equal(t, "0", Field{TypeName: "byte"}.ZeroValueLiteral())
+	assert.Equal(t, "false", Field{TypeName: "bool"}.ZeroValueLiteral())
+	assert.Equal(t, "nil", Field{TypeName: "error"}.ZeroValueLiteral())
+	assert.Equal(t, "nil", Field{TypeName: "interface{}"}.ZeroValueLiteral())
+	assert.Equal(t, "nil", Field{TypeName: "Person", IsPointer: true}.ZeroValueLiteral())
+	assert.Equal(t, "nil", Field{TypeName: "string", IsSlice: true}.ZeroValueLiteral())
+	assert.Equal(t, "nil", Field{IsMap: true, MapKeyTypeName: "string", MapValueTypeName: "int"}.ZeroValueLiteral())
+	assert.Equal(t, "nil", Field{TypeName: "Person", IsChannel: true}.ZeroValueLiteral())
+	assert.Equal(t, "*new(Person)", Field{TypeName: "Person"}.ZeroValueLiteral())
+	assert.Equal(t, "*new(time.Time)", Field{TypeName: "Time", PackageQualifier: "time"}.ZeroValueLiteral())
+	// named types that are no struct
+	assert.Equal(t, "*new(io.Reader)", Field{TypeName: "Reader", PackageQualifier: "io"}.ZeroValueLiteral())
+	assert.Equal(t, "*new(time.Duration)", Field{TypeName: "Duration", PackageQualifier: "time"}.ZeroValueLiteral())
+}
+
+func TestTagGet(t *testing.T) {
+	f := Field{Name: "Name", TypeName: "string", Tag: "`json:\"name,omitempty\" db:\"name\"`"}
+	assert.Equal(t, `json:"name,omitempty" db:"name"`, string(f.ParsedTag()))
+	assert.Equal(t, "name,omitempty", f.TagGet("json"))
+	assert.Equal(t, "name", f.TagGet("db"))
+	assert.Equal(t, "", f.TagGet("xml"))
+}
+
+func TestTagLookup(t *testing.T) {
+	f := Field{Name: "Name", TypeName: "string", Tag: "`json:\"\" db:\"-\"`"}
+
+	value, found := f.TagLookup("json")
+	assert.True(t, found)
+	assert.Equal(t, "", value)
+
+	value, found = f.TagLookup("db")
+	assert.True(t, found)
+	assert.Equal(t, "-", value)
+
+	_, found = f.TagLookup("xml")
+	assert.False(t, found)
+}
+
+func TestTagWithoutTag(t *testing.T) {
+	f := Field{Name: "Name", TypeName: "string"}
+	assert.Equal(t, "", string(f.ParsedTag()))
+	assert.Equal(t, "", f.TagGet("json"))
+	_, found := f.TagLookup("json")
+	assert.False(t, found)
+}
+
+func TestParseTagInterpretedString(t *testing.T) {
+	tag := ParseTag(`"json:\"name,omitempty\""`)
+	assert.Equal(t, "name,omitempty", tag.Get("json"))
+}
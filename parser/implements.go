@@ -0,0 +1,200 @@
+package parser
+
+import "github.com/MarcGrol/golangAnnotations/model"
+
+// ImplementsMarshaler tells if the struct has a hand-written MarshalJSON() ([]byte, error)
+func (v *AstVisitor) ImplementsMarshaler(s model.Struct) bool {
+	return v.hasMethod(s, "MarshalJSON", []string{}, []string{"[]byte", "error"})
+}
+
+// ImplementsUnmarshaler tells if the struct has a hand-written UnmarshalJSON([]byte) error
+func (v *AstVisitor) ImplementsUnmarshaler(s model.Struct) bool {
+	return v.hasMethod(s, "UnmarshalJSON", []string{"[]byte"}, []string{"error"})
+}
+
+// ImplementsError tells if the struct has a hand-written Error() string
+func (v *AstVisitor) ImplementsError(s model.Struct) bool {
+	return v.hasMethod(s, "Error", []string{}, []string{"string"})
+}
+
+// ImplementsStringer tells if the struct has a hand-written String() string
+func (v *AstVisitor) ImplementsStringer(s model.Struct) bool {
+	return v.hasMethod(s, "String", []string{}, []string{"string"})
+}
+
+// hasMethod looks for a method with the given signature, regardless of a pointer- or value-receiver
+func (v *AstVisitor) hasMethod(s model.Struct, name string, inputTypes []string, outputTypes []string) bool {
+	for _, oper := range v.Operations {
+		if oper.RelatedStruct == nil || oper.RelatedStruct.TypeName != s.Name || oper.Name != name {
+			continue
+		}
+		if oper.PackageName != "" && s.PackageName != "" && oper.PackageName != s.PackageName {
+			continue
+		}
+		if hasTypes(oper.InputArgs, inputTypes) && hasTypes(oper.OutputArgs, outputTypes) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTypes(args []model.Field, typeNames []string) bool {
+	if len(args) != len(typeNames) {
+		return false
+	}
+	for idx, arg := range args {
+		if arg.TypeKey() != typeNames[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// MethodSetOf returns the method set of the struct-type, following the rules of Go: only methods with a value-receiver
+// are included, plus the methods that are promoted from embedded structs of the same package. An embedded struct
+// promotes its value-receiver methods, an embedded pointer all of its methods. Methods and fields of the struct itself
+// shadow promoted methods with the same name.
+func (v *AstVisitor) MethodSetOf(s model.Struct) []model.Operation {
+	return v.methodSetOf(s, false, map[string]bool{})
+}
+
+// PointerMethodSetOf returns the method set of the pointer to the struct: all methods of the struct, plus all methods
+// that are promoted from its embedded structs
+func (v *AstVisitor) PointerMethodSetOf(s model.Struct) []model.Operation {
+	return v.methodSetOf(s, true, map[string]bool{})
+}
+
+func (v *AstVisitor) methodSetOf(s model.Struct, pointer bool, visited map[string]bool) []model.Operation {
+	if visited[s.Name] {
+		return []model.Operation{}
+	}
+	visited[s.Name] = true
+	defer delete(visited, s.Name)
+
+	methods := []model.Operation{}
+	names := map[string]bool{}
+	for _, oper := range v.Operations {
+		if oper.RelatedStruct == nil || oper.RelatedStruct.TypeName != s.Name {
+			continue
+		}
+		if oper.PackageName != "" && s.PackageName != "" && oper.PackageName != s.PackageName {
+			continue
+		}
+		// a pointer-method is not part of the method set of the value, but it still shadows promoted methods
+		names[oper.Name] = true
+		if pointer || !oper.RelatedStruct.IsPointerReceiver {
+			methods = append(methods, oper)
+		}
+	}
+
+	fields := append(append([]model.Field{}, s.Fields...), s.PrivateFields...)
+	for _, f := range fields {
+		names[f.Name] = true
+	}
+	for _, f := range fields {
+		if !f.IsEmbedded || f.PackageQualifier != "" {
+			continue
+		}
+		embedded, found := v.findStruct(f.TypeName)
+		if !found {
+			continue
+		}
+		for _, oper := range v.methodSetOf(embedded, pointer || f.IsPointer, visited) {
+			if !names[oper.Name] {
+				methods = append(methods, oper)
+				names[oper.Name] = true
+			}
+		}
+	}
+	return methods
+}
+
+// Implements tells if the method set of the struct-type contains all methods of the interface with the given name,
+// including the methods of the interfaces it embeds. Interfaces that are not part of the harvest are never implemented.
+func (v *AstVisitor) Implements(s model.Struct, ifaceName string) bool {
+	return v.implements(v.MethodSetOf(s), ifaceName)
+}
+
+// PointerImplements tells if the pointer to the struct implements the interface, like Implements does for the
+// struct-type itself
+func (v *AstVisitor) PointerImplements(s model.Struct, ifaceName string) bool {
+	return v.implements(v.PointerMethodSetOf(s), ifaceName)
+}
+
+func (v *AstVisitor) implements(methods []model.Operation, ifaceName string) bool {
+	iface, found := v.findInterface(ifaceName)
+	if !found {
+		return false
+	}
+	required, complete := v.interfaceMethodsOf(iface, map[string]bool{})
+	if !complete {
+		return false
+	}
+	for _, m := range required {
+		if !containsMethod(methods, m) {
+			return false
+		}
+	}
+	return true
+}
+
+// interfaceMethodsOf returns the methods of the interface and of the interfaces it embeds: the result is incomplete when
+// an embedded interface is not part of the harvest, like io.Reader
+func (v *AstVisitor) interfaceMethodsOf(iface model.Interface, visited map[string]bool) ([]model.Operation, bool) {
+	if visited[iface.Name] {
+		return []model.Operation{}, true
+	}
+	visited[iface.Name] = true
+
+	methods := append([]model.Operation{}, iface.Methods...)
+	for _, e := range iface.Embedded {
+		if e.PackageName != "" {
+			return methods, false
+		}
+		embedded, found := v.findInterface(e.Name)
+		if !found {
+			return methods, false
+		}
+		promoted, complete := v.interfaceMethodsOf(embedded, visited)
+		if !complete {
+			return methods, false
+		}
+		methods = append(methods, promoted...)
+	}
+	return methods, true
+}
+
+func (v *AstVisitor) findStruct(name string) (model.Struct, bool) {
+	for _, s := range v.Structs {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return model.Struct{}, false
+}
+
+func (v *AstVisitor) findInterface(name string) (model.Interface, bool) {
+	for _, i := range v.Interfaces {
+		if i.Name == name {
+			return i, true
+		}
+	}
+	return model.Interface{}, false
+}
+
+func containsMethod(methods []model.Operation, m model.Operation) bool {
+	inputTypes := []string{}
+	for _, arg := range m.InputArgs {
+		inputTypes = append(inputTypes, arg.TypeKey())
+	}
+	outputTypes := []string{}
+	for _, arg := range m.OutputArgs {
+		outputTypes = append(outputTypes, arg.TypeKey())
+	}
+	for _, oper := range methods {
+		if oper.Name == m.Name && hasTypes(oper.InputArgs, inputTypes) && hasTypes(oper.OutputArgs, outputTypes) {
+			return true
+		}
+	}
+	return false
+}
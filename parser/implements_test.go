@@ -0,0 +1,231 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const implementsSource = `
+package implements
+
+type Money struct {
+	Amount int
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return nil, nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	return nil
+}
+
+func (m Money) String() string {
+	return ""
+}
+
+type Failure struct {
+	Reason string
+}
+
+func (f *Failure) Error() string {
+	return f.Reason
+}
+
+// String has the wrong signature to be a fmt.Stringer
+func (f Failure) String(verbose bool) string {
+	return f.Reason
+}
+`
+
+func TestImplementsWellKnownInterfaces(t *testing.T) {
+	harvest, err := ParseSourceString("implements.go", implementsSource)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(harvest.Structs))
+
+	money := harvest.Structs[0]
+	assert.Equal(t, "Money", money.Name)
+	assert.True(t, harvest.ImplementsMarshaler(money))
+	assert.True(t, harvest.ImplementsUnmarshaler(money))
+	assert.True(t, harvest.ImplementsStringer(money))
+	assert.False(t, harvest.ImplementsError(money))
+
+	failure := harvest.Structs[1]
+	assert.Equal(t, "Failure", failure.Name)
+	assert.True(t, harvest.ImplementsError(failure))
+	assert.False(t, harvest.ImplementsStringer(failure))
+	assert.False(t, harvest.ImplementsMarshaler(failure))
+	assert.False(t, harvest.ImplementsUnmarshaler(failure))
+}
+
+const methodSetSource = `
+package methodset
+
+type Store interface {
+	Get(id string) (string, error)
+	Put(id string, value string) error
+}
+
+type Getter interface {
+	Get(id string) (string, error)
+}
+
+type PutGetter interface {
+	Getter
+	Put(id string, value string) error
+}
+
+type ReadCloser interface {
+	Getter
+	io.Closer
+}
+
+type base struct {
+}
+
+func (b *base) Get(id string) (string, error) {
+	return "", nil
+}
+
+func (b *base) Put(id string, value string) error {
+	return nil
+}
+
+type CachedStore struct {
+	*base
+	Size int
+}
+
+// Get shadows the promoted method of base
+func (c *CachedStore) Get(id string) (string, error) {
+	return "", nil
+}
+
+type ReadOnlyStore struct {
+	Size int
+}
+
+func (r ReadOnlyStore) Get(id string) (string, error) {
+	return "", nil
+}
+
+type EmbeddingStore struct {
+	base
+}
+`
+
+func TestMethodSetIncludesPromotedMethods(t *testing.T) {
+	harvest, err := ParseSourceString("methodset.go", methodSetSource)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 4, len(harvest.Structs))
+
+	cached := harvest.Structs[1]
+	assert.Equal(t, "CachedStore", cached.Name)
+	methods := harvest.PointerMethodSetOf(cached)
+	assert.Equal(t, 2, len(methods))
+	assert.Equal(t, "Get", methods[0].Name)
+	assert.Equal(t, "CachedStore", methods[0].RelatedStruct.TypeName)
+	assert.Equal(t, "Put", methods[1].Name)
+	assert.Equal(t, "base", methods[1].RelatedStruct.TypeName)
+
+	assert.True(t, harvest.PointerImplements(cached, "Store"))
+	assert.True(t, harvest.PointerImplements(harvest.Structs[0], "Store"))
+	assert.False(t, harvest.Implements(harvest.Structs[2], "Store"))
+	assert.False(t, harvest.PointerImplements(cached, "Unknown"))
+
+	assert.True(t, harvest.PointerImplements(cached, "PutGetter"))
+	assert.False(t, harvest.Implements(harvest.Structs[2], "PutGetter"))
+	assert.True(t, harvest.Implements(harvest.Structs[2], "Getter"))
+	assert.True(t, harvest.PointerImplements(harvest.Structs[2], "Getter"))
+	// io.Closer is not part of the harvest
+	assert.False(t, harvest.PointerImplements(cached, "ReadCloser"))
+}
+
+func TestMethodSetOfValueExcludesPointerMethods(t *testing.T) {
+	harvest, err := ParseSourceString("methodset.go", methodSetSource)
+	assert.Equal(t, nil, err)
+
+	// the methods of base have pointer-receivers
+	base := harvest.Structs[0]
+	assert.Empty(t, harvest.MethodSetOf(base))
+	assert.False(t, harvest.Implements(base, "Store"))
+
+	// the embedded *base promotes all its methods, but Get is shadowed by the pointer-method of CachedStore
+	cached := harvest.Structs[1]
+	methods := harvest.MethodSetOf(cached)
+	assert.Equal(t, 1, len(methods))
+	assert.Equal(t, "Put", methods[0].Name)
+	assert.False(t, harvest.Implements(cached, "Store"))
+
+	// the embedded base only promotes its value-methods
+	embedding := harvest.Structs[3]
+	assert.Equal(t, "EmbeddingStore", embedding.Name)
+	assert.Empty(t, harvest.MethodSetOf(embedding))
+	assert.False(t, harvest.Implements(embedding, "Store"))
+	assert.Equal(t, 2, len(harvest.PointerMethodSetOf(embedding)))
+	assert.True(t, harvest.PointerImplements(embedding, "Store"))
+}
+
+const signatureSource = `
+package signatures
+
+type Indexer interface {
+	Index(names map[string]int) error
+}
+
+type Sender interface {
+	Send(items chan string) error
+}
+
+type Logger interface {
+	Log(format string, args ...string)
+}
+
+// every method only differs from the interface in the kind of its argument
+type Mismatch struct {
+}
+
+func (m Mismatch) Index(names map[string]string) error {
+	return nil
+}
+
+func (m Mismatch) Send(items []string) error {
+	return nil
+}
+
+func (m Mismatch) Log(format string, args []string) {
+}
+
+type Match struct {
+}
+
+func (m Match) Index(names map[string]int) error {
+	return nil
+}
+
+func (m Match) Send(items chan string) error {
+	return nil
+}
+
+func (m Match) Log(format string, args ...string) {
+}
+`
+
+func TestImplementsComparesMapChannelAndVariadicArguments(t *testing.T) {
+	harvest, err := ParseSourceString("signatures.go", signatureSource)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(harvest.Structs))
+
+	mismatch := harvest.Structs[0]
+	assert.Equal(t, "Mismatch", mismatch.Name)
+	assert.False(t, harvest.Implements(mismatch, "Indexer"))
+	assert.False(t, harvest.Implements(mismatch, "Sender"))
+	assert.False(t, harvest.Implements(mismatch, "Logger"))
+
+	match := harvest.Structs[1]
+	assert.Equal(t, "Match", match.Name)
+	assert.True(t, harvest.Implements(match, "Indexer"))
+	assert.True(t, harvest.Implements(match, "Sender"))
+	assert.True(t, harvest.Implements(match, "Logger"))
+}
@@ -9,4 +9,6 @@ type Doer interface {
 	doit(req Req) (Resp, error)
 	// docline for interface method dontDoit
 	dontDoit()
+	// docline for interface method doitAnonymously
+	doitAnonymously(string, int) error
 }
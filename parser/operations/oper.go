@@ -8,6 +8,11 @@ type Person struct {
 type Service struct {
 }
 
+// docline for newService
+func newService() *Service {
+	return &Service{}
+}
+
 // docline for getPersons
 func (serv *Service) getPersons() ([]Person, error) {
 	return []Person{
@@ -23,3 +28,10 @@ func (s Service) getPerson(uid string) (Person, *Person, error) {
 	}
 	return p, &p, nil
 }
+
+// docline for streamPersons
+func (s Service) streamPersons() (<-chan *Person, error) {
+	persons := make(chan *Person)
+	close(persons)
+	return persons, nil
+}
@@ -1,86 +1,449 @@
 package parser
 
 import (
+	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/scanner"
 	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
 	"log"
-	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/MarcGrol/golangAnnotations/annotation"
 	"github.com/MarcGrol/golangAnnotations/model"
 )
 
+// ParseOptions influence what ends up in the harvest
+type ParseOptions struct {
+	// IncludePrivateFields keeps unexported fields in Struct.Fields as well
+	IncludePrivateFields bool
+	// PackageFilter limits parsing to the files that declare this package: empty means all packages
+	PackageFilter string
+	// FailOnUnknownAnnotations turns annotations that are not registered into errors instead of warnings
+	FailOnUnknownAnnotations bool
+}
+
 type AstVisitor struct {
-	PackageName string
-	Structs     []model.Struct
-	Operations  []model.Operation
-	Interfaces  []model.Interface
+	PackageName        string
+	Structs            []model.Struct
+	Operations         []model.Operation // methods: operations with a receiver
+	FreeFunctions      []model.Operation // top-level functions without a receiver
+	Interfaces         []model.Interface
+	UnknownAnnotations []UnknownAnnotation // annotations that are used but not registered: register them before parsing
+	InvalidAnnotations []InvalidAnnotation // registered annotations that are ignored because of invalid attributes
+	AnnotationWarnings []AnnotationWarning // valid annotations that do not follow a soft rule
+	GenerateDirectives []GenerateDirective
+	Imports            []model.Import
+	currentFile        string
+	fileSet            *token.FileSet // to determine the line of a node while walking: nil when unknown
+}
+
+// GenerateDirective is a //go:generate-line in a source-file
+type GenerateDirective struct {
+	Command string // the command without the //go:generate-prefix
+	File    string // absolute path of the file
+	Line    int
 }
 
+const generateDirectivePrefix = "//go:generate "
+
 func ParseSourceFile(srcFilename string) (*AstVisitor, error) {
+	v, _, _, err := ParseSourceFileRaw(srcFilename)
+	return v, err
+}
+
+// ParseSourceFileRaw also returns the parsed file and its file-set, for callers that want to do their own ast-analysis
+func ParseSourceFileRaw(srcFilename string) (*AstVisitor, *ast.File, *token.FileSet, error) {
+	return parseSource(srcFilename, nil)
+}
+
+// VisitorFunc is called for every node of the ast while the file is parsed: returning false skips the children of the
+// node, for the function only
+type VisitorFunc func(node ast.Node) bool
+
+// ParseSourceFileWithVisitor calls fn for every node during the same walk that fills the harvest, so custom information
+// can be extracted without walking the ast a second time
+func ParseSourceFileWithVisitor(srcFilename string, fn VisitorFunc) (*AstVisitor, error) {
+	v, _, _, err := parseSourceWithVisitor(srcFilename, nil, fn)
+	return v, err
+}
+
+// ParseSourceString parses source-code that is held in memory: the filename is only used in error-messages
+func ParseSourceString(srcFilename string, source string) (*AstVisitor, error) {
+	v, _, _, err := parseSource(srcFilename, source)
+	return v, err
+}
+
+// ParseSourceReader parses the source-code that is read from r: the filename is only used in error-messages
+func ParseSourceReader(r io.Reader, srcFilename string) (*AstVisitor, error) {
+	source, err := ioutil.ReadAll(r)
+	if err != nil {
+		log.Printf("error reading src %s: %s", srcFilename, err.Error())
+		return nil, err
+	}
+	v, _, _, err := parseSource(srcFilename, source)
+	return v, err
+}
+
+// parseSource parses the source, or the file itself when source is nil
+func parseSource(srcFilename string, source interface{}) (*AstVisitor, *ast.File, *token.FileSet, error) {
+	return parseSourceWithVisitor(srcFilename, source, nil)
+}
+
+func parseSourceWithVisitor(srcFilename string, source interface{}, fn VisitorFunc) (*AstVisitor, *ast.File, *token.FileSet, error) {
 	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, srcFilename, nil, parser.ParseComments)
+	f, err := parser.ParseFile(fset, srcFilename, source, parser.ParseComments)
 	if err != nil {
 		log.Printf("error parsing src %s: %s", srcFilename, err.Error())
-		return nil, err
+		return nil, nil, nil, err
+	}
+	v := AstVisitor{currentFile: srcFilename, fileSet: fset}
+	if fn != nil {
+		ast.Walk(funcVisitor{harvester: &v, fn: fn}, f)
+	} else {
+		ast.Walk(&v, f)
 	}
-	v := AstVisitor{}
-	ast.Walk(&v, f)
-	return &v, nil
+	v.fileSet = nil
+	v.separatePrivateFields(ParseOptions{})
+	return &v, f, fset, nil
+}
+
+// funcVisitor fills the harvest and calls fn until fn skips the children of a node: the harvest keeps being filled
+type funcVisitor struct {
+	harvester *AstVisitor
+	fn        VisitorFunc
+}
+
+func (w funcVisitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		return nil
+	}
+	w.harvester.Visit(node)
+	if !w.fn(node) {
+		return w.harvester
+	}
+	return w
 }
 
 func ParseSourceDir(dirName string, filenameRegex string) (*AstVisitor, error) {
-	packages, err := parseDir(dirName, filenameRegex)
-	if err != nil {
+	return ParseSourceDirWithOptions(dirName, filenameRegex, ParseOptions{})
+}
+
+func ParseSourceDirWithOptions(dirName string, filenameRegex string, options ParseOptions) (*AstVisitor, error) {
+	v, errs := parseSourceDir(dirName, filenameRegex, options)
+	if len(errs) > 0 {
+		err := ParseError{Errors: errs}
 		log.Printf("error parsing dir %s: %s", dirName, err.Error())
 		return nil, err
 	}
+	return v, nil
+}
+
+// ParseSourceDirAllErrors does not stop at the first file that cannot be parsed: it returns the harvest of all other
+// files, together with a ParseError that lists every problem with its file and line
+func ParseSourceDirAllErrors(dirName string, filenameRegex string) (*AstVisitor, error) {
+	v, errs := parseSourceDir(dirName, filenameRegex, ParseOptions{})
+	if len(errs) > 0 {
+		return v, ParseError{Errors: errs}
+	}
+	return v, nil
+}
+
+func parseSourceDir(dirName string, filenameRegex string, options ParseOptions) (*AstVisitor, []error) {
+	v := AstVisitor{fileSet: token.NewFileSet()}
+	packages, errs := parseDir(v.fileSet, dirName, filenameRegex)
 
-	v := AstVisitor{}
 	for _, p := range packages {
-		for _, f := range p.Files {
-			ast.Walk(&v, f)
+		if options.PackageFilter != "" && p.Name != options.PackageFilter {
+			continue
+		}
+		// visit the files in a fixed order, so the harvest does not depend on the iteration-order of the map
+		fileNames := []string{}
+		for fileName := range p.Files {
+			fileNames = append(fileNames, fileName)
+		}
+		sort.Strings(fileNames)
+		for _, fileName := range fileNames {
+			v.currentFile = fileName
+			ast.Walk(&v, p.Files[fileName])
+		}
+	}
+	v.fileSet = nil
+
+	errs = append(errs, v.Validate()...)
+	if options.FailOnUnknownAnnotations {
+		for _, unknown := range v.UnknownAnnotations {
+			errs = append(errs, unknown)
+		}
+	}
+
+	v.separatePrivateFields(options)
+	v.linkOperationsToStructs()
+
+	return &v, errs
+}
+
+// ParseSourceDirWithFileInfo parses a directory like ParseSourceDir, but returns a harvest per source-file, keyed by
+// the absolute path of the file. Structs keep all their operations, also those declared in other files.
+func ParseSourceDirWithFileInfo(dirName string, filenameRegex string) (map[string]*AstVisitor, error) {
+	harvest, err := ParseSourceDir(dirName, filenameRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	perFile := map[string]*AstVisitor{}
+	visitorOf := func(sourceFile string) *AstVisitor {
+		v, found := perFile[sourceFile]
+		if !found {
+			v = &AstVisitor{PackageName: harvest.PackageName, currentFile: sourceFile}
+			perFile[sourceFile] = v
+		}
+		return v
+	}
+	for _, s := range harvest.Structs {
+		v := visitorOf(s.SourceFile)
+		v.Structs = append(v.Structs, s)
+	}
+	for _, o := range harvest.Operations {
+		v := visitorOf(o.SourceFile)
+		v.Operations = append(v.Operations, o)
+	}
+	for _, o := range harvest.FreeFunctions {
+		v := visitorOf(o.SourceFile)
+		v.FreeFunctions = append(v.FreeFunctions, o)
+	}
+	for _, i := range harvest.Interfaces {
+		v := visitorOf(i.SourceFile)
+		v.Interfaces = append(v.Interfaces, i)
+	}
+	for _, d := range harvest.GenerateDirectives {
+		v := visitorOf(d.File)
+		v.GenerateDirectives = append(v.GenerateDirectives, d)
+	}
+	for _, unknown := range harvest.UnknownAnnotations {
+		absPath, err := filepath.Abs(unknown.FilePath)
+		if err != nil {
+			absPath = unknown.FilePath
+		}
+		v := visitorOf(absPath)
+		v.UnknownAnnotations = append(v.UnknownAnnotations, unknown)
+	}
+	for _, invalid := range harvest.InvalidAnnotations {
+		absPath, err := filepath.Abs(invalid.FilePath)
+		if err != nil {
+			absPath = invalid.FilePath
+		}
+		v := visitorOf(absPath)
+		v.InvalidAnnotations = append(v.InvalidAnnotations, invalid)
+	}
+	for _, warning := range harvest.AnnotationWarnings {
+		absPath, err := filepath.Abs(warning.FilePath)
+		if err != nil {
+			absPath = warning.FilePath
+		}
+		v := visitorOf(absPath)
+		v.AnnotationWarnings = append(v.AnnotationWarnings, warning)
+	}
+	return perFile, nil
+}
+
+// GroupByPackage splits the harvest into a harvest per package, keyed by package-name, for generators that emit a file
+// per package. Unknown and invalid annotations go to the package of the file in which they are found.
+func (v *AstVisitor) GroupByPackage() map[string]*AstVisitor {
+	perPackage := map[string]*AstVisitor{}
+	packageOfFile := map[string]string{}
+	visitorOf := func(packageName string, sourceFile string) *AstVisitor {
+		if packageName == "" {
+			packageName = v.PackageName
+		}
+		if sourceFile != "" {
+			packageOfFile[sourceFile] = packageName
+		}
+		sub, found := perPackage[packageName]
+		if !found {
+			sub = &AstVisitor{PackageName: packageName, currentFile: v.currentFile}
+			perPackage[packageName] = sub
+		}
+		return sub
+	}
+	for _, s := range v.Structs {
+		sub := visitorOf(s.PackageName, s.SourceFile)
+		sub.Structs = append(sub.Structs, s)
+	}
+	for _, o := range v.Operations {
+		sub := visitorOf(o.PackageName, o.SourceFile)
+		sub.Operations = append(sub.Operations, o)
+	}
+	for _, o := range v.FreeFunctions {
+		sub := visitorOf(o.PackageName, o.SourceFile)
+		sub.FreeFunctions = append(sub.FreeFunctions, o)
+	}
+	for _, i := range v.Interfaces {
+		sub := visitorOf(i.PackageName, i.SourceFile)
+		sub.Interfaces = append(sub.Interfaces, i)
+	}
+	packageOf := func(filePath string) string {
+		absPath, err := filepath.Abs(filePath)
+		if err != nil {
+			absPath = filePath
+		}
+		return packageOfFile[absPath]
+	}
+	for _, d := range v.GenerateDirectives {
+		sub := visitorOf(packageOf(d.File), "")
+		sub.GenerateDirectives = append(sub.GenerateDirectives, d)
+	}
+	for _, i := range v.Imports {
+		sub := visitorOf(packageOf(i.SourceFile), "")
+		sub.Imports = append(sub.Imports, i)
+	}
+	for _, unknown := range v.UnknownAnnotations {
+		sub := visitorOf(packageOf(unknown.FilePath), "")
+		sub.UnknownAnnotations = append(sub.UnknownAnnotations, unknown)
+	}
+	for _, invalid := range v.InvalidAnnotations {
+		sub := visitorOf(packageOf(invalid.FilePath), "")
+		sub.InvalidAnnotations = append(sub.InvalidAnnotations, invalid)
+	}
+	for _, warning := range v.AnnotationWarnings {
+		sub := visitorOf(packageOf(warning.FilePath), "")
+		sub.AnnotationWarnings = append(sub.AnnotationWarnings, warning)
+	}
+	return perPackage
+}
+
+// separatePrivateFields moves the unexported fields of structs to PrivateFields, unless they should be included
+func (v *AstVisitor) separatePrivateFields(options ParseOptions) {
+	for idx := range v.Structs {
+		s := &v.Structs[idx]
+		exported := []model.Field{}
+		private := []model.Field{}
+		for _, f := range s.Fields {
+			if isExportedField(f) {
+				exported = append(exported, f)
+			} else {
+				private = append(private, f)
+			}
+		}
+		model.SortFieldsByDeclOrder(exported)
+		model.SortFieldsByDeclOrder(private)
+		s.PrivateFields = private
+		if !options.IncludePrivateFields {
+			s.Fields = exported
+		}
+	}
+}
+
+// isExportedField tells if a field is visible outside its package: embedded fields are named after their type
+func isExportedField(f model.Field) bool {
+	name := f.Name
+	if name == "" {
+		name = f.TypeName
+	}
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+// MergeVisitors combines the harvests of separately parsed files or directories into a single harvest, in which the
+// methods are linked to their struct, also when struct and methods come from different harvests
+func MergeVisitors(visitors ...*AstVisitor) *AstVisitor {
+	merged := &AstVisitor{}
+	for _, v := range visitors {
+		if v == nil {
+			continue
 		}
+		if merged.PackageName == "" {
+			merged.PackageName = v.PackageName
+		}
+		merged.Structs = append(merged.Structs, v.Structs...)
+		merged.Operations = append(merged.Operations, v.Operations...)
+		merged.FreeFunctions = append(merged.FreeFunctions, v.FreeFunctions...)
+		merged.Interfaces = append(merged.Interfaces, v.Interfaces...)
+		merged.UnknownAnnotations = append(merged.UnknownAnnotations, v.UnknownAnnotations...)
+		merged.InvalidAnnotations = append(merged.InvalidAnnotations, v.InvalidAnnotations...)
+		merged.AnnotationWarnings = append(merged.AnnotationWarnings, v.AnnotationWarnings...)
+		merged.GenerateDirectives = append(merged.GenerateDirectives, v.GenerateDirectives...)
+		merged.Imports = append(merged.Imports, v.Imports...)
 	}
+	merged.linkOperationsToStructs()
+	return merged
+}
 
-	allStructs := make(map[string]*model.Struct)
-	for idx, _ := range v.Structs {
-		allStructs[(&v.Structs[idx]).Name] = &v.Structs[idx]
+// linkOperationsToStructs makes the methods of a struct available via the struct: a method belongs to the struct with
+// the same name in the same package
+func (v *AstVisitor) linkOperationsToStructs() {
+	type structKey struct {
+		packageName string
+		name        string
+	}
+	allStructs := make(map[structKey]*model.Struct)
+	for idx := range v.Structs {
+		s := &v.Structs[idx]
+		// start over, so methods that were linked before are not linked twice
+		s.Operations = nil
+		allStructs[structKey{packageName: s.PackageName, name: s.Name}] = s
 	}
-	for idx, _ := range v.Operations {
+	for idx := range v.Operations {
 		oper := v.Operations[idx]
 		if oper.RelatedStruct != nil {
-			found, exists := allStructs[(*oper.RelatedStruct).TypeName]
+			found, exists := allStructs[structKey{packageName: oper.PackageName, name: oper.RelatedStruct.TypeName}]
 			if exists {
 				found.Operations = append(found.Operations, &oper)
 			}
 		}
 	}
-
-	return &v, nil
 }
 
-func parseDir(dirName string, filenameRegex string) (map[string]*ast.Package, error) {
+// parseDir parses every go-file in the directory that matches the regex. Unlike parser.ParseDir, it continues after a
+// file that cannot be parsed and returns the problems of all files.
+func parseDir(fset *token.FileSet, dirName string, filenameRegex string) (map[string]*ast.Package, []error) {
 	var pattern = regexp.MustCompile(filenameRegex)
 
 	packages := make(map[string]*ast.Package)
-	var err error
+	errs := []error{}
 
-	fset := token.NewFileSet()
-	packages, err = parser.ParseDir(
-		fset,
-		dirName,
-		func(fi os.FileInfo) bool {
-			return pattern.MatchString(fi.Name())
-		},
-		parser.ParseComments)
+	files, err := ioutil.ReadDir(dirName)
 	if err != nil {
 		log.Printf("error parsing dir %s: %s", dirName, err.Error())
-		return packages, err
+		return packages, append(errs, err)
+	}
+
+	for _, fi := range files {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), ".go") || !pattern.MatchString(fi.Name()) {
+			continue
+		}
+		fileName := filepath.Join(dirName, fi.Name())
+		f, err := parser.ParseFile(fset, fileName, nil, parser.ParseComments)
+		if err != nil {
+			log.Printf("error parsing src %s: %s", fileName, err.Error())
+			if errList, ok := err.(scanner.ErrorList); ok {
+				// every entry carries the file and line of the problem
+				for _, e := range errList {
+					errs = append(errs, e)
+				}
+			} else {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		p, found := packages[f.Name.Name]
+		if !found {
+			p = &ast.Package{Name: f.Name.Name, Files: make(map[string]*ast.File)}
+			packages[f.Name.Name] = p
+		}
+		p.Files[fileName] = f
 	}
 
-	return packages, nil
+	return packages, errs
 }
 
 func dumpFile(srcFilename string) {
@@ -119,12 +482,22 @@ func (v *AstVisitor) Visit(node ast.Node) ast.Visitor {
 			v.PackageName = pName
 		}
 
+		if f, ok := node.(*ast.File); ok {
+			v.collectGenerateDirectives(f)
+			v.Imports = append(v.Imports, extractImports(f, v.sourceFile())...)
+		}
+
 		{
 			// if struct, get its fields
 			str, found := extractGenDeclForStruct(node)
 			if found {
 				str.PackageName = v.PackageName
+				str.SourceFile = v.sourceFile()
 				v.Structs = append(v.Structs, str)
+				v.collectAnnotationProblems(str.Name, str.DocLines)
+				for _, f := range str.Fields {
+					v.collectAnnotationProblems(str.Name+"."+f.Name, f.DocLines)
+				}
 			}
 		}
 
@@ -133,7 +506,9 @@ func (v *AstVisitor) Visit(node ast.Node) ast.Visitor {
 			iface, found := extractGenDecForInterface(node)
 			if found {
 				iface.PackageName = v.PackageName
+				iface.SourceFile = v.sourceFile()
 				v.Interfaces = append(v.Interfaces, iface)
+				v.collectAnnotationProblems(iface.Name, iface.DocLines)
 			}
 		}
 
@@ -142,7 +517,15 @@ func (v *AstVisitor) Visit(node ast.Node) ast.Visitor {
 			operation, ok := extractOperation(node)
 			if ok {
 				operation.PackageName = v.PackageName
-				v.Operations = append(v.Operations, operation)
+				operation.SourceFile = v.sourceFile()
+				nodeName := operation.Name
+				if operation.RelatedStruct != nil {
+					v.Operations = append(v.Operations, operation)
+					nodeName = operation.RelatedStruct.TypeName + "." + nodeName
+				} else {
+					v.FreeFunctions = append(v.FreeFunctions, operation)
+				}
+				v.collectAnnotationProblems(nodeName, operation.DocLines)
 			}
 		}
 
@@ -150,6 +533,61 @@ func (v *AstVisitor) Visit(node ast.Node) ast.Visitor {
 	return v
 }
 
+// collectGenerateDirectives records the //go:generate-lines of the file
+func (v *AstVisitor) collectGenerateDirectives(f *ast.File) {
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			if !strings.HasPrefix(c.Text, generateDirectivePrefix) {
+				continue
+			}
+			directive := GenerateDirective{
+				Command: strings.TrimSpace(strings.TrimPrefix(c.Text, generateDirectivePrefix)),
+				File:    v.sourceFile(),
+			}
+			if v.fileSet != nil {
+				directive.Line = v.fileSet.Position(c.Slash).Line
+			}
+			v.GenerateDirectives = append(v.GenerateDirectives, directive)
+		}
+	}
+}
+
+// sourceFile returns the absolute path of the file that is being visited
+func (v *AstVisitor) sourceFile() string {
+	absPath, err := filepath.Abs(v.currentFile)
+	if err != nil {
+		return v.currentFile
+	}
+	return absPath
+}
+
+func (v *AstVisitor) collectAnnotationProblems(nodeName string, docLines []string) {
+	for _, name := range annotation.UnregisteredNames(docLines) {
+		v.UnknownAnnotations = append(v.UnknownAnnotations, UnknownAnnotation{
+			AnnotationName: name,
+			NodeName:       nodeName,
+			FilePath:       v.currentFile,
+		})
+	}
+	validationErrs, warnings := annotation.LintAll(docLines)
+	for _, validationErr := range validationErrs {
+		v.InvalidAnnotations = append(v.InvalidAnnotations, InvalidAnnotation{
+			AnnotationName: validationErr.AnnotationName,
+			NodeName:       nodeName,
+			FilePath:       v.currentFile,
+			Reason:         validationErr.Reason,
+		})
+	}
+	for _, warning := range warnings {
+		v.AnnotationWarnings = append(v.AnnotationWarnings, AnnotationWarning{
+			AnnotationName: warning.AnnotationName,
+			NodeName:       nodeName,
+			FilePath:       v.currentFile,
+			Warning:        warning.Warning,
+		})
+	}
+}
+
 func extractGenDeclForStruct(node ast.Node) (model.Struct, bool) {
 	found := false
 	var str model.Struct
@@ -158,9 +596,10 @@ func extractGenDeclForStruct(node ast.Node) (model.Struct, bool) {
 	if ok {
 		// Continue parsing to see if it a struct
 		str, found = extractSpecsForStruct(gd.Specs)
-		if ok {
+		if found {
 			// Docline of struct (that could contain annotations) appear far before the details of the struct
 			str.DocLines = extractDocLines(gd.Doc)
+			str.Description = extractDescription(str.DocLines)
 		}
 	}
 
@@ -175,7 +614,7 @@ func extractGenDecForInterface(node ast.Node) (model.Interface, bool) {
 	if ok {
 		// Continue parsing to see if it an interface
 		iface, found = extractSpecsForInterface(gd.Specs)
-		if ok {
+		if found {
 			// Docline of interface (that could contain annotations) appear far before the details of the struct
 			iface.DocLines = extractDocLines(gd.Doc)
 		}
@@ -192,10 +631,19 @@ func extractSpecsForStruct(specs []ast.Spec) (model.Struct, bool) {
 		ts, ok := specs[0].(*ast.TypeSpec)
 		if ok {
 			str.Name = ts.Name.Name
+			str.TypeParams = extractTypeParams(ts.TypeParams)
 
 			ss, ok := ts.Type.(*ast.StructType)
 			if ok {
 				str.Fields = extractFieldList(ss.Fields)
+				for idx := range str.Fields {
+					f := &str.Fields[idx]
+					if f.Name == "" {
+						f.IsEmbedded = true
+						f.Name = f.TypeName
+					}
+					f.IsSelfReferential = f.TypeName == str.Name && f.PackageQualifier == ""
+				}
 				found = true
 			}
 		}
@@ -216,6 +664,7 @@ func extractSpecsForInterface(specs []ast.Spec) (model.Interface, bool) {
 			it, ok := ts.Type.(*ast.InterfaceType)
 			if ok {
 				interf.Methods = extractInterfaceMethods(it.Methods)
+				interf.Embedded = extractEmbeddedInterfaces(it.Methods)
 				found = true
 			}
 		}
@@ -224,6 +673,23 @@ func extractSpecsForInterface(specs []ast.Spec) (model.Interface, bool) {
 	return interf, found
 }
 
+// extractImports returns the imports of the file in the order in which they are declared
+func extractImports(f *ast.File, sourceFile string) []model.Import {
+	imports := []model.Import{}
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			path = spec.Path.Value
+		}
+		imp := model.Import{Path: path, SourceFile: sourceFile}
+		if spec.Name != nil {
+			imp.Alias = spec.Name.Name
+		}
+		imports = append(imports, imp)
+	}
+	return imports
+}
+
 func extractPackageName(node ast.Node) (string, bool) {
 	name := ""
 
@@ -245,10 +711,13 @@ func extractOperation(node ast.Node) (model.Operation, bool) {
 	fd, found := node.(*ast.FuncDecl)
 	if found {
 		oper.DocLines = extractDocLines(fd.Doc)
+		oper.Description = extractDescription(oper.DocLines)
 
 		if fd.Recv != nil {
+			// the receiver is like any other field: its name is empty for receivers like (*Service)
 			recvd := extractFieldList(fd.Recv)
 			if len(recvd) >= 1 {
+				recvd[0].IsPointerReceiver = recvd[0].IsPointer
 				oper.RelatedStruct = &(recvd[0])
 			}
 		}
@@ -257,8 +726,10 @@ func extractOperation(node ast.Node) (model.Operation, bool) {
 			oper.Name = fd.Name.Name
 		}
 
+		oper.TypeParams = extractTypeParams(fd.Type.TypeParams)
+
 		if fd.Type.Params != nil {
-			oper.InputArgs = extractFieldList(fd.Type.Params)
+			oper.InputArgs = extractParamList(fd.Type.Params)
 		}
 
 		if fd.Type.Results != nil {
@@ -278,6 +749,27 @@ func extractDocLines(doc *ast.CommentGroup) []string {
 	return docLines
 }
 
+// extractDescription returns the human-readable part of the doc-lines, separated by newlines:
+// annotations and directives like //go:generate are left out
+func extractDescription(docLines []string) string {
+	lines := []string{}
+	for _, docLine := range docLines {
+		if strings.HasPrefix(docLine, "//go:") {
+			continue
+		}
+		text := strings.TrimPrefix(docLine, "//")
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		for _, line := range strings.Split(text, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "@") {
+				continue
+			}
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
 func extractComments(comment *ast.CommentGroup) []string {
 	lines := []string{}
 	if comment != nil {
@@ -303,9 +795,41 @@ func extractFieldList(fl *ast.FieldList) []model.Field {
 			fields = append(fields, flds...)
 		}
 	}
+	for idx := range fields {
+		fields[idx].DeclOrder = idx
+	}
 	return fields
 }
 
+// extractParamList assigns synthetic names to unnamed parameters, so that generators can refer to them
+func extractParamList(fl *ast.FieldList) []model.Field {
+	params := extractFieldList(fl)
+	for idx := range params {
+		if params[idx].Name == "" {
+			params[idx].Name = fmt.Sprintf("_arg%d", idx)
+		} else {
+			params[idx].IsNamedParam = true
+		}
+	}
+	return params
+}
+
+// extractTypeParams returns the type-parameters of a generic struct or function: [K comparable, V any] results in two
+func extractTypeParams(fl *ast.FieldList) []model.TypeParam {
+	params := []model.TypeParam{}
+	if fl != nil {
+		for _, p := range fl.List {
+			for _, name := range p.Names {
+				params = append(params, model.TypeParam{
+					Name:       name.Name,
+					Constraint: types.ExprString(p.Type),
+				})
+			}
+		}
+	}
+	return params
+}
+
 func extractInterfaceMethods(fl *ast.FieldList) []model.Operation {
 	methods := []model.Operation{}
 
@@ -318,7 +842,7 @@ func extractInterfaceMethods(fl *ast.FieldList) []model.Operation {
 			ft, found := m.Type.(*ast.FuncType)
 			if found {
 				if ft.Params != nil {
-					oper.InputArgs = extractFieldList(ft.Params)
+					oper.InputArgs = extractParamList(ft.Params)
 				}
 
 				if ft.Results != nil {
@@ -331,6 +855,26 @@ func extractInterfaceMethods(fl *ast.FieldList) []model.Operation {
 	return methods
 }
 
+// extractEmbeddedInterfaces returns the interfaces that are embedded by name: the type-sets of constraints, like
+// ~int | ~string, are skipped
+func extractEmbeddedInterfaces(fl *ast.FieldList) []model.EmbeddedInterface {
+	embedded := []model.EmbeddedInterface{}
+	for _, m := range fl.List {
+		if len(m.Names) > 0 {
+			continue
+		}
+		switch t := m.Type.(type) {
+		case *ast.Ident:
+			embedded = append(embedded, model.EmbeddedInterface{Name: t.Name})
+		case *ast.SelectorExpr:
+			if pkg, ok := t.X.(*ast.Ident); ok {
+				embedded = append(embedded, model.EmbeddedInterface{Name: t.Sel.Name, PackageName: pkg.Name})
+			}
+		}
+	}
+	return embedded
+}
+
 func extractFields(input *ast.Field) []model.Field {
 	fields := []model.Field{}
 	if input != nil {
@@ -364,40 +908,65 @@ func _extractField(input *ast.Field) model.Field {
 		arr, ok := input.Type.(*ast.ArrayType)
 		if ok {
 			field.IsSlice = true
-			{
-				ident, ok := arr.Elt.(*ast.Ident)
-				if ok {
-					field.TypeName = ident.Name
-				}
-			}
-			{
-				star, ok := arr.Elt.(*ast.StarExpr)
-				if ok {
-					ident, ok := star.X.(*ast.Ident)
-					if ok {
-						field.TypeName = ident.Name
-						field.IsPointer = true
-					}
-				}
-			}
+			field.TypeName, field.PackageQualifier, field.IsPointer = extractTypeRef(arr.Elt)
 		}
 	}
 	{
-		star, ok := input.Type.(*ast.StarExpr)
+		ch, ok := input.Type.(*ast.ChanType)
 		if ok {
-			ident, ok := star.X.(*ast.Ident)
-			if ok {
-				field.TypeName = ident.Name
-				field.IsPointer = true
-			}
+			field.IsChannel = true
+			field.TypeName, field.PackageQualifier, field.IsPointer = extractTypeRef(ch.Value)
 		}
 	}
 	{
-		ident, ok := input.Type.(*ast.Ident)
+		ellipsis, ok := input.Type.(*ast.Ellipsis)
 		if ok {
-			field.TypeName = ident.Name
+			field.IsVariadic = true
+			field.TypeName, field.PackageQualifier, field.IsPointer = extractTypeRef(ellipsis.Elt)
 		}
 	}
+	{
+		m, ok := input.Type.(*ast.MapType)
+		if ok {
+			field.IsMap = true
+			field.MapKeyTypeName, field.MapKeyPackage, _ = extractTypeRef(m.Key)
+			field.MapValueTypeName, field.MapValuePackage, field.MapValueIsPointer = extractTypeRef(m.Value)
+		}
+	}
+	switch input.Type.(type) {
+	case *ast.StarExpr, *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr:
+		field.TypeName, field.PackageQualifier, field.IsPointer = extractTypeRef(input.Type)
+	}
 
 	return field
 }
+
+// extractTypeRef resolves a reference to a type like Person, *Person, time.Time or *url.URL
+func extractTypeRef(expr ast.Expr) (typeName string, packageQualifier string, isPointer bool) {
+	star, ok := expr.(*ast.StarExpr)
+	if ok {
+		expr = star.X
+		isPointer = true
+	}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name, "", isPointer
+	case *ast.SelectorExpr:
+		ident, ok := t.X.(*ast.Ident)
+		if ok {
+			return t.Sel.Name, ident.Name, isPointer
+		}
+	case *ast.IndexExpr:
+		// instantiation of a generic type, like Result[int]: the type-arguments are not kept
+		typeName, packageQualifier, _ := extractTypeRef(t.X)
+		return typeName, packageQualifier, isPointer
+	case *ast.IndexListExpr:
+		typeName, packageQualifier, _ := extractTypeRef(t.X)
+		return typeName, packageQualifier, isPointer
+	case *ast.InterfaceType:
+		if t.Methods == nil || len(t.Methods.List) == 0 {
+			return "interface{}", "", isPointer
+		}
+	}
+	return "", "", false
+}
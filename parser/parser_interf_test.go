@@ -19,14 +19,14 @@ func TestInterfacesInDir(t *testing.T) {
 		assert.Equal(t, "Doer", i.Name)
 
 		{
-			assert.Len(t, i.Methods, 2)
+			assert.Len(t, i.Methods, 3)
 			{
 				m := i.Methods[0]
 				assert.Equal(t, []string{"// docline for interface method doit"}, m.DocLines)
 				assert.Equal(t, "doit", m.Name)
 				assert.Nil(t, m.RelatedStruct)
 				assert.Equal(t, 1, len(m.InputArgs))
-				assertField(t, model.Field{Name: "req", TypeName: "Req", IsSlice: false}, m.InputArgs[0])
+				assertField(t, model.Field{Name: "req", TypeName: "Req", IsSlice: false, IsNamedParam: true}, m.InputArgs[0])
 
 				assert.Equal(t, 2, len(m.OutputArgs))
 				assertField(t, model.Field{TypeName: "Resp", IsSlice: false}, m.OutputArgs[0])
@@ -40,6 +40,62 @@ func TestInterfacesInDir(t *testing.T) {
 				assert.Equal(t, 0, len(m.InputArgs))
 				assert.Equal(t, 0, len(m.OutputArgs))
 			}
+			{
+				m := i.Methods[2]
+				assert.Equal(t, "doitAnonymously", m.Name)
+				assert.Equal(t, 2, len(m.InputArgs))
+				assertField(t, model.Field{Name: "_arg0", TypeName: "string", IsNamedParam: false}, m.InputArgs[0])
+				assertField(t, model.Field{Name: "_arg1", TypeName: "int", IsNamedParam: false}, m.InputArgs[1])
+
+				assert.Equal(t, 1, len(m.OutputArgs))
+				assertField(t, model.Field{TypeName: "error"}, m.OutputArgs[0])
+			}
 		}
 	}
 }
+
+func TestEmbeddedInterfaces(t *testing.T) {
+	harvest, err := ParseSourceString("embedded.go", `
+package embedded
+
+import "io"
+
+type Getter interface {
+	Get(id string) (string, error)
+}
+
+type Store interface {
+	Getter
+}
+
+type ReadWriter interface {
+	io.Reader
+	io.Writer
+	Close() error
+}
+
+type Number interface {
+	~int | ~int64
+}
+`)
+	assert.Equal(t, nil, err)
+	assert.Len(t, harvest.Interfaces, 4)
+
+	getter := harvest.Interfaces[0]
+	assert.Empty(t, getter.Embedded)
+
+	store := harvest.Interfaces[1]
+	assert.Empty(t, store.Methods)
+	assert.Equal(t, []model.EmbeddedInterface{{Name: "Getter"}}, store.Embedded)
+
+	readWriter := harvest.Interfaces[2]
+	assert.Len(t, readWriter.Methods, 1)
+	assert.Equal(t, "Close", readWriter.Methods[0].Name)
+	assert.Equal(t, []model.EmbeddedInterface{
+		{Name: "Reader", PackageName: "io"},
+		{Name: "Writer", PackageName: "io"},
+	}, readWriter.Embedded)
+
+	number := harvest.Interfaces[3]
+	assert.Empty(t, number.Embedded)
+}
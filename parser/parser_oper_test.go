@@ -1,7 +1,11 @@
 package parser
 
 import (
+	"errors"
+	"os"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/MarcGrol/golangAnnotations/model"
 	"github.com/stretchr/testify/assert"
@@ -10,14 +14,17 @@ import (
 func TestStructOperationsInDir(t *testing.T) {
 	harvest, err := ParseSourceDir("./operations", ".*")
 	assert.Equal(t, nil, err)
-	assert.Equal(t, 2, len(harvest.Operations))
+	assert.Equal(t, 3, len(harvest.Operations))
+	assert.Equal(t, 1, len(harvest.FreeFunctions))
+	assert.Equal(t, "newService", harvest.FreeFunctions[0].Name)
+	assert.Nil(t, harvest.FreeFunctions[0].RelatedStruct)
 
 	{
 		o := harvest.Operations[0]
 		assert.Equal(t, "operations", o.PackageName)
 		assert.Equal(t, []string{"// docline for getPersons"}, o.DocLines)
 		assert.Equal(t, "getPersons", o.Name)
-		assertField(t, model.Field{Name: "serv", TypeName: "Service", IsPointer: true}, *o.RelatedStruct)
+		assertField(t, model.Field{Name: "serv", TypeName: "Service", IsPointer: true, IsPointerReceiver: true}, *o.RelatedStruct)
 
 		assert.Equal(t, 0, len(o.InputArgs))
 
@@ -33,11 +40,179 @@ func TestStructOperationsInDir(t *testing.T) {
 		assertField(t, model.Field{Name: "s", TypeName: "Service"}, *o.RelatedStruct)
 
 		assert.Equal(t, 1, len(o.InputArgs))
-		assertField(t, model.Field{Name: "uid", TypeName: "string"}, o.InputArgs[0])
+		assertField(t, model.Field{Name: "uid", TypeName: "string", IsNamedParam: true}, o.InputArgs[0])
 
 		assert.Equal(t, 3, len(o.OutputArgs))
 		assertField(t, model.Field{TypeName: "Person"}, o.OutputArgs[0])
 		assertField(t, model.Field{TypeName: "Person", IsPointer: true}, o.OutputArgs[1])
 		assertField(t, model.Field{TypeName: "error"}, o.OutputArgs[2])
 	}
+	{
+		o := harvest.Operations[2]
+		assert.Equal(t, "streamPersons", o.Name)
+
+		assert.Equal(t, 2, len(o.OutputArgs))
+		assertField(t, model.Field{TypeName: "Person", IsPointer: true, IsChannel: true}, o.OutputArgs[0])
+		assertField(t, model.Field{TypeName: "error"}, o.OutputArgs[1])
+	}
+}
+
+func TestQualifiedTypesInOperations(t *testing.T) {
+	harvest, err := ParseSourceDir("testdata/qualified", ".*")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(harvest.Operations))
+
+	o := harvest.Operations[0]
+	assert.Equal(t, "getUid", o.Name)
+
+	assert.Equal(t, 2, len(o.OutputArgs))
+	assertField(t, model.Field{TypeName: "UUID", PackageQualifier: "uuid"}, o.OutputArgs[0])
+	assertField(t, model.Field{TypeName: "error"}, o.OutputArgs[1])
+}
+
+func TestParseSourceReader(t *testing.T) {
+	fromFile, err := ParseSourceFile("operations/oper.go")
+	assert.Equal(t, nil, err)
+
+	f, err := os.Open("operations/oper.go")
+	assert.Equal(t, nil, err)
+	defer f.Close()
+	fromReader, err := ParseSourceReader(f, "operations/oper.go")
+	assert.Equal(t, nil, err)
+
+	// doc-lines are extracted the same way as from a file
+	assert.Equal(t, fromFile, fromReader)
+	assert.Equal(t, []string{"// docline for getPersons"}, fromReader.Operations[0].DocLines)
+}
+
+func TestParseSourceReaderWithError(t *testing.T) {
+	_, err := ParseSourceReader(iotest.ErrReader(errors.New("disk failure")), "broken.go")
+	assert.EqualError(t, err, "disk failure")
+
+	_, err = ParseSourceReader(strings.NewReader("package broken\n\nfunc {"), "broken.go")
+	assert.Error(t, err)
+}
+
+func TestReceivers(t *testing.T) {
+	harvest, err := ParseSourceString("receivers.go", `
+package receivers
+
+type Counter struct {
+	count int
+}
+
+func (c Counter) Get() int {
+	return c.count
+}
+
+func (c *Counter) Increment() {
+	c.count++
+}
+
+func (*Counter) Reset() {
+}
+
+func (Counter) Name() string {
+	return "counter"
+}
+`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 4, len(harvest.Operations))
+
+	assertField(t, model.Field{Name: "c", TypeName: "Counter"}, *harvest.Operations[0].RelatedStruct)
+	assertField(t, model.Field{Name: "c", TypeName: "Counter", IsPointer: true, IsPointerReceiver: true}, *harvest.Operations[1].RelatedStruct)
+	assertField(t, model.Field{TypeName: "Counter", IsPointer: true, IsPointerReceiver: true}, *harvest.Operations[2].RelatedStruct)
+	assertField(t, model.Field{TypeName: "Counter"}, *harvest.Operations[3].RelatedStruct)
+}
+
+func TestNamedReturnValues(t *testing.T) {
+	harvest, err := ParseSourceString("named.go", `
+package named
+
+func (s *Service) GetUser(id string) (user *User, err error) {
+	return nil, nil
+}
+
+func (s *Service) GetRange() (from, to int) {
+	return 0, 0
+}
+
+type Repository interface {
+	Find(id string) (found bool, err error)
+}
+`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(harvest.Operations))
+
+	{
+		o := harvest.Operations[0]
+		assert.Equal(t, 2, len(o.OutputArgs))
+		assertField(t, model.Field{Name: "user", TypeName: "User", IsPointer: true}, o.OutputArgs[0])
+		assertField(t, model.Field{Name: "err", TypeName: "error"}, o.OutputArgs[1])
+	}
+	{
+		o := harvest.Operations[1]
+		assert.Equal(t, 2, len(o.OutputArgs))
+		assertField(t, model.Field{Name: "from", TypeName: "int"}, o.OutputArgs[0])
+		assertField(t, model.Field{Name: "to", TypeName: "int"}, o.OutputArgs[1])
+	}
+	{
+		m := harvest.Interfaces[0].Methods[0]
+		assert.Equal(t, 2, len(m.OutputArgs))
+		assert.Equal(t, "found", m.OutputArgs[0].Name)
+		assert.Equal(t, "err", m.OutputArgs[1].Name)
+	}
+}
+
+func TestVariadicOperation(t *testing.T) {
+	harvest, err := ParseSourceString("variadic.go", `
+package variadic
+
+func (s *Service) Log(level int, args ...interface{}) {
+}
+
+func (s *Service) Tag(names ...*pkg.Name) {
+}
+`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(harvest.Operations))
+
+	{
+		o := harvest.Operations[0]
+		assert.Equal(t, 2, len(o.InputArgs))
+		assert.False(t, o.InputArgs[0].IsVariadic)
+		assert.Equal(t, "args", o.InputArgs[1].Name)
+		assert.True(t, o.InputArgs[1].IsVariadic)
+		assert.Equal(t, "interface{}", o.InputArgs[1].TypeName)
+		assert.False(t, o.InputArgs[1].IsSlice)
+	}
+	{
+		o := harvest.Operations[1]
+		assert.Equal(t, 1, len(o.InputArgs))
+		assertField(t, model.Field{Name: "names", TypeName: "Name", PackageQualifier: "pkg", IsPointer: true, IsVariadic: true, IsNamedParam: true}, o.InputArgs[0])
+	}
+}
+
+func TestGenericOperations(t *testing.T) {
+	harvest, err := ParseSourceDir("testdata/generics", ".*")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(harvest.Operations))
+	assert.Equal(t, 2, len(harvest.FreeFunctions))
+
+	{
+		o := harvest.Operations[0]
+		assert.Equal(t, "Get", o.Name)
+		assert.Empty(t, o.TypeParams)
+	}
+	{
+		o := harvest.FreeFunctions[0]
+		assert.Equal(t, "Map", o.Name)
+		assert.Equal(t, []model.TypeParam{{Name: "T", Constraint: "any"}, {Name: "U", Constraint: "any"}}, o.TypeParams)
+		assertField(t, model.Field{Name: "s", TypeName: "T", IsSlice: true, IsNamedParam: true}, o.InputArgs[0])
+	}
+	{
+		o := harvest.FreeFunctions[1]
+		assert.Equal(t, "Max", o.Name)
+		assert.Equal(t, []model.TypeParam{{Name: "T", Constraint: "constraints.Ordered"}}, o.TypeParams)
+	}
 }
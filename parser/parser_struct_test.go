@@ -1,8 +1,14 @@
 package parser
 
 import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
 	"testing"
 
+	"github.com/MarcGrol/golangAnnotations/annotation"
 	"github.com/MarcGrol/golangAnnotations/model"
 	"github.com/stretchr/testify/assert"
 )
@@ -46,20 +52,557 @@ func TestParseStructsInFile(t *testing.T) {
 			s.Fields[5])
 
 		assertField(t,
-			model.Field{Name: "Father", TypeName: "Person", IsPointer: true, IsSlice: false},
+			model.Field{Name: "Father", TypeName: "Person", IsPointer: true, IsSlice: false, IsSelfReferential: true},
 			s.Fields[6])
 
 		assertField(t,
-			model.Field{Name: "Uncles", TypeName: "Person", IsPointer: true, IsSlice: true},
+			model.Field{Name: "Uncles", TypeName: "Person", IsPointer: true, IsSlice: true, IsSelfReferential: true},
 			s.Fields[7])
 
 		assertField(t,
-			model.Field{Name: "Children", TypeName: "Person", IsPointer: false, IsSlice: true},
+			model.Field{Name: "Children", TypeName: "Person", IsPointer: false, IsSlice: true, IsSelfReferential: true},
 			s.Fields[8])
 
 	}
 }
 
+func TestParseStructsInFileRaw(t *testing.T) {
+	harvest, f, fset, err := ParseSourceFileRaw("structs/example.go")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(harvest.Structs))
+	assert.Equal(t, "structs", f.Name.Name)
+	assert.Equal(t, "structs/example.go", fset.Position(f.Pos()).Filename)
+}
+
+func TestParseWithVisitorFunc(t *testing.T) {
+	funcs := []string{}
+	structTypes := 0
+	harvest, err := ParseSourceFileWithVisitor("structs/example.go", func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncDecl:
+			funcs = append(funcs, n.Name.Name)
+		case *ast.StructType:
+			structTypes++
+		case *ast.GenDecl:
+			return false
+		}
+		return true
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"MyFunc", "Dump"}, funcs)
+
+	// the function skipped the type-declarations, the harvest did not
+	assert.Equal(t, 0, structTypes)
+	assert.Equal(t, 2, len(harvest.Structs))
+	assert.Equal(t, 1, len(harvest.Operations))
+	assert.Equal(t, 1, len(harvest.FreeFunctions))
+}
+
+func TestParseStructsInString(t *testing.T) {
+	harvest, err := ParseSourceString("inMemory.go", `
+package inmemory
+
+// docline for Person
+type Person struct {
+	Name string
+}
+`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "inmemory", harvest.PackageName)
+	assert.Equal(t, 1, len(harvest.Structs))
+	assert.Equal(t, "Person", harvest.Structs[0].Name)
+	assert.Equal(t, []string{"// docline for Person"}, harvest.Structs[0].DocLines)
+}
+
+func TestNonStructDeclarations(t *testing.T) {
+	harvest, err := ParseSourceString("decls.go", `
+package decls
+
+// imports with a doc-line
+import (
+	"fmt"
+)
+
+// variables with a doc-line
+var (
+	greeting = fmt.Sprintf("hello")
+)
+
+// Person is a struct
+type Person struct {
+	Name string
+}
+`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(harvest.Structs))
+	assert.Equal(t, "Person", harvest.Structs[0].Name)
+	assert.Equal(t, []string{"// Person is a struct"}, harvest.Structs[0].DocLines)
+}
+
+func TestImportIsNoStruct(t *testing.T) {
+	decl := &ast.GenDecl{
+		Doc:   &ast.CommentGroup{List: []*ast.Comment{{Text: "// imports with a doc-line"}}},
+		Tok:   token.IMPORT,
+		Specs: []ast.Spec{&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"fmt"`}}},
+	}
+
+	str, found := extractGenDeclForStruct(decl)
+	assert.False(t, found)
+	assert.Empty(t, str.DocLines)
+
+	iface, found := extractGenDecForInterface(decl)
+	assert.False(t, found)
+	assert.Empty(t, iface.DocLines)
+}
+
+func TestParseDescription(t *testing.T) {
+	harvest, err := ParseSourceString("description.go", `
+package description
+
+// Service manages persons.
+//
+// @RestService( path = "/api" )
+//go:generate golangAnnotations -input-dir .
+type Service struct{}
+
+// getPerson retrieves a person
+// by its id.
+// @RestOperation( method = "GET", path = "/person/{uid}" )
+func (s Service) getPerson(uid string) (Person, error) {
+	return Person{}, nil
+}
+
+// @RestOperation( method = "GET", path = "/person" )
+func (s Service) getPersons() ([]Person, error) {
+	return nil, nil
+}
+`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Service manages persons.", harvest.Structs[0].Description)
+	assert.Equal(t, "getPerson retrieves a person\nby its id.", harvest.Operations[0].Description)
+	assert.Equal(t, "", harvest.Operations[1].Description)
+}
+
+func TestParsePointerToQualifiedType(t *testing.T) {
+	harvest, err := ParseSourceString("pointer.go", `
+package pointer
+
+import "io"
+
+type Legacy struct {
+	Logger *io.Writer
+}
+`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(harvest.Structs))
+	f := harvest.Structs[0].Fields[0]
+	assert.Equal(t, "Logger", f.Name)
+	assert.Equal(t, "Writer", f.TypeName)
+	assert.Equal(t, "io", f.PackageQualifier)
+	assert.True(t, f.IsPointer)
+}
+
+func TestParseQualifiedTypes(t *testing.T) {
+	harvest, err := ParseSourceString("qualified.go", `
+package qualified
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Request struct {
+	CreatedAt time.Time
+	Origin    *url.URL
+	Headers   []http.Header
+	Redirects []*url.URL
+	Updates   chan time.Time
+}
+`)
+	assert.Equal(t, nil, err)
+	s := harvest.Structs[0]
+	assert.Equal(t, 5, len(s.Fields))
+
+	assertField(t, model.Field{Name: "CreatedAt", TypeName: "Time", PackageQualifier: "time"}, s.Fields[0])
+	assertField(t, model.Field{Name: "Origin", TypeName: "URL", PackageQualifier: "url", IsPointer: true}, s.Fields[1])
+	assertField(t, model.Field{Name: "Headers", TypeName: "Header", PackageQualifier: "http", IsSlice: true}, s.Fields[2])
+	assertField(t, model.Field{Name: "Redirects", TypeName: "URL", PackageQualifier: "url", IsSlice: true, IsPointer: true}, s.Fields[3])
+	assertField(t, model.Field{Name: "Updates", TypeName: "Time", PackageQualifier: "time", IsChannel: true}, s.Fields[4])
+}
+
+func TestParseMapTypes(t *testing.T) {
+	harvest, err := ParseSourceString("maps.go", `
+package maps
+
+import (
+	"pkg"
+	"time"
+)
+
+type Index struct {
+	Labels   map[string]string
+	Children map[int]*MyStruct
+	Values   map[MyKey]pkg.Value
+	Seen     map[string]*time.Time
+}
+`)
+	assert.Equal(t, nil, err)
+	s := harvest.Structs[0]
+	assert.Equal(t, 4, len(s.Fields))
+
+	for _, f := range s.Fields {
+		assert.True(t, f.IsMap)
+		assert.Equal(t, "", f.TypeName)
+		assert.False(t, f.IsSlice)
+	}
+	assert.Equal(t, "string", s.Fields[0].MapKeyTypeName)
+	assert.Equal(t, "string", s.Fields[0].MapValueTypeName)
+	assert.False(t, s.Fields[0].MapValueIsPointer)
+
+	assert.Equal(t, "int", s.Fields[1].MapKeyTypeName)
+	assert.Equal(t, "MyStruct", s.Fields[1].MapValueTypeName)
+	assert.True(t, s.Fields[1].MapValueIsPointer)
+
+	assert.Equal(t, "MyKey", s.Fields[2].MapKeyTypeName)
+	assert.Equal(t, "", s.Fields[2].MapKeyPackage)
+	assert.Equal(t, "Value", s.Fields[2].MapValueTypeName)
+	assert.Equal(t, "pkg", s.Fields[2].MapValuePackage)
+
+	assert.Equal(t, "Time", s.Fields[3].MapValueTypeName)
+	assert.Equal(t, "time", s.Fields[3].MapValuePackage)
+	assert.True(t, s.Fields[3].MapValueIsPointer)
+}
+
+func TestParseGenericStructs(t *testing.T) {
+	harvest, err := ParseSourceDir("testdata/generics", ".*")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(harvest.Structs))
+
+	result := harvest.Structs[0]
+	assert.Equal(t, "Result", result.Name)
+	assert.Equal(t, []model.TypeParam{{Name: "T", Constraint: "any"}}, result.TypeParams)
+	assertField(t, model.Field{Name: "Value", TypeName: "T"}, result.Fields[0])
+	assert.Equal(t, 1, len(result.Operations))
+	assert.Equal(t, "Get", result.Operations[0].Name)
+	assertField(t, model.Field{Name: "r", TypeName: "Result", IsPointer: true, IsPointerReceiver: true}, *result.Operations[0].RelatedStruct)
+
+	pair := harvest.Structs[1]
+	assert.Equal(t, "Pair", pair.Name)
+	assert.Equal(t, []model.TypeParam{{Name: "K", Constraint: "comparable"}, {Name: "V", Constraint: "any"}}, pair.TypeParams)
+
+	harvest, err = ParseSourceString("plain.go", "package plain\n\ntype Plain struct {\n\tID int\n}\n")
+	assert.Equal(t, nil, err)
+	assert.Empty(t, harvest.Structs[0].TypeParams)
+}
+
+func TestParseStructsPreservesDeclOrder(t *testing.T) {
+	harvest, err := ParseSourceString("order.go", `
+package order
+
+type Order struct {
+	Zone     string
+	ID, Rank int
+	password string
+	Amount   float64
+}
+`)
+	assert.Equal(t, nil, err)
+	s := harvest.Structs[0]
+	assert.Equal(t, 4, len(s.Fields))
+
+	blob, err := json.Marshal(s)
+	assert.Equal(t, nil, err)
+	var reparsed model.Struct
+	err = json.Unmarshal(blob, &reparsed)
+	assert.Equal(t, nil, err)
+
+	for idx, name := range []string{"Zone", "ID", "Rank", "Amount"} {
+		assert.Equal(t, name, reparsed.Fields[idx].Name)
+	}
+	assert.Equal(t, []int{0, 1, 2, 4}, []int{reparsed.Fields[0].DeclOrder, reparsed.Fields[1].DeclOrder, reparsed.Fields[2].DeclOrder, reparsed.Fields[3].DeclOrder})
+	assert.Equal(t, 3, reparsed.PrivateFields[0].DeclOrder)
+}
+
+func TestParseInvalidString(t *testing.T) {
+	_, err := ParseSourceString("invalid.go", "package")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid.go")
+}
+
+func TestParsePrivateFields(t *testing.T) {
+	harvest, err := ParseSourceString("private.go", `
+package private
+
+type Account struct {
+	Owner    string
+	password string
+	*Embedded
+}
+`)
+	assert.Equal(t, nil, err)
+	s := harvest.Structs[0]
+
+	assert.Equal(t, 2, len(s.Fields))
+	assertField(t, model.Field{Name: "Owner", TypeName: "string"}, s.Fields[0])
+	assertField(t, model.Field{Name: "Embedded", TypeName: "Embedded", IsPointer: true, IsEmbedded: true}, s.Fields[1])
+
+	assert.Equal(t, 1, len(s.PrivateFields))
+	assertField(t, model.Field{Name: "password", TypeName: "string"}, s.PrivateFields[0])
+}
+
+func TestParseEmbeddedFields(t *testing.T) {
+	harvest, err := ParseSourceString("admin.go", `
+package admin
+
+import "io"
+
+type Admin struct {
+	User
+	*Base
+	io.Reader
+	Role string
+}
+`)
+	assert.Equal(t, nil, err)
+	s := harvest.Structs[0]
+
+	assert.Equal(t, 4, len(s.Fields))
+	assertField(t, model.Field{Name: "User", TypeName: "User", IsEmbedded: true}, s.Fields[0])
+	assertField(t, model.Field{Name: "Base", TypeName: "Base", IsPointer: true, IsEmbedded: true}, s.Fields[1])
+	assertField(t, model.Field{Name: "Reader", TypeName: "Reader", PackageQualifier: "io", IsEmbedded: true}, s.Fields[2])
+	assertField(t, model.Field{Name: "Role", TypeName: "string"}, s.Fields[3])
+}
+
+func TestParseSelfReferentialFields(t *testing.T) {
+	harvest, err := ParseSourceString("node.go", `
+package tree
+
+import "other"
+
+type Node struct {
+	Value int
+	Next  *Node
+	Other other.Node
+}
+`)
+	assert.Equal(t, nil, err)
+	s := harvest.Structs[0]
+
+	assert.False(t, s.Fields[0].IsSelfReferential)
+	assert.True(t, s.Fields[1].IsSelfReferential)
+	assert.False(t, s.Fields[2].IsSelfReferential)
+}
+
+func TestParseDirIncludingPrivateFields(t *testing.T) {
+	harvest, err := ParseSourceDirWithOptions("testdata/private", ".*", ParseOptions{IncludePrivateFields: true})
+	assert.Equal(t, nil, err)
+
+	for _, s := range harvest.Structs {
+		if s.Name == "Account" {
+			assert.Equal(t, 3, len(s.Fields))
+			assert.Equal(t, 2, len(s.PrivateFields))
+			assert.Equal(t, "password", s.PrivateFields[0].Name)
+			assert.Equal(t, "secret", s.PrivateFields[1].TypeName)
+		}
+	}
+
+	harvest, err = ParseSourceDir("testdata/private", ".*")
+	assert.Equal(t, nil, err)
+	for _, s := range harvest.Structs {
+		if s.Name == "Account" {
+			assert.Equal(t, 1, len(s.Fields))
+		}
+	}
+}
+
+func TestParseDirWithPackageFilter(t *testing.T) {
+	harvest, err := ParseSourceDirWithOptions("testdata/multipackage", ".*", ParseOptions{PackageFilter: "mypackage"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "mypackage", harvest.PackageName)
+	assert.Equal(t, 1, len(harvest.Structs))
+	assert.Equal(t, "PersonCreated", harvest.Structs[0].Name)
+
+	harvest, err = ParseSourceDirWithOptions("testdata/multipackage", ".*", ParseOptions{PackageFilter: "mypackage_test"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "mypackage_test", harvest.PackageName)
+	assert.Equal(t, 1, len(harvest.Structs))
+	assert.Equal(t, "TestPersonCreated", harvest.Structs[0].Name)
+
+	harvest, err = ParseSourceDir("testdata/multipackage", ".*")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(harvest.Structs))
+}
+
+func TestGenerateDirectives(t *testing.T) {
+	harvest, err := ParseSourceDir("testdata/generate", ".*")
+	assert.Equal(t, nil, err)
+
+	serviceFile, err := filepath.Abs("testdata/generate/service.go")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []GenerateDirective{
+		{Command: "golangAnnotations -input-dir .", File: serviceFile, Line: 3},
+		{Command: "stringer -type=Status", File: serviceFile, Line: 4},
+	}, harvest.GenerateDirectives)
+}
+
+func TestImports(t *testing.T) {
+	harvest, err := ParseSourceDir("testdata/imports", ".*")
+	assert.Equal(t, nil, err)
+
+	serviceFile, err := filepath.Abs("testdata/imports/service.go")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []model.Import{
+		{Path: "fmt", SourceFile: serviceFile},
+		{Path: "net/http", SourceFile: serviceFile},
+		{Path: "github.com/MarcGrol/golangAnnotations/model", Alias: ".", SourceFile: serviceFile},
+		{Path: "github.com/MarcGrol/microgen/lib/myerrors", Alias: "myerrs", SourceFile: serviceFile},
+		{Path: "github.com/lib/pq", Alias: "_", SourceFile: serviceFile},
+	}, harvest.Imports)
+}
+
+func TestGenerateDirectivesInString(t *testing.T) {
+	harvest, err := ParseSourceString("generate.go", `package generate
+
+//go:generate go run gen.go
+`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(harvest.GenerateDirectives))
+	assert.Equal(t, "go run gen.go", harvest.GenerateDirectives[0].Command)
+	assert.Equal(t, 3, harvest.GenerateDirectives[0].Line)
+}
+
+func TestMergeVisitors(t *testing.T) {
+	structs, err := ParseSourceFile("testdata/merge/model.go")
+	assert.Equal(t, nil, err)
+	methods, err := ParseSourceFile("testdata/merge/service.go")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(structs.Operations))
+
+	merged := MergeVisitors(structs, methods)
+	assert.Equal(t, "merge", merged.PackageName)
+	assert.Equal(t, 1, len(merged.Structs))
+	assert.Equal(t, 2, len(merged.Operations))
+	assert.Equal(t, 2, len(merged.Structs[0].Operations))
+	assert.Equal(t, "Greet", merged.Structs[0].Operations[0].Name)
+	assert.Equal(t, "String", merged.Structs[0].Operations[1].Name)
+
+	// the original harvests are left alone
+	assert.Equal(t, 0, len(structs.Structs[0].Operations))
+}
+
+func TestMergeVisitorsDoesNotLinkTwice(t *testing.T) {
+	harvest, err := ParseSourceDir("testdata/merge", ".*")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(harvest.Structs[0].Operations))
+
+	merged := MergeVisitors(harvest, nil)
+	assert.Equal(t, 2, len(merged.Structs[0].Operations))
+}
+
+func TestMergeVisitorsKeepsPackagesApart(t *testing.T) {
+	merged := MergeVisitors(
+		&AstVisitor{PackageName: "a", Structs: []model.Struct{{PackageName: "a", Name: "Person"}}},
+		&AstVisitor{PackageName: "b", Structs: []model.Struct{{PackageName: "b", Name: "Person"}}},
+		&AstVisitor{PackageName: "b", Operations: []model.Operation{{PackageName: "b", Name: "Greet", RelatedStruct: &model.Field{TypeName: "Person"}}}},
+	)
+	assert.Equal(t, "a", merged.PackageName)
+	assert.Equal(t, 0, len(merged.Structs[0].Operations))
+	assert.Equal(t, 1, len(merged.Structs[1].Operations))
+}
+
+func TestGroupByPackage(t *testing.T) {
+	harvest, err := ParseSourceDir("testdata/multipackage", ".*")
+	assert.Equal(t, nil, err)
+
+	perPackage := harvest.GroupByPackage()
+	assert.Equal(t, 2, len(perPackage))
+	assert.Equal(t, "mypackage", perPackage["mypackage"].PackageName)
+	assert.Equal(t, 1, len(perPackage["mypackage"].Structs))
+	assert.Equal(t, "PersonCreated", perPackage["mypackage"].Structs[0].Name)
+	assert.Equal(t, "mypackage_test", perPackage["mypackage_test"].PackageName)
+	assert.Equal(t, 1, len(perPackage["mypackage_test"].Structs))
+	assert.Equal(t, "TestPersonCreated", perPackage["mypackage_test"].Structs[0].Name)
+}
+
+func TestGroupByPackageSplitsAllItems(t *testing.T) {
+	harvest := AstVisitor{
+		PackageName: "a",
+		Structs:     []model.Struct{{PackageName: "a", Name: "S1", SourceFile: "/src/a/a.go"}, {PackageName: "b", Name: "S2", SourceFile: "/src/b/b.go"}},
+		Operations:  []model.Operation{{PackageName: "b", Name: "o1", RelatedStruct: &model.Field{TypeName: "S2"}}},
+		FreeFunctions: []model.Operation{
+			{PackageName: "a", Name: "f1"},
+			{PackageName: "b", Name: "f2"},
+		},
+		Interfaces:         []model.Interface{{PackageName: "c", Name: "I1"}},
+		UnknownAnnotations: []UnknownAnnotation{{AnnotationName: "Typo", NodeName: "S2", FilePath: "/src/b/b.go"}},
+		InvalidAnnotations: []InvalidAnnotation{{AnnotationName: "Event", NodeName: "S1", FilePath: "/src/a/a.go"}},
+	}
+
+	perPackage := harvest.GroupByPackage()
+	assert.Equal(t, 3, len(perPackage))
+
+	a := perPackage["a"]
+	assert.Equal(t, 1, len(a.Structs))
+	assert.Equal(t, 0, len(a.Operations))
+	assert.Equal(t, 1, len(a.FreeFunctions))
+	assert.Equal(t, 1, len(a.InvalidAnnotations))
+	assert.Equal(t, 0, len(a.UnknownAnnotations))
+
+	b := perPackage["b"]
+	assert.Equal(t, "S2", b.Structs[0].Name)
+	assert.Equal(t, "o1", b.Operations[0].Name)
+	assert.Equal(t, "f2", b.FreeFunctions[0].Name)
+	assert.Equal(t, 1, len(b.UnknownAnnotations))
+
+	assert.Equal(t, "I1", perPackage["c"].Interfaces[0].Name)
+}
+
+func TestParseDirRecordsSourceFile(t *testing.T) {
+	harvest, err := ParseSourceDir("testdata/sourcefiles", ".*")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(harvest.Structs))
+
+	personFile, err := filepath.Abs("testdata/sourcefiles/person.go")
+	assert.Nil(t, err)
+	addressFile, err := filepath.Abs("testdata/sourcefiles/address.go")
+	assert.Nil(t, err)
+
+	// files are visited in alphabetical order
+	assert.Equal(t, "Address", harvest.Structs[0].Name)
+	assert.Equal(t, addressFile, harvest.Structs[0].SourceFile)
+	assert.Equal(t, "Person", harvest.Structs[1].Name)
+	assert.Equal(t, personFile, harvest.Structs[1].SourceFile)
+	assert.Equal(t, 1, len(harvest.Operations))
+	assert.Equal(t, personFile, harvest.Operations[0].SourceFile)
+}
+
+func TestParseDirWithFileInfo(t *testing.T) {
+	perFile, err := ParseSourceDirWithFileInfo("testdata/sourcefiles", ".*")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(perFile))
+
+	personFile, err := filepath.Abs("testdata/sourcefiles/person.go")
+	assert.Nil(t, err)
+	harvest, found := perFile[personFile]
+	assert.True(t, found)
+	assert.Equal(t, "sourcefiles", harvest.PackageName)
+	assert.Equal(t, 1, len(harvest.Structs))
+	assert.Equal(t, "Person", harvest.Structs[0].Name)
+	assert.Equal(t, 1, len(harvest.Structs[0].Operations))
+	assert.Equal(t, 1, len(harvest.Operations))
+
+	addressFile, err := filepath.Abs("testdata/sourcefiles/address.go")
+	assert.Nil(t, err)
+	harvest, found = perFile[addressFile]
+	assert.True(t, found)
+	assert.Equal(t, 1, len(harvest.Structs))
+	assert.Equal(t, "Address", harvest.Structs[0].Name)
+	assert.Equal(t, 0, len(harvest.Operations))
+}
+
 func TestParseStructsInDir(t *testing.T) {
 	harvest, err := ParseSourceDir("structs", ".*xample.*")
 	assert.Equal(t, nil, err)
@@ -93,8 +636,15 @@ func assertField(t *testing.T, expected model.Field, actual model.Field) {
 
 	assert.Equal(t, expected.Name, actual.Name)
 	assert.Equal(t, expected.TypeName, actual.TypeName)
+	assert.Equal(t, expected.PackageQualifier, actual.PackageQualifier)
 	assert.Equal(t, expected.IsPointer, actual.IsPointer)
+	assert.Equal(t, expected.IsPointerReceiver, actual.IsPointerReceiver)
 	assert.Equal(t, expected.IsSlice, actual.IsSlice)
+	assert.Equal(t, expected.IsChannel, actual.IsChannel)
+	assert.Equal(t, expected.IsVariadic, actual.IsVariadic)
+	assert.Equal(t, expected.IsEmbedded, actual.IsEmbedded)
+	assert.Equal(t, expected.IsNamedParam, actual.IsNamedParam)
+	assert.Equal(t, expected.IsSelfReferential, actual.IsSelfReferential)
 	assert.Equal(t, expected.Tag, actual.Tag)
 	assert.Equal(t, len(expected.CommentLines), len(actual.CommentLines))
 	assertStringSlice(t, expected.CommentLines, actual.CommentLines)
@@ -119,3 +669,91 @@ func assertStringSlice(t *testing.T, expected []string, actual []string) {
 		}
 	}
 }
+
+func registerRestAnnotations() {
+	annotation.ClearRegisteredAnnotations()
+	validateOk := func(annot annotation.Annotation) bool { return true }
+	annotation.RegisterAnnotation("RestService", []string{"path"}, validateOk)
+	annotation.RegisterAnnotation("RestOperation", []string{"method", "path"}, validateOk)
+	annotation.RegisterAnnotation("Sensitive", []string{}, validateOk)
+}
+
+func TestParseUnknownAnnotations(t *testing.T) {
+	registerRestAnnotations()
+	defer annotation.ClearRegisteredAnnotations()
+
+	harvest, err := ParseSourceDir("testdata/unknown", ".*")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []UnknownAnnotation{
+		{AnnotationName: "Sensitiv", NodeName: "Service.Password", FilePath: "testdata/unknown/service.go"},
+		{AnnotationName: "RestOperaton", NodeName: "Service.getPerson", FilePath: "testdata/unknown/service.go"},
+	}, harvest.UnknownAnnotations)
+}
+
+func TestParseInvalidAnnotations(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	defer annotation.ClearRegisteredAnnotations()
+	annotation.RegisterExplainingAnnotation("RestOperation", []string{"method", "path"}, func(annot annotation.Annotation) (bool, error) {
+		if annot.Attributes["path"] == "" {
+			return false, fmt.Errorf("@RestOperation requires a non-empty 'path' attribute")
+		}
+		return true, nil
+	})
+
+	harvest, err := ParseSourceString("service.go", `package invalid
+
+type Service struct{}
+
+// @RestOperation( method = "GET" )
+func (s *Service) getPerson() error {
+	return nil
+}
+`)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(harvest.InvalidAnnotations))
+	invalid := harvest.InvalidAnnotations[0]
+	assert.Equal(t, "RestOperation", invalid.AnnotationName)
+	assert.Equal(t, "Service.getPerson", invalid.NodeName)
+	assert.Equal(t, "Invalid annotation @RestOperation on Service.getPerson in service.go: @RestOperation requires a non-empty 'path' attribute", invalid.Error())
+}
+
+func TestParseAnnotationWarnings(t *testing.T) {
+	annotation.ClearRegisteredAnnotations()
+	defer annotation.ClearRegisteredAnnotations()
+	annotation.RegisterAnnotation("RestOperation", []string{"method", "path", "description"}, func(annot annotation.Annotation) bool {
+		return true
+	}, func(annot annotation.Annotation) string {
+		if annot.Attributes["description"] == "" {
+			return "operation has no description"
+		}
+		return ""
+	})
+
+	harvest, err := ParseSourceString("service.go", `package warnings
+
+type Service struct{}
+
+// @RestOperation( method = "GET", path = "/person" )
+func (s *Service) getPerson() error {
+	return nil
+}
+`)
+	assert.Equal(t, nil, err)
+	assert.Empty(t, harvest.InvalidAnnotations)
+	assert.Equal(t, 1, len(harvest.AnnotationWarnings))
+	assert.Equal(t, "Annotation @RestOperation on Service.getPerson in service.go: operation has no description", harvest.AnnotationWarnings[0].String())
+
+	// the annotation is still used
+	assert.Equal(t, 1, len(harvest.Operations))
+	_, ok := annotation.ResolveAnnotationByName(harvest.Operations[0].DocLines, "RestOperation")
+	assert.True(t, ok)
+}
+
+func TestParseFailOnUnknownAnnotations(t *testing.T) {
+	registerRestAnnotations()
+	defer annotation.ClearRegisteredAnnotations()
+
+	_, err := ParseSourceDirWithOptions("testdata/unknown", ".*", ParseOptions{FailOnUnknownAnnotations: true})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Unknown annotation @RestOperaton on Service.getPerson in testdata/unknown/service.go")
+}
@@ -0,0 +1,5 @@
+package duplicates
+
+type Person struct {
+	Name string
+}
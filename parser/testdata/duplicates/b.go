@@ -0,0 +1,8 @@
+package duplicates
+
+type Person struct {
+	FirstName string
+}
+
+type Other struct {
+}
@@ -0,0 +1,10 @@
+package generate
+
+//go:generate golangAnnotations -input-dir .
+//go:generate   stringer -type=Status
+
+// Status is not a directive: //go:generate is only recognized at the start of a comment
+type Status int
+
+// go:generate with a space is no directive either
+type Service struct{}
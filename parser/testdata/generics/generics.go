@@ -0,0 +1,32 @@
+package generics
+
+import "golang.org/x/exp/constraints"
+
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+func (r *Result[T]) Get() (T, error) {
+	return r.Value, r.Err
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+func Map[T, U any](s []T, f func(T) U) []U {
+	result := make([]U, 0, len(s))
+	for _, v := range s {
+		result = append(result, f(v))
+	}
+	return result
+}
+
+func Max[T constraints.Ordered](a T, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,19 @@
+package imports
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/MarcGrol/golangAnnotations/model"
+	myerrs "github.com/MarcGrol/microgen/lib/myerrors"
+	_ "github.com/lib/pq"
+)
+
+type Service struct {
+	Operation Operation
+}
+
+func (s Service) Describe(w http.ResponseWriter) error {
+	fmt.Fprintf(w, "%s", s.Operation.Name)
+	return myerrs.NewInternalError(fmt.Errorf("not implemented"))
+}
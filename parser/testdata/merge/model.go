@@ -0,0 +1,6 @@
+package merge
+
+// Person is declared in another file than its methods
+type Person struct {
+	Name string
+}
@@ -0,0 +1,9 @@
+package merge
+
+func (p *Person) Greet() string {
+	return "Hello " + p.Name
+}
+
+func (p Person) String() string {
+	return p.Name
+}
@@ -0,0 +1,6 @@
+package mypackage
+
+// @Event( aggregate = "Person" )
+type PersonCreated struct {
+	Name string
+}
@@ -0,0 +1,6 @@
+package mypackage_test
+
+// @Event( aggregate = "Person" )
+type TestPersonCreated struct {
+	Name string
+}
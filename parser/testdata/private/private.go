@@ -0,0 +1,9 @@
+package private
+
+type secret struct{}
+
+type Account struct {
+	Owner    string
+	password string
+	secret
+}
@@ -0,0 +1,11 @@
+package qualified
+
+import "github.com/satori/go.uuid"
+
+type Service struct {
+}
+
+// docline for getUid
+func (s Service) getUid() (uuid.UUID, error) {
+	return uuid.NewV4()
+}
@@ -0,0 +1,5 @@
+package sourcefiles
+
+type Address struct {
+	Street string
+}
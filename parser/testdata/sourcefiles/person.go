@@ -0,0 +1,9 @@
+package sourcefiles
+
+type Person struct {
+	Name string
+}
+
+func (p Person) greet() string {
+	return "Hello " + p.Name
+}
@@ -0,0 +1,4 @@
+package syntaxerrors
+
+type Broken struct {
+	Name string
@@ -0,0 +1,5 @@
+package syntaxerrors
+
+type Person struct {
+	Name string
+}
@@ -0,0 +1,5 @@
+package syntaxerrors
+
+func (p Person) greet() string {
+	return "Hello " +
+}
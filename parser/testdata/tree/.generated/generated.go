@@ -0,0 +1,4 @@
+package generated
+
+type Generated struct {
+}
@@ -0,0 +1,5 @@
+package items
+
+type Item struct {
+	SKU string
+}
@@ -0,0 +1,5 @@
+package orders
+
+type Order struct {
+	ID string
+}
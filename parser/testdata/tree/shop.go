@@ -0,0 +1,5 @@
+package tree
+
+type Shop struct {
+	Name string
+}
@@ -0,0 +1,12 @@
+package unknown
+
+// @RestService( path = "/api" )
+type Service struct {
+	// @Sensitiv
+	Password string
+}
+
+// @RestOperaton( method = "GET", path = "/person" )
+func (s *Service) getPerson() error {
+	return nil
+}
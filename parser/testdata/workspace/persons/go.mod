@@ -0,0 +1,3 @@
+module example.com/persons
+
+go 1.21
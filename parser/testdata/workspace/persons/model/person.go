@@ -0,0 +1,6 @@
+package model
+
+// docline for Person
+type Person struct {
+	Name string
+}
@@ -0,0 +1,13 @@
+package main
+
+// docline for Config
+type Config struct {
+	Name string
+}
+
+func (c Config) describe() string {
+	return c.Name
+}
+
+func main() {
+}
@@ -0,0 +1,5 @@
+module example.com/tours
+
+go 1.21
+
+require example.com/persons v0.0.0
@@ -0,0 +1,14 @@
+package tours
+
+import "example.com/persons/model"
+
+// docline for Tour
+type Tour struct {
+	Year   int
+	Winner model.Person
+}
+
+// docline for getWinner
+func (t Tour) getWinner() model.Person {
+	return t.Winner
+}
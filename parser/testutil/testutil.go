@@ -0,0 +1,17 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/parser"
+)
+
+// MustParseString parses the given source-code and aborts the test when it cannot be parsed
+func MustParseString(t testing.TB, source string) *parser.AstVisitor {
+	t.Helper()
+	harvest, err := parser.ParseSourceString(t.Name()+".go", source)
+	if err != nil {
+		t.Fatalf("Error parsing source: %s", err)
+	}
+	return harvest
+}
@@ -0,0 +1,21 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMustParseString(t *testing.T) {
+	harvest := MustParseString(t, `
+package example
+
+func doit(name string) error {
+	return nil
+}
+`)
+	assert.Equal(t, "example", harvest.PackageName)
+	assert.Equal(t, 0, len(harvest.Operations))
+	assert.Equal(t, 1, len(harvest.FreeFunctions))
+	assert.Equal(t, "doit", harvest.FreeFunctions[0].Name)
+}
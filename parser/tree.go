@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TreeOptions influence which directories ParseSourceTree descends into
+type TreeOptions struct {
+	// IncludeHiddenDirs also parses directories of which the name starts with a dot
+	IncludeHiddenDirs bool
+	// IncludeVendor also parses vendor-directories
+	IncludeVendor bool
+	// ModulePackagesOnly skips what the go-tool skips within a module: nested modules, testdata-directories and
+	// directories of which the name starts with an underscore
+	ModulePackagesOnly bool
+}
+
+// ParseSourceTree parses every directory below rootDir that holds go-files matching the regex, skipping hidden and
+// vendor-directories: the result is keyed by the slash-separated path of the directory, relative to rootDir
+func ParseSourceTree(rootDir string, filenameRegex string) (map[string]*AstVisitor, error) {
+	return ParseSourceTreeWithOptions(rootDir, filenameRegex, TreeOptions{})
+}
+
+func ParseSourceTreeWithOptions(rootDir string, filenameRegex string, options TreeOptions) (map[string]*AstVisitor, error) {
+	pattern := regexp.MustCompile(filenameRegex)
+
+	visitors := make(map[string]*AstVisitor)
+	err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != rootDir {
+			name := d.Name()
+			if !options.IncludeHiddenDirs && strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			if !options.IncludeVendor && name == "vendor" {
+				return filepath.SkipDir
+			}
+			if options.ModulePackagesOnly && (name == "testdata" || strings.HasPrefix(name, "_") || isModuleDir(path)) {
+				return filepath.SkipDir
+			}
+		}
+		if !hasMatchingGoFiles(path, pattern) {
+			return nil
+		}
+
+		v, err := ParseSourceDir(path, filenameRegex)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return err
+		}
+		visitors[filepath.ToSlash(rel)] = v
+		return nil
+	})
+	if err != nil {
+		log.Printf("error parsing tree %s: %s", rootDir, err.Error())
+		return nil, err
+	}
+	return visitors, nil
+}
+
+func isModuleDir(dirName string) bool {
+	_, err := os.Stat(filepath.Join(dirName, "go.mod"))
+	return err == nil
+}
+
+func hasMatchingGoFiles(dirName string, pattern *regexp.Regexp) bool {
+	files, err := ioutil.ReadDir(dirName)
+	if err != nil {
+		return false
+	}
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".go") && pattern.MatchString(f.Name()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSourceTree(t *testing.T) {
+	visitors, err := ParseSourceTree("testdata/tree", ".*")
+	assert.NoError(t, err)
+	assert.Len(t, visitors, 3)
+
+	shop := visitors["."]
+	assert.NotNil(t, shop)
+	assert.Equal(t, "tree", shop.PackageName)
+	assert.Equal(t, 1, len(shop.Structs))
+	assert.Equal(t, "Shop", shop.Structs[0].Name)
+
+	orders := visitors["orders"]
+	assert.NotNil(t, orders)
+	assert.Equal(t, 1, len(orders.Structs))
+	assert.Equal(t, "Order", orders.Structs[0].Name)
+
+	items := visitors["orders/items"]
+	assert.NotNil(t, items)
+	assert.Equal(t, 1, len(items.Structs))
+	assert.Equal(t, "Item", items.Structs[0].Name)
+}
+
+func TestParseSourceTreeWithOptions(t *testing.T) {
+	visitors, err := ParseSourceTreeWithOptions("testdata/tree", ".*", TreeOptions{IncludeHiddenDirs: true, IncludeVendor: true})
+	assert.NoError(t, err)
+	assert.Len(t, visitors, 5)
+	assert.Equal(t, "Generated", visitors[".generated"].Structs[0].Name)
+	assert.Equal(t, "Vendored", visitors["vendor/lib"].Structs[0].Name)
+
+	visitors, err = ParseSourceTree("testdata/tree", "order.*")
+	assert.NoError(t, err)
+	assert.Len(t, visitors, 1)
+	assert.NotNil(t, visitors["orders"])
+}
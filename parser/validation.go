@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DuplicateError reports a struct that is declared more than once within the same package
+type DuplicateError struct {
+	PackageName string
+	Name        string
+}
+
+func (e DuplicateError) Error() string {
+	return fmt.Sprintf("Struct %s is declared multiple times in package %s", e.Name, e.PackageName)
+}
+
+// UnknownAnnotation is an annotation that is used in the source-code but has not been registered, like a typo
+type UnknownAnnotation struct {
+	AnnotationName string
+	NodeName       string
+	FilePath       string
+}
+
+func (e UnknownAnnotation) Error() string {
+	return fmt.Sprintf("Unknown annotation @%s on %s in %s", e.AnnotationName, e.NodeName, e.FilePath)
+}
+
+// InvalidAnnotation is a registered annotation that is ignored because its attributes are not valid
+type InvalidAnnotation struct {
+	AnnotationName string
+	NodeName       string
+	FilePath       string
+	Reason         error
+}
+
+func (e InvalidAnnotation) Error() string {
+	return fmt.Sprintf("Invalid annotation @%s on %s in %s: %s", e.AnnotationName, e.NodeName, e.FilePath, e.Reason)
+}
+
+// AnnotationWarning is a valid annotation that does not follow a soft rule: it is still used by the generators
+type AnnotationWarning struct {
+	AnnotationName string
+	NodeName       string
+	FilePath       string
+	Warning        string
+}
+
+func (w AnnotationWarning) String() string {
+	return fmt.Sprintf("Annotation @%s on %s in %s: %s", w.AnnotationName, w.NodeName, w.FilePath, w.Warning)
+}
+
+// ParseError combines all problems encountered while parsing
+type ParseError struct {
+	Errors []error
+}
+
+func (e ParseError) Error() string {
+	msgs := []string{}
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Validate checks the harvest for problems that prevent the parsed code from compiling
+func (v *AstVisitor) Validate() []error {
+	errs := []error{}
+
+	type structKey struct {
+		packageName string
+		name        string
+	}
+	seen := make(map[structKey]bool)
+	for _, s := range v.Structs {
+		key := structKey{packageName: s.PackageName, name: s.Name}
+		if seen[key] {
+			errs = append(errs, DuplicateError{PackageName: s.PackageName, Name: s.Name})
+		}
+		seen[key] = true
+	}
+
+	return errs
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDuplicateStructs(t *testing.T) {
+	v := AstVisitor{
+		Structs: []model.Struct{
+			{PackageName: "a", Name: "Person"},
+			{PackageName: "b", Name: "Person"},
+			{PackageName: "a", Name: "Other"},
+			{PackageName: "a", Name: "Person"},
+		},
+	}
+	errs := v.Validate()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, DuplicateError{PackageName: "a", Name: "Person"}, errs[0])
+}
+
+func TestValidateWithoutDuplicates(t *testing.T) {
+	harvest, err := ParseSourceDir("./structs", ".*")
+	assert.NoError(t, err)
+	assert.Empty(t, harvest.Validate())
+}
+
+func TestParseDirWithDuplicateStructs(t *testing.T) {
+	_, err := ParseSourceDir("./testdata/duplicates", ".*")
+	assert.Error(t, err)
+
+	parseErr, ok := err.(ParseError)
+	assert.True(t, ok)
+	assert.Equal(t, []error{DuplicateError{PackageName: "duplicates", Name: "Person"}}, parseErr.Errors)
+}
+
+func TestParseDirCollectsAllSyntaxErrors(t *testing.T) {
+	harvest, err := ParseSourceDir("./testdata/syntaxerrors", ".*")
+	assert.Error(t, err)
+	assert.Nil(t, harvest)
+
+	parseErr, ok := err.(ParseError)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(parseErr.Errors))
+	assert.Contains(t, parseErr.Errors[0].Error(), "a_broken.go:4:")
+	assert.Contains(t, parseErr.Errors[1].Error(), "c_broken.go:5:")
+}
+
+func TestParseDirAllErrorsReturnsPartialHarvest(t *testing.T) {
+	harvest, err := ParseSourceDirAllErrors("./testdata/syntaxerrors", ".*")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "a_broken.go")
+	assert.Contains(t, err.Error(), "c_broken.go")
+
+	assert.NotNil(t, harvest)
+	assert.Equal(t, "syntaxerrors", harvest.PackageName)
+	assert.Equal(t, 1, len(harvest.Structs))
+	assert.Equal(t, "Person", harvest.Structs[0].Name)
+	assert.Equal(t, 0, len(harvest.Operations))
+
+	harvest, err = ParseSourceDirAllErrors("./structs", ".*")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, harvest.Structs)
+}
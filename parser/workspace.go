@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+)
+
+// workspaceModule is a module that is used by a go.work file
+type workspaceModule struct {
+	Path string
+	Dir  string
+}
+
+// ParseWorkspace parses every package of all modules that are used by the go.work file: the result is keyed by the
+// import-path of the package
+func ParseWorkspace(workFile string) (map[string]*AstVisitor, error) {
+	modules, err := readWorkspace(workFile)
+	if err != nil {
+		log.Printf("error reading workspace %s: %s", workFile, err.Error())
+		return nil, err
+	}
+
+	visitors := make(map[string]*AstVisitor)
+	for _, m := range modules {
+		packages, err := ParseSourceTreeWithOptions(m.Dir, `.*\.go$`, TreeOptions{ModulePackagesOnly: true})
+		if err != nil {
+			log.Printf("error parsing module %s: %s", m.Path, err.Error())
+			return nil, err
+		}
+		for rel, v := range packages {
+			visitors[importPathOf(m, rel)] = v
+		}
+	}
+	return visitors, nil
+}
+
+// ResolveWorkspaceType returns the struct that a package-qualified field refers to: the imports of the file that
+// declares the field tell which package the qualifier stands for, and that package is parsed from the workspace
+func ResolveWorkspaceType(workFile string, imports []model.Import, sourceFile string, field model.Field) (*model.Struct, error) {
+	if field.PackageQualifier == "" {
+		return nil, fmt.Errorf("Type %s is not qualified with a package", field.TypeName)
+	}
+	importPath, found := importPathOfQualifier(imports, sourceFile, field.PackageQualifier)
+	if !found {
+		return nil, fmt.Errorf("Package %s is not imported by %s", field.PackageQualifier, sourceFile)
+	}
+	dir, err := ResolveWorkspaceImport(workFile, importPath)
+	if err != nil {
+		return nil, err
+	}
+	v, err := ParseSourceDir(dir, `.*\.go$`)
+	if err != nil {
+		return nil, err
+	}
+	for idx, s := range v.Structs {
+		if s.Name == field.TypeName {
+			return &v.Structs[idx], nil
+		}
+	}
+	return nil, fmt.Errorf("Type %s.%s is not declared in %s", field.PackageQualifier, field.TypeName, importPath)
+}
+
+func importPathOfQualifier(imports []model.Import, sourceFile string, qualifier string) (string, bool) {
+	for _, imp := range imports {
+		if imp.SourceFile != sourceFile {
+			continue
+		}
+		if imp.Alias == qualifier || (imp.Alias == "" && path.Base(imp.Path) == qualifier) {
+			return imp.Path, true
+		}
+	}
+	return "", false
+}
+
+func importPathOf(m workspaceModule, relDir string) string {
+	if relDir == "." {
+		return m.Path
+	}
+	return m.Path + "/" + relDir
+}
+
+// ResolveWorkspaceImport returns the directory within the workspace that holds the source of the imported package
+func ResolveWorkspaceImport(workFile string, importPath string) (string, error) {
+	modules, err := readWorkspace(workFile)
+	if err != nil {
+		return "", err
+	}
+
+	// the most specific module wins, to support nested modules
+	var found *workspaceModule
+	for idx, m := range modules {
+		if importPath == m.Path || strings.HasPrefix(importPath, m.Path+"/") {
+			if found == nil || len(m.Path) > len(found.Path) {
+				found = &modules[idx]
+			}
+		}
+	}
+	if found == nil {
+		return "", fmt.Errorf("Import %s is not provided by any module of workspace %s", importPath, workFile)
+	}
+	return filepath.Join(found.Dir, filepath.FromSlash(strings.TrimPrefix(importPath, found.Path))), nil
+}
+
+func readWorkspace(workFile string) ([]workspaceModule, error) {
+	dirs, err := readDirectives(workFile, "use")
+	if err != nil {
+		return nil, err
+	}
+
+	modules := []workspaceModule{}
+	for _, dir := range dirs {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(workFile), dir)
+		}
+		paths, err := readDirectives(filepath.Join(dir, "go.mod"), "module")
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) != 1 {
+			return nil, fmt.Errorf("Module in %s has no valid module-directive", dir)
+		}
+		modules = append(modules, workspaceModule{Path: paths[0], Dir: dir})
+	}
+	return modules, nil
+}
+
+// readDirectives returns the arguments of the directive in a go.mod or go.work file, both in single-line and in block-form
+func readDirectives(filename string, directive string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	args := []string{}
+	inBlock := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			args = append(args, strings.Trim(line, `"`))
+		case line == directive+" (":
+			inBlock = true
+		case strings.HasPrefix(line, directive+" "):
+			args = append(args, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, directive)), `"`))
+		}
+	}
+	return args, scanner.Err()
+}
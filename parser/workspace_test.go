@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/MarcGrol/golangAnnotations/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWorkspace(t *testing.T) {
+	visitors, err := ParseWorkspace("testdata/workspace/go.work")
+	assert.NoError(t, err)
+	assert.Len(t, visitors, 4)
+
+	persons := visitors["example.com/persons/model"]
+	assert.NotNil(t, persons)
+	assert.Equal(t, 1, len(persons.Structs))
+	assert.Equal(t, "Person", persons.Structs[0].Name)
+	assert.Equal(t, "model", persons.Structs[0].PackageName)
+
+	tours := visitors["example.com/tours"]
+	assert.NotNil(t, tours)
+	assert.Equal(t, 1, len(tours.Structs))
+	assert.Equal(t, "Tour", tours.Structs[0].Name)
+	assert.Equal(t, 1, len(tours.Structs[0].Operations))
+	assert.Equal(t, "model", tours.Structs[0].Fields[1].PackageQualifier)
+}
+
+func TestParseWorkspaceKeepsPackagesApart(t *testing.T) {
+	visitors, err := ParseWorkspace("testdata/workspace/go.work")
+	assert.NoError(t, err)
+
+	// both main-packages declare a Config with a method of its own
+	for _, importPath := range []string{"example.com/tours/cmd/a", "example.com/tours/cmd/b"} {
+		v := visitors[importPath]
+		assert.NotNil(t, v)
+		assert.Equal(t, 1, len(v.Structs))
+		assert.Equal(t, "Config", v.Structs[0].Name)
+		assert.Equal(t, 1, len(v.Structs[0].Operations))
+	}
+}
+
+func TestResolveWorkspaceType(t *testing.T) {
+	visitors, err := ParseWorkspace("testdata/workspace/go.work")
+	assert.NoError(t, err)
+	tours := visitors["example.com/tours"]
+	tour := tours.Structs[0]
+
+	person, err := ResolveWorkspaceType("testdata/workspace/go.work", tours.Imports, tour.SourceFile, tour.Fields[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "Person", person.Name)
+	assert.Equal(t, "model", person.PackageName)
+
+	_, err = ResolveWorkspaceType("testdata/workspace/go.work", tours.Imports, tour.SourceFile, tour.Fields[0])
+	assert.Error(t, err)
+
+	_, err = ResolveWorkspaceType("testdata/workspace/go.work", tours.Imports, tour.SourceFile,
+		model.Field{TypeName: "Unknown", PackageQualifier: "model"})
+	assert.Error(t, err)
+}
+
+func TestResolveWorkspaceImport(t *testing.T) {
+	dir, err := ResolveWorkspaceImport("testdata/workspace/go.work", "example.com/persons/model")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("testdata", "workspace", "persons", "model"), dir)
+
+	_, err = ResolveWorkspaceImport("testdata/workspace/go.work", "example.com/unknown")
+	assert.Error(t, err)
+}